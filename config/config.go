@@ -16,6 +16,20 @@ type Config struct {
 	DBName              string
 	APIPort             string
 	JWTSecret           string
+	JWTKeysDir          string // Directorio con las claves .pem; vacío firma con HS256 y JWTSecret
+	JWTActiveKID        string // Clave con la que se firma; vacío usa la última del directorio
+	AccessTokenTTL      int // minutes
+	RefreshTokenTTL     int // hours
+	// Bloqueo de login por intentos fallidos
+	LoginMaxIntentos    int // fallos por usuario antes de bloquear
+	LoginMaxIntentosIP  int // fallos por IP antes de bloquear
+	LoginBloqueoBase    int // seconds; se duplica con cada fallo siguiente
+	LoginBloqueoMax     int // minutes
+	// Segundo factor (TOTP)
+	MFARequeridoAdmin   bool   // los administradores deben activar MFA para ingresar
+	MFATokenTTL         int    // minutes; vigencia del token entre la contraseña y el código
+	MFAEmisor           string // nombre que muestra la aplicación autenticadora
+	APIKeyRateLimit     int    // solicitudes por minuto de cada API key
 	CORSAllowedOrigins  string
 	Environment         string
 	LogLevel            string
@@ -30,6 +44,29 @@ type Config struct {
 	BackupInterval      int    // hours
 	BackupRetention     int    // number of backups to keep
 	BackupDirectory     string
+	// Propuestas de horario
+	PropuestaVigencia   int // hours
+	PropuestaRevision   int // minutes
+	// Email Configuration
+	EmailSMTPHost       string // vacío: los correos quedan en memoria
+	EmailSMTPPort       string
+	EmailFrom           string
+	EmailUsername       string
+	EmailPassword       string
+	FrontendURL         string // base de los enlaces que se envían por correo
+	PasswordResetTTL    int // minutes
+	EmailVerificationTTL int // hours
+	// Almacenamiento de fotos
+	StorageBackend      string // "local" (por defecto) o "s3"
+	UploadDir           string // directorio del almacenamiento local
+	UploadURL           string // prefijo de las URLs de los archivos locales
+	UploadMaxMB         int    // tamaño máximo de una foto subida
+	S3Endpoint          string // p. ej. https://s3.us-east-1.amazonaws.com o el de MinIO
+	S3Region            string
+	S3Bucket            string
+	S3AccessKey         string
+	S3SecretKey         string
+	S3PublicURL         string // base pública de los objetos; vacío usa endpoint/bucket
 }
 
 func LoadConfig() *Config {
@@ -49,6 +86,18 @@ func LoadConfig() *Config {
 	config.DBName = getEnv("DB_NAME", "copa_litoral")
 	config.APIPort = getEnv("API_PORT", "8089")
 	config.JWTSecret = getEnv("JWT_SECRET", "supersecretkeyforexample")
+	config.JWTKeysDir = getEnv("JWT_KEYS_DIR", "")
+	config.JWTActiveKID = getEnv("JWT_ACTIVE_KID", "")
+	config.AccessTokenTTL = getEnvAsInt("ACCESS_TOKEN_TTL", 15)     // minutes
+	config.RefreshTokenTTL = getEnvAsInt("REFRESH_TOKEN_TTL", 720) // hours
+	config.LoginMaxIntentos = getEnvAsInt("LOGIN_MAX_INTENTOS", 5)
+	config.LoginMaxIntentosIP = getEnvAsInt("LOGIN_MAX_INTENTOS_IP", 20)
+	config.LoginBloqueoBase = getEnvAsInt("LOGIN_BLOQUEO_BASE", 30) // seconds
+	config.LoginBloqueoMax = getEnvAsInt("LOGIN_BLOQUEO_MAX", 15)   // minutes
+	config.MFARequeridoAdmin = getEnvAsBool("MFA_REQUIRED_ADMIN", false)
+	config.MFATokenTTL = getEnvAsInt("MFA_TOKEN_TTL", 5) // minutes
+	config.MFAEmisor = getEnv("MFA_ISSUER", "Copa Litoral")
+	config.APIKeyRateLimit = getEnvAsInt("API_KEY_RATE_LIMIT", 120) // requests per minute
 	config.CORSAllowedOrigins = getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:5173,http://localhost:3000")
 	config.Environment = getEnv("ENVIRONMENT", "development")
 	config.LogLevel = getEnv("LOG_LEVEL", "info")
@@ -66,8 +115,38 @@ func LoadConfig() *Config {
 	config.BackupRetention = getEnvAsInt("BACKUP_RETENTION", 7) // backups
 	config.BackupDirectory = getEnv("BACKUP_DIRECTORY", "backups")
 
+	// Propuestas de horario
+	config.PropuestaVigencia = getEnvAsInt("PROPUESTA_VIGENCIA", 48) // hours
+	config.PropuestaRevision = getEnvAsInt("PROPUESTA_REVISION", 15) // minutes
+	if config.PropuestaRevision <= 0 {
+		log.Printf("Advertencia: PROPUESTA_REVISION debe ser mayor que cero, usando valor por defecto: 15")
+		config.PropuestaRevision = 15
+	}
+
+	// Email Configuration
+	config.EmailSMTPHost = getEnv("EMAIL_SMTP_HOST", "")
+	config.EmailSMTPPort = getEnv("EMAIL_SMTP_PORT", "587")
+	config.EmailFrom = getEnv("EMAIL_FROM", "noreply@copalitoral.local")
+	config.EmailUsername = getEnv("EMAIL_USERNAME", "")
+	config.EmailPassword = getEnv("EMAIL_PASSWORD", "")
+	config.FrontendURL = getEnv("FRONTEND_URL", "http://localhost:5173")
+	config.PasswordResetTTL = getEnvAsInt("PASSWORD_RESET_TTL", 60)           // minutes
+	config.EmailVerificationTTL = getEnvAsInt("EMAIL_VERIFICATION_TTL", 48) // hours
+
+	// Almacenamiento de fotos
+	config.StorageBackend = getEnv("STORAGE_BACKEND", "local")
+	config.UploadDir = getEnv("UPLOAD_DIR", "uploads")
+	config.UploadURL = getEnv("UPLOAD_URL", "/uploads")
+	config.UploadMaxMB = getEnvAsInt("UPLOAD_MAX_MB", 10)
+	config.S3Endpoint = getEnv("S3_ENDPOINT", "")
+	config.S3Region = getEnv("S3_REGION", "us-east-1")
+	config.S3Bucket = getEnv("S3_BUCKET", "")
+	config.S3AccessKey = getEnv("S3_ACCESS_KEY", "")
+	config.S3SecretKey = getEnv("S3_SECRET_KEY", "")
+	config.S3PublicURL = getEnv("S3_PUBLIC_URL", "")
+
 	// Validar variables críticas solo en producción
-	if config.JWTSecret == "supersecretkeyforexample" {
+	if config.JWTKeysDir == "" && config.JWTSecret == "supersecretkeyforexample" {
 		log.Printf("Advertencia: JWT_SECRET está usando valor por defecto. Cambia esto en producción.")
 	}
 
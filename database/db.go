@@ -14,7 +14,7 @@ var DB *sql.DB
 
 func InitDB(config *config.Config) {
 	// Construir la cadena de conexión DSN
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		config.DBHost, config.DBPort, config.DBUser, config.DBPassword, config.DBName)
 
 	// Abrir la conexión a la base de datos
@@ -0,0 +1,39 @@
+-- Rollback de llaves de eliminación directa
+-- Versión: 002
+
+DROP INDEX IF EXISTS idx_partidos_llave_posicion;
+DROP INDEX IF EXISTS idx_partidos_siguiente;
+
+ALTER TABLE partidos DROP COLUMN IF EXISTS siguiente_slot;
+ALTER TABLE partidos DROP COLUMN IF EXISTS siguiente_partido_id;
+ALTER TABLE partidos DROP COLUMN IF EXISTS posicion_llave;
+ALTER TABLE partidos DROP COLUMN IF EXISTS ronda;
+
+-- Los partidos sin jugadores definidos deben eliminarse antes de restaurar la restricción
+DELETE FROM partidos WHERE jugador1_id IS NULL OR jugador2_id IS NULL;
+ALTER TABLE partidos ALTER COLUMN jugador1_id SET NOT NULL;
+ALTER TABLE partidos ALTER COLUMN jugador2_id SET NOT NULL;
+
+-- El estado vuelve al enum original; se omite si la columna ya lo usa
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'estado_partido_enum') THEN
+        CREATE TYPE estado_partido_enum AS ENUM ('Pendiente', 'Agendado', 'Jugado', 'Walkover', 'Cancelado');
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+                   WHERE table_name = 'partidos' AND column_name = 'estado' AND udt_name = 'estado_partido_enum') THEN
+        ALTER TABLE partidos DROP CONSTRAINT IF EXISTS partidos_estado_check;
+        ALTER TABLE partidos ALTER COLUMN estado DROP DEFAULT;
+        ALTER TABLE partidos ALTER COLUMN estado TYPE estado_partido_enum USING (
+            CASE estado
+                WHEN 'agendado' THEN 'Agendado'
+                WHEN 'en_juego' THEN 'Agendado'
+                WHEN 'finalizado' THEN 'Jugado'
+                WHEN 'walkover' THEN 'Walkover'
+                WHEN 'cancelado' THEN 'Cancelado'
+                ELSE 'Pendiente'
+            END
+        )::estado_partido_enum;
+        ALTER TABLE partidos ALTER COLUMN estado SET DEFAULT 'Pendiente';
+    END IF;
+END $$;
@@ -0,0 +1,40 @@
+-- Migración: Llaves de eliminación directa
+-- Versión: 002
+-- Descripción: Permite partidos con jugadores por definir y los enlaza con el
+-- partido de la ronda siguiente que ocupa su ganador
+
+-- El estado pasa a texto con los mismos valores que models.EstadoPartido, para
+-- admitir por_definir en los partidos que esperan rivales
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'partidos' AND column_name = 'estado' AND udt_name = 'estado_partido_enum') THEN
+        ALTER TABLE partidos ALTER COLUMN estado DROP DEFAULT;
+        ALTER TABLE partidos ALTER COLUMN estado TYPE VARCHAR(20) USING (
+            CASE estado::TEXT
+                WHEN 'Pendiente' THEN 'pendiente'
+                WHEN 'Agendado' THEN 'agendado'
+                WHEN 'Jugado' THEN 'finalizado'
+                WHEN 'Walkover' THEN 'walkover'
+                WHEN 'Cancelado' THEN 'cancelado'
+            END
+        );
+        ALTER TABLE partidos ALTER COLUMN estado SET DEFAULT 'pendiente';
+        ALTER TABLE partidos ADD CONSTRAINT partidos_estado_check CHECK (estado IN (
+            'por_definir', 'pendiente', 'agendado', 'en_juego', 'finalizado', 'walkover', 'cancelado'
+        ));
+    END IF;
+END $$;
+DROP TYPE IF EXISTS estado_partido_enum;
+
+-- Los partidos de rondas posteriores se crean antes de conocer a sus jugadores
+ALTER TABLE partidos ALTER COLUMN jugador1_id DROP NOT NULL;
+ALTER TABLE partidos ALTER COLUMN jugador2_id DROP NOT NULL;
+
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS ronda INTEGER; -- 1 = primera ronda de la llave
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS posicion_llave INTEGER; -- Posición dentro de la ronda, desde 0
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS siguiente_partido_id INTEGER REFERENCES partidos(id) ON DELETE SET NULL;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS siguiente_slot SMALLINT CHECK (siguiente_slot IN (1, 2)); -- 1 = jugador1, 2 = jugador2
+
+CREATE INDEX IF NOT EXISTS idx_partidos_siguiente ON partidos (siguiente_partido_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_partidos_llave_posicion ON partidos (torneo_id, categoria_id, ronda, posicion_llave) WHERE ronda IS NOT NULL;
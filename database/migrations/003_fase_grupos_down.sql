@@ -0,0 +1,10 @@
+-- Rollback de la fase de grupos
+-- Versión: 003
+
+DROP INDEX IF EXISTS idx_partidos_grupo;
+ALTER TABLE partidos DROP COLUMN IF EXISTS jornada;
+ALTER TABLE partidos DROP COLUMN IF EXISTS grupo_id;
+
+DROP TRIGGER IF EXISTS set_timestamp_grupos ON grupos;
+DROP TABLE IF EXISTS grupo_jugadores;
+DROP TABLE IF EXISTS grupos;
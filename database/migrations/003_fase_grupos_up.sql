@@ -0,0 +1,29 @@
+-- Migración: Fase de grupos con sistema todos contra todos
+-- Versión: 003
+-- Descripción: Crea los grupos, sus integrantes y vincula los partidos a su grupo
+
+CREATE TABLE IF NOT EXISTS grupos (
+    id SERIAL PRIMARY KEY,
+    torneo_id INTEGER REFERENCES torneos(id) ON DELETE CASCADE NOT NULL,
+    categoria_id INTEGER REFERENCES categorias(id) ON DELETE CASCADE NOT NULL,
+    nombre VARCHAR(100) NOT NULL, -- Ej: 'Grupo A'
+    criterios_desempate TEXT[] NOT NULL DEFAULT ARRAY['enfrentamiento_directo', 'diferencia_sets', 'diferencia_games'],
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (torneo_id, categoria_id, nombre)
+);
+
+CREATE TABLE IF NOT EXISTS grupo_jugadores (
+    grupo_id INTEGER REFERENCES grupos(id) ON DELETE CASCADE,
+    jugador_id INTEGER REFERENCES jugadores(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (grupo_id, jugador_id)
+);
+
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS grupo_id INTEGER REFERENCES grupos(id) ON DELETE CASCADE;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS jornada INTEGER; -- Fecha del fixture dentro del grupo
+
+CREATE INDEX IF NOT EXISTS idx_grupos_torneo_categoria ON grupos (torneo_id, categoria_id);
+CREATE INDEX IF NOT EXISTS idx_partidos_grupo ON partidos (grupo_id);
+
+CREATE TRIGGER set_timestamp_grupos BEFORE UPDATE ON grupos FOR EACH ROW EXECUTE FUNCTION update_timestamp();
@@ -0,0 +1,4 @@
+-- Rollback del formato de partido por torneo
+-- Versión: 004
+
+ALTER TABLE torneos DROP COLUMN IF EXISTS formato_partido;
@@ -0,0 +1,5 @@
+-- Formato de puntuación de los partidos de cada torneo
+-- Versión: 004
+
+ALTER TABLE torneos ADD COLUMN formato_partido VARCHAR(50) NOT NULL DEFAULT 'mejor_de_3'
+    CHECK (formato_partido IN ('mejor_de_3', 'mejor_de_3_super_tiebreak', 'pro_set_8'));
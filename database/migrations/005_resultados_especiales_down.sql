@@ -0,0 +1,6 @@
+-- Rollback de walkover, abandono y descalificación
+-- Versión: 005
+
+-- El estado vuelve al enum original en el rollback de la versión 002
+ALTER TABLE partidos DROP COLUMN IF EXISTS motivo_resultado;
+ALTER TABLE partidos DROP COLUMN IF EXISTS tipo_resultado;
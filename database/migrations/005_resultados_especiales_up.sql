@@ -0,0 +1,7 @@
+-- Walkover, abandono y descalificación
+-- Versión: 005
+
+-- Cómo se definió el resultado; el beneficiario queda registrado como ganador
+ALTER TABLE partidos ADD COLUMN tipo_resultado VARCHAR(20) NOT NULL DEFAULT 'normal'
+    CHECK (tipo_resultado IN ('normal', 'walkover', 'abandono', 'descalificacion'));
+ALTER TABLE partidos ADD COLUMN motivo_resultado TEXT;
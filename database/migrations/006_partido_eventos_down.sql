@@ -0,0 +1,10 @@
+-- Rollback del historial de estados de partidos
+-- Versión: 006
+
+DROP TABLE IF EXISTS partido_eventos;
+
+ALTER TABLE partidos DROP CONSTRAINT IF EXISTS partidos_estado_check;
+UPDATE partidos SET estado = 'finalizado' WHERE estado = 'reportado';
+ALTER TABLE partidos ADD CONSTRAINT partidos_estado_check CHECK (estado IN (
+    'por_definir', 'pendiente', 'agendado', 'en_juego', 'finalizado', 'walkover', 'cancelado'
+));
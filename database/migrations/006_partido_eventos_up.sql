@@ -0,0 +1,21 @@
+-- Máquina de estados de partidos e historial de transiciones
+-- Versión: 006
+
+-- Los resultados sin aprobar pasan al nuevo estado reportado
+ALTER TABLE partidos DROP CONSTRAINT IF EXISTS partidos_estado_check;
+UPDATE partidos SET estado = 'reportado' WHERE estado = 'finalizado' AND resultado_aprobado = false;
+ALTER TABLE partidos ADD CONSTRAINT partidos_estado_check CHECK (estado IN (
+    'por_definir', 'pendiente', 'agendado', 'en_juego', 'reportado', 'finalizado', 'walkover', 'cancelado'
+));
+
+CREATE TABLE IF NOT EXISTS partido_eventos (
+    id SERIAL PRIMARY KEY,
+    partido_id INTEGER NOT NULL REFERENCES partidos(id) ON DELETE CASCADE,
+    estado_anterior VARCHAR(20) NOT NULL,
+    estado_nuevo VARCHAR(20) NOT NULL,
+    usuario_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL, -- NULL: cambio hecho por el sistema
+    detalle TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_partido_eventos_partido ON partido_eventos (partido_id, created_at);
@@ -0,0 +1,17 @@
+-- Rollback de las propuestas de horario
+-- Versión: 007
+
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS propuesta_fecha_j1 DATE;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS propuesta_hora_j1 TIME;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS propuesta_fecha_j2 DATE;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS propuesta_hora_j2 TIME;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS propuesta_aceptada_j1 BOOLEAN DEFAULT FALSE;
+ALTER TABLE partidos ADD COLUMN IF NOT EXISTS propuesta_aceptada_j2 BOOLEAN DEFAULT FALSE;
+
+DROP INDEX IF EXISTS idx_partidos_agenda_escalada;
+ALTER TABLE partidos DROP COLUMN IF EXISTS agenda_escalada;
+
+DROP TRIGGER IF EXISTS set_timestamp_propuestas_horario ON propuestas_horario;
+ALTER TABLE IF EXISTS propuestas_horario DROP CONSTRAINT IF EXISTS fk_propuestas_opcion_aceptada;
+DROP TABLE IF EXISTS propuesta_opciones;
+DROP TABLE IF EXISTS propuestas_horario;
@@ -0,0 +1,65 @@
+-- Propuestas de horario con varias opciones, respuesta del rival y vencimiento
+-- Versión: 007
+
+CREATE TABLE IF NOT EXISTS propuestas_horario (
+    id SERIAL PRIMARY KEY,
+    partido_id INTEGER NOT NULL REFERENCES partidos(id) ON DELETE CASCADE,
+    propuesto_por INTEGER NOT NULL REFERENCES jugadores(id) ON DELETE CASCADE,
+    estado VARCHAR(20) NOT NULL DEFAULT 'pendiente' CHECK (estado IN (
+        'pendiente', 'aceptada', 'rechazada', 'contrapropuesta', 'reemplazada', 'expirada', 'cancelada'
+    )),
+    respondida_por INTEGER REFERENCES jugadores(id) ON DELETE SET NULL,
+    opcion_aceptada_id INTEGER,
+    motivo TEXT,
+    expira_en TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS propuesta_opciones (
+    id SERIAL PRIMARY KEY,
+    propuesta_id INTEGER NOT NULL REFERENCES propuestas_horario(id) ON DELETE CASCADE,
+    fecha_hora TIMESTAMP NOT NULL,
+    UNIQUE (propuesta_id, fecha_hora)
+);
+
+ALTER TABLE propuestas_horario ADD CONSTRAINT fk_propuestas_opcion_aceptada
+    FOREIGN KEY (opcion_aceptada_id) REFERENCES propuesta_opciones(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_propuestas_partido ON propuestas_horario (partido_id);
+CREATE INDEX IF NOT EXISTS idx_propuestas_pendientes ON propuestas_horario (expira_en) WHERE estado = 'pendiente';
+
+CREATE TRIGGER set_timestamp_propuestas_horario BEFORE UPDATE ON propuestas_horario FOR EACH ROW EXECUTE FUNCTION update_timestamp();
+
+-- Partidos que pasan al administrador cuando los jugadores no acuerdan horario
+ALTER TABLE partidos ADD COLUMN agenda_escalada BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_partidos_agenda_escalada ON partidos (agenda_escalada) WHERE agenda_escalada = true;
+
+-- Las propuestas sin aceptar del esquema anterior pasan a la nueva tabla
+WITH anteriores AS (
+    SELECT id AS partido_id, jugador1_id AS jugador_id, propuesta_fecha_j1 + propuesta_hora_j1 AS fecha_hora
+    FROM partidos
+    WHERE propuesta_fecha_j1 IS NOT NULL AND propuesta_hora_j1 IS NOT NULL AND NOT propuesta_aceptada_j2
+      AND estado = 'pendiente' AND jugador1_id IS NOT NULL
+    UNION ALL
+    SELECT id, jugador2_id, propuesta_fecha_j2 + propuesta_hora_j2
+    FROM partidos
+    WHERE propuesta_fecha_j2 IS NOT NULL AND propuesta_hora_j2 IS NOT NULL AND NOT propuesta_aceptada_j1
+      AND estado = 'pendiente' AND jugador2_id IS NOT NULL
+), nuevas AS (
+    INSERT INTO propuestas_horario (partido_id, propuesto_por, estado, expira_en)
+    SELECT partido_id, jugador_id, 'pendiente', NOW() + INTERVAL '48 hours'
+    FROM anteriores
+    RETURNING id, partido_id, propuesto_por
+)
+INSERT INTO propuesta_opciones (propuesta_id, fecha_hora)
+SELECT n.id, a.fecha_hora
+FROM nuevas n
+JOIN anteriores a ON a.partido_id = n.partido_id AND a.jugador_id = n.propuesto_por;
+
+ALTER TABLE partidos DROP COLUMN IF EXISTS propuesta_fecha_j1;
+ALTER TABLE partidos DROP COLUMN IF EXISTS propuesta_hora_j1;
+ALTER TABLE partidos DROP COLUMN IF EXISTS propuesta_fecha_j2;
+ALTER TABLE partidos DROP COLUMN IF EXISTS propuesta_hora_j2;
+ALTER TABLE partidos DROP COLUMN IF EXISTS propuesta_aceptada_j1;
+ALTER TABLE partidos DROP COLUMN IF EXISTS propuesta_aceptada_j2;
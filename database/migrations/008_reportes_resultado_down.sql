@@ -0,0 +1,13 @@
+-- Rollback de los reportes de resultado y disputas
+-- Versión: 008
+
+DROP TRIGGER IF EXISTS set_timestamp_reportes_resultado ON reportes_resultado;
+DROP TABLE IF EXISTS disputas_resultado;
+DROP TABLE IF EXISTS reporte_sets;
+DROP TABLE IF EXISTS reportes_resultado;
+
+ALTER TABLE partidos DROP CONSTRAINT IF EXISTS partidos_estado_check;
+UPDATE partidos SET estado = 'agendado' WHERE estado = 'en_disputa';
+ALTER TABLE partidos ADD CONSTRAINT partidos_estado_check CHECK (estado IN (
+    'por_definir', 'pendiente', 'agendado', 'en_juego', 'reportado', 'finalizado', 'walkover', 'cancelado'
+));
@@ -0,0 +1,48 @@
+-- Reportes de resultado de ambos jugadores y disputas
+-- Versión: 008
+
+ALTER TABLE partidos DROP CONSTRAINT IF EXISTS partidos_estado_check;
+ALTER TABLE partidos ADD CONSTRAINT partidos_estado_check CHECK (estado IN (
+    'por_definir', 'pendiente', 'agendado', 'en_juego', 'reportado', 'en_disputa', 'finalizado', 'walkover', 'cancelado'
+));
+
+-- Cada reporte es la versión del resultado de un jugador; los reemplazados se conservan
+CREATE TABLE IF NOT EXISTS reportes_resultado (
+    id SERIAL PRIMARY KEY,
+    partido_id INTEGER NOT NULL REFERENCES partidos(id) ON DELETE CASCADE,
+    jugador_id INTEGER NOT NULL REFERENCES jugadores(id) ON DELETE CASCADE,
+    sets_ganados_j1 INTEGER NOT NULL,
+    sets_ganados_j2 INTEGER NOT NULL,
+    ganador_id INTEGER NOT NULL REFERENCES jugadores(id) ON DELETE CASCADE,
+    estado VARCHAR(20) NOT NULL DEFAULT 'pendiente' CHECK (estado IN (
+        'pendiente', 'confirmado', 'en_disputa', 'reemplazado', 'resuelto'
+    )),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS reporte_sets (
+    id SERIAL PRIMARY KEY,
+    reporte_id INTEGER NOT NULL REFERENCES reportes_resultado(id) ON DELETE CASCADE,
+    numero_set INTEGER NOT NULL,
+    score_jugador1 INTEGER NOT NULL,
+    score_jugador2 INTEGER NOT NULL,
+    tie_break_j1 INTEGER,
+    tie_break_j2 INTEGER,
+    UNIQUE (reporte_id, numero_set)
+);
+
+CREATE TABLE IF NOT EXISTS disputas_resultado (
+    id SERIAL PRIMARY KEY,
+    partido_id INTEGER NOT NULL REFERENCES partidos(id) ON DELETE CASCADE,
+    estado VARCHAR(20) NOT NULL DEFAULT 'abierta' CHECK (estado IN ('abierta', 'resuelta')),
+    resuelta_por INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    nota TEXT,
+    resuelta_en TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reportes_partido ON reportes_resultado (partido_id, jugador_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_disputas_abierta_partido ON disputas_resultado (partido_id) WHERE estado = 'abierta';
+
+CREATE TRIGGER set_timestamp_reportes_resultado BEFORE UPDATE ON reportes_resultado FOR EACH ROW EXECUTE FUNCTION update_timestamp();
@@ -0,0 +1,4 @@
+-- Rollback de los refresh tokens
+-- Versión: 009
+
+DROP TABLE IF EXISTS refresh_tokens;
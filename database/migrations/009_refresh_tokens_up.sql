@@ -0,0 +1,17 @@
+-- Refresh tokens con rotación y revocación de sesiones
+-- Versión: 009
+
+-- Cada login abre una familia de tokens; un token rotado que se vuelve a usar revoca la familia completa
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    familia VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA-256 del token; el token en claro no se guarda
+    expira_en TIMESTAMP NOT NULL,
+    usado_en TIMESTAMP, -- Momento en que se rotó por un token nuevo
+    revocado_en TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_familia ON refresh_tokens (familia, usuario_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_usuario ON refresh_tokens (usuario_id) WHERE revocado_en IS NULL;
@@ -0,0 +1,5 @@
+-- Rollback de la recuperación de contraseña y verificación de email
+-- Versión: 010
+
+DROP TABLE IF EXISTS tokens_usuario;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS email_verificado_en;
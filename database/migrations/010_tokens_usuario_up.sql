@@ -0,0 +1,18 @@
+-- Recuperación de contraseña y verificación de email
+-- Versión: 010
+
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS email_verificado_en TIMESTAMP;
+
+-- Tokens de un solo uso que se envían por correo; solo se guarda su hash
+CREATE TABLE IF NOT EXISTS tokens_usuario (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    tipo VARCHAR(30) NOT NULL CHECK (tipo IN ('recuperacion_password', 'verificacion_email')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255) NOT NULL, -- Dirección a la que se envió; si el usuario la cambia el token deja de servir
+    expira_en TIMESTAMP NOT NULL,
+    usado_en TIMESTAMP, -- Se completa al usarlo o al emitirse uno nuevo del mismo tipo
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tokens_usuario_pendientes ON tokens_usuario (usuario_id, tipo) WHERE usado_en IS NULL;
@@ -0,0 +1,5 @@
+-- Rollback de la gestión de usuarios
+-- Versión: 011
+
+DROP TABLE IF EXISTS auditoria_usuarios;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS activo;
@@ -0,0 +1,19 @@
+-- Gestión de usuarios por el administrador y auditoría de cambios
+-- Versión: 011
+
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS activo BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Historial de cambios que un administrador hace sobre una cuenta
+CREATE TABLE IF NOT EXISTS auditoria_usuarios (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL, -- Administrador que hizo el cambio
+    accion VARCHAR(30) NOT NULL CHECK (accion IN (
+        'cambio_rol', 'vinculacion_jugador', 'desvinculacion_jugador', 'desactivacion', 'activacion'
+    )),
+    valor_anterior VARCHAR(255),
+    valor_nuevo VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auditoria_usuarios_usuario ON auditoria_usuarios (usuario_id, created_at);
@@ -0,0 +1,10 @@
+-- Rollback del reclamo de jugadores
+-- Versión: 012
+
+DELETE FROM auditoria_usuarios WHERE accion = 'reclamo_jugador';
+ALTER TABLE auditoria_usuarios DROP CONSTRAINT IF EXISTS auditoria_usuarios_accion_check;
+ALTER TABLE auditoria_usuarios ADD CONSTRAINT auditoria_usuarios_accion_check CHECK (accion IN (
+    'cambio_rol', 'vinculacion_jugador', 'desvinculacion_jugador', 'desactivacion', 'activacion'
+));
+
+DROP TABLE IF EXISTS reclamos_jugador;
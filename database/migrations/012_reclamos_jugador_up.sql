@@ -0,0 +1,28 @@
+-- Reclamo de jugadores cargados por el administrador
+-- Versión: 012
+
+-- Un usuario pide vincularse a un jugador existente; lo aprueba un administrador
+-- o el propio usuario con el código que recibe en el teléfono del jugador
+CREATE TABLE IF NOT EXISTS reclamos_jugador (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    jugador_id INTEGER NOT NULL REFERENCES jugadores(id) ON DELETE CASCADE,
+    estado VARCHAR(20) NOT NULL DEFAULT 'pendiente' CHECK (estado IN ('pendiente', 'aprobado', 'rechazado')),
+    codigo_hash VARCHAR(64), -- SHA-256 del código de confirmación vigente
+    codigo_expira_en TIMESTAMP,
+    intentos_codigo INTEGER NOT NULL DEFAULT 0,
+    resuelto_por INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    nota TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Un usuario tiene a lo sumo un reclamo pendiente
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reclamos_jugador_pendiente
+    ON reclamos_jugador (usuario_id) WHERE estado = 'pendiente';
+CREATE INDEX IF NOT EXISTS idx_reclamos_jugador_jugador ON reclamos_jugador (jugador_id, estado);
+
+ALTER TABLE auditoria_usuarios DROP CONSTRAINT IF EXISTS auditoria_usuarios_accion_check;
+ALTER TABLE auditoria_usuarios ADD CONSTRAINT auditoria_usuarios_accion_check CHECK (accion IN (
+    'cambio_rol', 'vinculacion_jugador', 'desvinculacion_jugador', 'desactivacion', 'activacion', 'reclamo_jugador'
+));
@@ -0,0 +1,8 @@
+-- Rollback de la auditoría del desbloqueo de login
+-- Versión: 013
+
+DELETE FROM auditoria_usuarios WHERE accion = 'desbloqueo';
+ALTER TABLE auditoria_usuarios DROP CONSTRAINT IF EXISTS auditoria_usuarios_accion_check;
+ALTER TABLE auditoria_usuarios ADD CONSTRAINT auditoria_usuarios_accion_check CHECK (accion IN (
+    'cambio_rol', 'vinculacion_jugador', 'desvinculacion_jugador', 'desactivacion', 'activacion', 'reclamo_jugador'
+));
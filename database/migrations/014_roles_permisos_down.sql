@@ -0,0 +1,13 @@
+-- Rollback de roles y permisos
+-- Versión: 014
+
+DELETE FROM auditoria_usuarios WHERE accion IN ('asignacion_rol', 'revocacion_rol');
+ALTER TABLE auditoria_usuarios DROP CONSTRAINT IF EXISTS auditoria_usuarios_accion_check;
+ALTER TABLE auditoria_usuarios ADD CONSTRAINT auditoria_usuarios_accion_check CHECK (accion IN (
+    'cambio_rol', 'vinculacion_jugador', 'desvinculacion_jugador', 'desactivacion', 'activacion', 'reclamo_jugador',
+    'desbloqueo'
+));
+
+DROP TABLE IF EXISTS usuario_roles;
+DROP TABLE IF EXISTS rol_permisos;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,71 @@
+-- Roles con permisos y asignación de roles a usuarios con alcance por torneo o categoría
+-- Versión: 014
+
+CREATE TABLE IF NOT EXISTS roles (
+    id SERIAL PRIMARY KEY,
+    nombre VARCHAR(50) UNIQUE NOT NULL,
+    descripcion VARCHAR(255),
+    sistema BOOLEAN NOT NULL DEFAULT FALSE, -- Rol base de las cuentas (usuarios.rol); no se edita ni se asigna
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- El catálogo de permisos vive en el código (models.Permisos)
+CREATE TABLE IF NOT EXISTS rol_permisos (
+    rol_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permiso VARCHAR(50) NOT NULL,
+    PRIMARY KEY (rol_id, permiso)
+);
+
+-- Roles adicionales al rol base; sin torneo ni categoría rigen en todo el sistema
+CREATE TABLE IF NOT EXISTS usuario_roles (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    rol_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    torneo_id INTEGER REFERENCES torneos(id) ON DELETE CASCADE,
+    categoria_id INTEGER REFERENCES categorias(id) ON DELETE CASCADE,
+    asignado_por INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_usuario_roles_unico
+    ON usuario_roles (usuario_id, rol_id, COALESCE(torneo_id, 0), COALESCE(categoria_id, 0));
+
+INSERT INTO roles (nombre, descripcion, sistema) VALUES
+    ('administrador', 'Administrador', TRUE),
+    ('jugador', 'Jugador', TRUE),
+    ('arbitro', 'Árbitro', FALSE),
+    ('editor_prensa', 'Editor de prensa', FALSE),
+    ('organizador_categoria', 'Organizador de categoría', FALSE)
+ON CONFLICT (nombre) DO NOTHING;
+
+INSERT INTO rol_permisos (rol_id, permiso)
+SELECT r.id, p.permiso
+FROM roles r
+JOIN (VALUES
+    ('administrador', 'torneos:edit'),
+    ('administrador', 'categorias:edit'),
+    ('administrador', 'jugadores:edit'),
+    ('administrador', 'llaves:edit'),
+    ('administrador', 'partidos:edit'),
+    ('administrador', 'partidos:schedule'),
+    ('administrador', 'partidos:approve'),
+    ('administrador', 'usuarios:manage'),
+    ('administrador', 'roles:manage'),
+    ('administrador', 'noticias:publish'),
+    ('arbitro', 'partidos:schedule'),
+    ('arbitro', 'partidos:approve'),
+    ('editor_prensa', 'noticias:publish'),
+    ('organizador_categoria', 'jugadores:edit'),
+    ('organizador_categoria', 'llaves:edit'),
+    ('organizador_categoria', 'partidos:edit'),
+    ('organizador_categoria', 'partidos:schedule'),
+    ('organizador_categoria', 'partidos:approve')
+) AS p (rol, permiso) ON p.rol = r.nombre
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE auditoria_usuarios DROP CONSTRAINT IF EXISTS auditoria_usuarios_accion_check;
+ALTER TABLE auditoria_usuarios ADD CONSTRAINT auditoria_usuarios_accion_check CHECK (accion IN (
+    'cambio_rol', 'vinculacion_jugador', 'desvinculacion_jugador', 'desactivacion', 'activacion', 'reclamo_jugador',
+    'desbloqueo', 'asignacion_rol', 'revocacion_rol'
+));
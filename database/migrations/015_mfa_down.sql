@@ -0,0 +1,15 @@
+-- Rollback del segundo factor de autenticación
+-- Versión: 015
+
+DELETE FROM auditoria_usuarios WHERE accion IN ('mfa_activado', 'mfa_desactivado');
+ALTER TABLE auditoria_usuarios DROP CONSTRAINT IF EXISTS auditoria_usuarios_accion_check;
+ALTER TABLE auditoria_usuarios ADD CONSTRAINT auditoria_usuarios_accion_check CHECK (accion IN (
+    'cambio_rol', 'vinculacion_jugador', 'desvinculacion_jugador', 'desactivacion', 'activacion', 'reclamo_jugador',
+    'desbloqueo', 'asignacion_rol', 'revocacion_rol'
+));
+
+DROP TABLE IF EXISTS mfa_codigos_recuperacion;
+
+ALTER TABLE usuarios DROP COLUMN IF EXISTS mfa_ultimo_paso;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS mfa_habilitado_en;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS mfa_secreto;
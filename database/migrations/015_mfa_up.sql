@@ -0,0 +1,22 @@
+-- Segundo factor de autenticación (TOTP) y códigos de recuperación
+-- Versión: 015
+
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS mfa_secreto VARCHAR(64);       -- Base32; se guarda al enrolar, antes de activar
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS mfa_habilitado_en TIMESTAMP;   -- NULL mientras MFA no esté activo
+ALTER TABLE usuarios ADD COLUMN IF NOT EXISTS mfa_ultimo_paso BIGINT;        -- Último paso TOTP usado, para impedir reutilizar un código
+
+CREATE TABLE IF NOT EXISTS mfa_codigos_recuperacion (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    codigo_hash VARCHAR(64) NOT NULL, -- SHA-256 del código normalizado
+    usado_en TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_codigos_usuario ON mfa_codigos_recuperacion(usuario_id);
+
+ALTER TABLE auditoria_usuarios DROP CONSTRAINT IF EXISTS auditoria_usuarios_accion_check;
+ALTER TABLE auditoria_usuarios ADD CONSTRAINT auditoria_usuarios_accion_check CHECK (accion IN (
+    'cambio_rol', 'vinculacion_jugador', 'desvinculacion_jugador', 'desactivacion', 'activacion', 'reclamo_jugador',
+    'desbloqueo', 'asignacion_rol', 'revocacion_rol', 'mfa_activado', 'mfa_desactivado'
+));
@@ -0,0 +1,6 @@
+-- Rollback de las API keys
+-- Versión: 016
+
+DELETE FROM rol_permisos WHERE permiso = 'api_keys:manage';
+
+DROP TABLE IF EXISTS api_keys;
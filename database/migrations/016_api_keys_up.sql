@@ -0,0 +1,21 @@
+-- API keys para integraciones (tableros, bots de resultados)
+-- Versión: 016
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    nombre VARCHAR(100) NOT NULL,
+    prefijo VARCHAR(16) NOT NULL,                 -- Comienzo de la clave, para reconocerla en los listados
+    clave_hash VARCHAR(64) UNIQUE NOT NULL,       -- SHA-256 de la clave; la clave solo se muestra al crearla
+    permisos TEXT[] NOT NULL DEFAULT '{}',        -- Limitan los permisos de quien la creó
+    creada_por INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    expira_en TIMESTAMP,
+    ultimo_uso_en TIMESTAMP,
+    revocada_en TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_creada_por ON api_keys(creada_por);
+
+INSERT INTO rol_permisos (rol_id, permiso)
+SELECT id, 'api_keys:manage' FROM roles WHERE nombre = 'administrador'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,19 @@
+-- Rollback de estados y vínculos de noticias
+-- Versión: 017
+
+DROP TABLE IF EXISTS noticia_jugadores;
+DROP TABLE IF EXISTS noticia_partidos;
+DROP TABLE IF EXISTS noticia_torneos;
+
+DROP INDEX IF EXISTS idx_noticias_publicacion;
+
+-- Sin estados, solo se conservan las noticias que estaban publicadas. Se omite si la
+-- columna no existe, como al inicializar la base con todos los archivos en orden.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'noticias' AND column_name = 'estado') THEN
+        DELETE FROM noticias WHERE estado <> 'publicada' OR fecha_publicacion IS NULL;
+        ALTER TABLE noticias ALTER COLUMN fecha_publicacion SET DEFAULT CURRENT_TIMESTAMP;
+    END IF;
+END $$;
+ALTER TABLE noticias DROP COLUMN IF EXISTS estado;
@@ -0,0 +1,34 @@
+-- Estados y publicación programada de noticias; vínculos con torneos, partidos y jugadores
+-- Versión: 017
+
+-- Las noticias existentes ya se mostraban: quedan publicadas. Las nuevas nacen como borrador.
+ALTER TABLE noticias ADD COLUMN IF NOT EXISTS estado VARCHAR(20) NOT NULL DEFAULT 'publicada'
+    CHECK (estado IN ('borrador', 'publicada', 'archivada'));
+ALTER TABLE noticias ALTER COLUMN estado SET DEFAULT 'borrador';
+
+-- Un borrador no tiene fecha; una publicada con fecha futura queda programada
+ALTER TABLE noticias ALTER COLUMN fecha_publicacion DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_noticias_publicacion ON noticias (estado, fecha_publicacion DESC);
+
+CREATE TABLE IF NOT EXISTS noticia_torneos (
+    noticia_id INTEGER NOT NULL REFERENCES noticias(id) ON DELETE CASCADE,
+    torneo_id INTEGER NOT NULL REFERENCES torneos(id) ON DELETE CASCADE,
+    PRIMARY KEY (noticia_id, torneo_id)
+);
+
+CREATE TABLE IF NOT EXISTS noticia_partidos (
+    noticia_id INTEGER NOT NULL REFERENCES noticias(id) ON DELETE CASCADE,
+    partido_id INTEGER NOT NULL REFERENCES partidos(id) ON DELETE CASCADE,
+    PRIMARY KEY (noticia_id, partido_id)
+);
+
+CREATE TABLE IF NOT EXISTS noticia_jugadores (
+    noticia_id INTEGER NOT NULL REFERENCES noticias(id) ON DELETE CASCADE,
+    jugador_id INTEGER NOT NULL REFERENCES jugadores(id) ON DELETE CASCADE,
+    PRIMARY KEY (noticia_id, jugador_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_noticia_torneos_torneo ON noticia_torneos (torneo_id);
+CREATE INDEX IF NOT EXISTS idx_noticia_partidos_partido ON noticia_partidos (partido_id);
+CREATE INDEX IF NOT EXISTS idx_noticia_jugadores_jugador ON noticia_jugadores (jugador_id);
@@ -0,0 +1,6 @@
+-- Rollback de los auspicios
+-- Versión: 018
+
+DELETE FROM rol_permisos WHERE permiso = 'auspiciadores:edit';
+
+DROP TABLE IF EXISTS auspicios;
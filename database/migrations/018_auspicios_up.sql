@@ -0,0 +1,26 @@
+-- Auspicios: nivel y vigencia de cada auspiciador en general, en un torneo o en una categoría
+-- Versión: 018
+
+CREATE TABLE IF NOT EXISTS auspicios (
+    id SERIAL PRIMARY KEY,
+    auspiciador_id INTEGER NOT NULL REFERENCES auspiciadores(id) ON DELETE CASCADE,
+    torneo_id INTEGER REFERENCES torneos(id) ON DELETE CASCADE,       -- NULL: todos los torneos
+    categoria_id INTEGER REFERENCES categorias(id) ON DELETE CASCADE, -- NULL: todas las categorías
+    nivel VARCHAR(10) NOT NULL DEFAULT 'bronce' CHECK (nivel IN ('oro', 'plata', 'bronce')),
+    vigente_desde DATE,                                               -- NULL: sin fecha de inicio
+    vigente_hasta DATE,                                               -- NULL: sin vencimiento
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (vigente_desde IS NULL OR vigente_hasta IS NULL OR vigente_desde <= vigente_hasta)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auspicios_auspiciador ON auspicios (auspiciador_id);
+CREATE INDEX IF NOT EXISTS idx_auspicios_torneo ON auspicios (torneo_id);
+
+-- Los auspiciadores activos existentes se mostraban siempre: quedan como auspicio general
+INSERT INTO auspicios (auspiciador_id, nivel)
+SELECT a.id, 'bronce' FROM auspiciadores a
+WHERE a.activo AND NOT EXISTS (SELECT 1 FROM auspicios x WHERE x.auspiciador_id = a.id);
+
+INSERT INTO rol_permisos (rol_id, permiso)
+SELECT id, 'auspiciadores:edit' FROM roles WHERE nombre = 'administrador'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+-- Rollback de los campeones históricos
+-- Versión: 019
+
+DROP INDEX IF EXISTS idx_campeones_jugador;
+DROP INDEX IF EXISTS idx_campeones_historicos;
+
+ALTER TABLE campeones DROP COLUMN IF EXISTS jugador_nombre;
@@ -0,0 +1,9 @@
+-- Campeones históricos: campeones cargados a mano, anteriores al sistema
+-- Versión: 019
+
+-- Nombre del campeón cuando no está registrado como jugador
+ALTER TABLE campeones ADD COLUMN IF NOT EXISTS jugador_nombre VARCHAR(255);
+
+-- Sin torneo, UNIQUE (torneo_id, categoria_id, anio) no evita duplicados: NULL no se repite
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campeones_historicos ON campeones (categoria_id, anio) WHERE torneo_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_campeones_jugador ON campeones (jugador_id);
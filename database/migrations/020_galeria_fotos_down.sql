@@ -0,0 +1,15 @@
+-- Rollback de la galería de fotos
+-- Versión: 020
+
+DELETE FROM rol_permisos WHERE permiso = 'fotos:upload';
+
+DROP INDEX IF EXISTS idx_fotos_partido;
+DROP INDEX IF EXISTS idx_fotos_torneo;
+DROP TABLE IF EXISTS foto_variantes;
+
+ALTER TABLE fotos_destacadas DROP COLUMN IF EXISTS subida_por;
+ALTER TABLE fotos_destacadas DROP COLUMN IF EXISTS tamano_bytes;
+ALTER TABLE fotos_destacadas DROP COLUMN IF EXISTS alto;
+ALTER TABLE fotos_destacadas DROP COLUMN IF EXISTS ancho;
+ALTER TABLE fotos_destacadas DROP COLUMN IF EXISTS tipo_mime;
+ALTER TABLE fotos_destacadas DROP COLUMN IF EXISTS clave;
@@ -0,0 +1,28 @@
+-- Galería de fotos: archivos subidos, sus miniaturas y quién los subió
+-- Versión: 020
+
+-- clave: ubicación del original en el almacenamiento; NULL en las fotos cargadas
+-- antes de la galería, que solo tienen una URL externa
+ALTER TABLE fotos_destacadas ADD COLUMN IF NOT EXISTS clave VARCHAR(255);
+ALTER TABLE fotos_destacadas ADD COLUMN IF NOT EXISTS tipo_mime VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE fotos_destacadas ADD COLUMN IF NOT EXISTS ancho INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE fotos_destacadas ADD COLUMN IF NOT EXISTS alto INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE fotos_destacadas ADD COLUMN IF NOT EXISTS tamano_bytes BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE fotos_destacadas ADD COLUMN IF NOT EXISTS subida_por INTEGER REFERENCES usuarios(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS foto_variantes (
+    foto_id INTEGER NOT NULL REFERENCES fotos_destacadas(id) ON DELETE CASCADE,
+    nombre VARCHAR(20) NOT NULL, -- chica, mediana, grande
+    clave VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    ancho INTEGER NOT NULL,
+    alto INTEGER NOT NULL,
+    PRIMARY KEY (foto_id, nombre)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fotos_torneo ON fotos_destacadas (torneo_id);
+CREATE INDEX IF NOT EXISTS idx_fotos_partido ON fotos_destacadas (partido_id);
+
+INSERT INTO rol_permisos (rol_id, permiso)
+SELECT id, 'fotos:upload' FROM roles WHERE nombre IN ('administrador', 'editor_prensa')
+ON CONFLICT DO NOTHING;
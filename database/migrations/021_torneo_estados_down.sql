@@ -0,0 +1,14 @@
+-- Rollback del ciclo de vida de los torneos
+-- Versión: 021
+
+ALTER TABLE torneos ADD COLUMN IF NOT EXISTS activo BOOLEAN DEFAULT TRUE;
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'torneos' AND column_name = 'estado') THEN
+        UPDATE torneos SET activo = estado IN ('inscripcion_abierta', 'inscripcion_cerrada', 'en_curso');
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_torneos_actual;
+ALTER TABLE torneos DROP COLUMN IF EXISTS inscripcion_cierre;
+ALTER TABLE torneos DROP COLUMN IF EXISTS estado;
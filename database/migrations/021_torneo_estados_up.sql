@@ -0,0 +1,22 @@
+-- Ciclo de vida de los torneos: el estado reemplaza a la marca activo
+-- Versión: 021
+
+ALTER TABLE torneos ADD COLUMN IF NOT EXISTS estado VARCHAR(30) NOT NULL DEFAULT 'planificado'
+    CHECK (estado IN ('planificado', 'inscripcion_abierta', 'inscripcion_cerrada', 'en_curso', 'finalizado', 'archivado'));
+ALTER TABLE torneos ADD COLUMN IF NOT EXISTS inscripcion_cierre TIMESTAMP; -- Cierre previsto de la inscripción
+
+-- Los torneos existentes quedan finalizados, salvo el activo más reciente, que sigue en curso
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'torneos' AND column_name = 'activo') THEN
+        UPDATE torneos SET estado = 'finalizado';
+        UPDATE torneos SET estado = 'en_curso'
+        WHERE id = (SELECT id FROM torneos WHERE activo ORDER BY anio DESC, fecha_inicio DESC NULLS LAST, id DESC LIMIT 1);
+    END IF;
+END $$;
+
+-- Un solo torneo puede estar entre la apertura de la inscripción y el final
+CREATE UNIQUE INDEX IF NOT EXISTS idx_torneos_actual ON torneos ((TRUE))
+    WHERE estado IN ('inscripcion_abierta', 'inscripcion_cerrada', 'en_curso');
+
+ALTER TABLE torneos DROP COLUMN IF EXISTS activo;
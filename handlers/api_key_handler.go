@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// respondAPIKeyError traduce los errores del servicio de API keys a respuestas HTTP
+func respondAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermisoInvalido), errors.Is(err, services.ErrAPIKeySinPermisos),
+		errors.Is(err, services.ErrAPIKeyExpiracion):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrAPIKeyPermisoAjeno):
+		utils.RespondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.GetAPIKeys()
+	if err != nil {
+		respondAPIKeyError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, keys)
+}
+
+// CreateAPIKey crea una clave con los permisos indicados y la devuelve en claro por
+// única vez
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Nombre   string     `json:"nombre" validate:"required,min=3,max=100,no_sql_injection,safe_string"`
+		Permisos []string   `json:"permisos" validate:"required,min=1"`
+		ExpiraEn *time.Time `json:"expira_en"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	key := models.APIKey{
+		Nombre:    utils.SanitizeString(request.Nombre),
+		Permisos:  request.Permisos,
+		CreadaPor: actorID,
+	}
+	if request.ExpiraEn != nil {
+		key.ExpiraEn = sql.NullTime{Time: *request.ExpiraEn, Valid: true}
+	}
+
+	clave, err := h.apiKeyService.CreateAPIKey(&key)
+	if err != nil {
+		respondAPIKeyError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, map[string]interface{}{
+		"api_key": key,
+		"clave":   clave,
+		"message": "Guardá la clave: no se vuelve a mostrar",
+	})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de API key inválido")
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(id); err != nil {
+		respondAPIKeyError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "API key revocada"})
+}
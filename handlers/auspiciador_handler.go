@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type AuspiciadorHandler struct {
+	auspiciadorService services.AuspiciadorService
+}
+
+func NewAuspiciadorHandler(auspiciadorService services.AuspiciadorService) *AuspiciadorHandler {
+	return &AuspiciadorHandler{
+		auspiciadorService: auspiciadorService,
+	}
+}
+
+// respondAuspiciadorError traduce los errores del servicio de auspiciadores a respuestas HTTP
+func respondAuspiciadorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrAuspiciadorNotFound), errors.Is(err, services.ErrAuspicioNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrAuspicioInvalido), errors.Is(err, services.ErrAuspicioVinculo),
+		errors.Is(err, services.ErrOrdenIncompleto):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetAuspiciadoresVigentes lista los auspiciadores que se muestran hoy en la web
+// para el torneo actual; acepta ?categoria_id=
+func (h *AuspiciadorHandler) GetAuspiciadoresVigentes(w http.ResponseWriter, r *http.Request) {
+	categoriaID, _ := strconv.Atoi(r.URL.Query().Get("categoria_id"))
+
+	auspiciadores, err := h.auspiciadorService.GetAuspiciadoresVigentes(categoriaID)
+	if err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, auspiciadores)
+}
+
+func (h *AuspiciadorHandler) GetAuspiciadores(w http.ResponseWriter, r *http.Request) {
+	auspiciadores, err := h.auspiciadorService.GetAuspiciadores()
+	if err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, auspiciadores)
+}
+
+func (h *AuspiciadorHandler) GetAuspiciador(w http.ResponseWriter, r *http.Request) {
+	id, ok := auspiciadorIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	auspiciador, err := h.auspiciadorService.GetAuspiciadorByID(id)
+	if err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, auspiciador)
+}
+
+type auspiciadorRequest struct {
+	Nombre      string `json:"nombre" validate:"required,min=2,max=255"`
+	LogoURL     string `json:"logo_url" validate:"omitempty,url,max=2048"`
+	EnlaceWeb   string `json:"enlace_web" validate:"omitempty,url,max=2048"`
+	Descripcion string `json:"descripcion" validate:"max=2000"`
+	Activo      *bool  `json:"activo"`
+}
+
+// auspiciador arma el modelo; sin "activo" el auspiciador queda activo
+func (req auspiciadorRequest) auspiciador() models.Auspiciador {
+	return models.Auspiciador{
+		Nombre:      utils.SanitizeString(req.Nombre),
+		LogoURL:     sql.NullString{String: req.LogoURL, Valid: req.LogoURL != ""},
+		EnlaceWeb:   sql.NullString{String: req.EnlaceWeb, Valid: req.EnlaceWeb != ""},
+		Descripcion: sql.NullString{String: utils.SanitizeString(req.Descripcion), Valid: req.Descripcion != ""},
+		Activo:      req.Activo == nil || *req.Activo,
+	}
+}
+
+func (h *AuspiciadorHandler) CreateAuspiciador(w http.ResponseWriter, r *http.Request) {
+	var request auspiciadorRequest
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	auspiciador := request.auspiciador()
+	if err := h.auspiciadorService.CreateAuspiciador(&auspiciador); err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, auspiciador)
+}
+
+func (h *AuspiciadorHandler) UpdateAuspiciador(w http.ResponseWriter, r *http.Request) {
+	id, ok := auspiciadorIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request auspiciadorRequest
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	auspiciador := request.auspiciador()
+	if err := h.auspiciadorService.UpdateAuspiciador(id, &auspiciador); err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	actualizado, err := h.auspiciadorService.GetAuspiciadorByID(id)
+	if err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, actualizado)
+}
+
+func (h *AuspiciadorHandler) DeleteAuspiciador(w http.ResponseWriter, r *http.Request) {
+	id, ok := auspiciadorIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.auspiciadorService.DeleteAuspiciador(id); err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Auspiciador eliminado exitosamente"})
+}
+
+// ReordenarAuspiciadores recibe todos los IDs en el nuevo orden de visualización
+func (h *AuspiciadorHandler) ReordenarAuspiciadores(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		IDs []int `json:"ids" validate:"required,min=1"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	if err := h.auspiciadorService.Reordenar(request.IDs); err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	auspiciadores, err := h.auspiciadorService.GetAuspiciadores()
+	if err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, auspiciadores)
+}
+
+// CreateAuspicio agrega un auspicio al auspiciador. Sin torneo ni categoría es
+// general; las fechas de vigencia son días (AAAA-MM-DD) y ambas son opcionales.
+func (h *AuspiciadorHandler) CreateAuspicio(w http.ResponseWriter, r *http.Request) {
+	id, ok := auspiciadorIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		TorneoID     int    `json:"torneo_id" validate:"omitempty,min=1"`
+		CategoriaID  int    `json:"categoria_id" validate:"omitempty,min=1"`
+		Nivel        string `json:"nivel" validate:"omitempty,oneof=oro plata bronce"`
+		VigenteDesde string `json:"vigente_desde" validate:"omitempty,datetime=2006-01-02"`
+		VigenteHasta string `json:"vigente_hasta" validate:"omitempty,datetime=2006-01-02"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	auspicio := models.Auspicio{
+		AuspiciadorID: id,
+		TorneoID:      sql.NullInt32{Int32: int32(request.TorneoID), Valid: request.TorneoID != 0},
+		CategoriaID:   sql.NullInt32{Int32: int32(request.CategoriaID), Valid: request.CategoriaID != 0},
+		Nivel:         request.Nivel,
+		VigenteDesde:  fechaOpcional(request.VigenteDesde),
+		VigenteHasta:  fechaOpcional(request.VigenteHasta),
+	}
+	if err := h.auspiciadorService.CreateAuspicio(&auspicio); err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, auspicio)
+}
+
+func (h *AuspiciadorHandler) DeleteAuspicio(w http.ResponseWriter, r *http.Request) {
+	id, ok := auspiciadorIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	auspicioID, err := strconv.Atoi(mux.Vars(r)["auspicio_id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de auspicio inválido")
+		return
+	}
+
+	if err := h.auspiciadorService.DeleteAuspicio(id, auspicioID); err != nil {
+		respondAuspiciadorError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Auspicio eliminado exitosamente"})
+}
+
+// fechaOpcional convierte un día AAAA-MM-DD ya validado; vacío es NULL
+func fechaOpcional(dia string) sql.NullTime {
+	fecha, err := time.Parse("2006-01-02", dia)
+	if err != nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: fecha, Valid: true}
+}
+
+func auspiciadorIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de auspiciador inválido")
+		return 0, false
+	}
+	return id, true
+}
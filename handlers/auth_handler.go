@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"copa-litoral-backend/config"
+	"copa-litoral-backend/middlewares"
 	"copa-litoral-backend/models"
 	"copa-litoral-backend/services"
 	"copa-litoral-backend/utils"
@@ -22,29 +27,24 @@ func NewAuthHandler(authService services.AuthService, config *config.Config) *Au
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
-	// Parsear y validar JSON
-	var usuario models.Usuario
-	if err := utils.ParseAndValidateJSON(r, &usuario); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
-		return
+	var request struct {
+		NombreUsuario string `json:"nombre_usuario" validate:"required,min=3,max=50,no_sql_injection,safe_string"`
+		Email         string `json:"email" validate:"omitempty,email,max=255"`
+		Password      string `json:"password" validate:"required,min=6,max=100"`
 	}
 
-	// Sanitizar inputs
-	usuario.NombreUsuario = utils.SanitizeString(usuario.NombreUsuario)
-	
-	// Validar que el password no esté vacío (viene del JSON)
-	if usuario.Password == "" {
-		utils.RespondWithError(w, http.StatusBadRequest, "Password es requerido")
+	// Parsear y validar JSON
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
 		return
 	}
 
-	// Mover password a PasswordHash para el servicio
-	usuario.PasswordHash = usuario.Password
-	usuario.Password = "" // Limpiar password del struct
-	
-	// Establecer rol por defecto si no se especifica
-	if usuario.Rol == "" {
-		usuario.Rol = "jugador"
+	// Sanitizar inputs; el password pasa como PasswordHash para que el servicio lo hashee.
+	// El rol no se acepta desde la solicitud: el registro público crea jugadores.
+	usuario := models.Usuario{
+		NombreUsuario: utils.SanitizeString(request.NombreUsuario),
+		Email:         sql.NullString{String: request.Email, Valid: request.Email != ""},
+		PasswordHash:  request.Password,
 	}
 
 	// Registrar usuario
@@ -73,16 +73,164 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	request.NombreUsuario = utils.SanitizeString(request.NombreUsuario)
 	request.Password = utils.SanitizeString(request.Password)
 
-	token, err := h.authService.LoginUser(request.NombreUsuario, request.Password)
+	result, err := h.authService.LoginUser(request.NombreUsuario, request.Password, middlewares.GetClientIP(r))
 	if err != nil {
+		var bloqueado *services.LoginBloqueadoError
+		if errors.As(err, &bloqueado) {
+			w.Header().Set("Retry-After", strconv.Itoa(bloqueado.RetryAfterSeconds()))
+			utils.RespondWithError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrCuentaDeshabilitada) {
+			utils.Forbidden(w, r, err.Error())
+			return
+		}
 		utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	response := map[string]string{
-		"token": token,
-		"message": "Login exitoso",
+	// La contraseña es correcta pero falta el segundo factor
+	if result.MFA != nil {
+		utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"mfa_requerido":    true,
+			"mfa_token":        result.MFA.Token,
+			"mfa_enrolamiento": result.MFA.Enrolamiento,
+			"expires_in":       result.MFA.ExpiresIn,
+			"message":          "Ingresá el código de verificación",
+		})
+		return
+	}
+
+	respondTokens(w, result.Tokens, nil)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.RefreshToken == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "refresh_token es requerido")
+		return
+	}
+
+	tokens, err := h.authService.RefreshSession(request.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenInvalido) || errors.Is(err, services.ErrRefreshTokenReutilizado) ||
+			errors.Is(err, services.ErrCuentaDeshabilitada) || errors.Is(err, services.ErrMFAObligatorio) {
+			utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middlewares.GetUserIDFromContext(r.Context())
+	sesionID, _ := middlewares.GetSesionIDFromContext(r.Context())
+
+	if err := h.authService.Logout(userID, sesionID); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Sesión cerrada"})
+}
+
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, response)
-} 
\ No newline at end of file
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Se cerraron todas las sesiones"})
+}
+
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Email string `json:"email" validate:"required,email,max=255"`
+	}
+
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(request.Email); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// La respuesta es la misma exista o no el email
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Si el email está registrado, vas a recibir un enlace para restablecer la contraseña",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required,min=6,max=100"`
+	}
+
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	if err := h.authService.ResetPassword(request.Token, request.Password); err != nil {
+		if errors.Is(err, services.ErrTokenUsuarioInvalido) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Contraseña actualizada; volvé a iniciar sesión"})
+}
+
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Token string `json:"token" validate:"required"`
+	}
+
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	if err := h.authService.VerifyEmail(request.Token); err != nil {
+		if errors.Is(err, services.ErrTokenUsuarioInvalido) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Email verificado"})
+}
+
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	if err := h.authService.SendVerificationEmail(userID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrSinEmail):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrEmailYaVerificado):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Te enviamos un nuevo enlace de verificación"})
+}
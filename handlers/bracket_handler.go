@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type BracketHandler struct {
+	bracketService services.BracketService
+}
+
+func NewBracketHandler(bracketService services.BracketService) *BracketHandler {
+	return &BracketHandler{
+		bracketService: bracketService,
+	}
+}
+
+// parseTorneoCategoria obtiene torneo_id y categoria_id de la ruta
+func parseTorneoCategoria(r *http.Request) (int, int, error) {
+	vars := mux.Vars(r)
+	torneoID, err := strconv.Atoi(vars["torneo_id"])
+	if err != nil {
+		return 0, 0, errors.New("ID de torneo inválido")
+	}
+
+	categoriaID, err := strconv.Atoi(vars["categoria_id"])
+	if err != nil {
+		return 0, 0, errors.New("ID de categoría inválido")
+	}
+
+	return torneoID, categoriaID, nil
+}
+
+func (h *BracketHandler) GetBracket(w http.ResponseWriter, r *http.Request) {
+	torneoID, categoriaID, err := parseTorneoCategoria(r)
+	if err != nil {
+		utils.BadRequest(w, r, err.Error(), nil)
+		return
+	}
+
+	partidos, err := h.bracketService.GetBracket(torneoID, categoriaID)
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "", partidos)
+}
+
+func (h *BracketHandler) GenerateBracket(w http.ResponseWriter, r *http.Request) {
+	torneoID, categoriaID, err := parseTorneoCategoria(r)
+	if err != nil {
+		utils.BadRequest(w, r, err.Error(), nil)
+		return
+	}
+
+	// Los jugadores se reciben ordenados por cabeza de serie
+	var request struct {
+		JugadorIDs []int `json:"jugador_ids"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.BadRequest(w, r, "Datos JSON inválidos", nil)
+		return
+	}
+
+	partidos, err := h.bracketService.GenerateBracket(torneoID, categoriaID, request.JugadorIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidBracketSeeds):
+			utils.BadRequest(w, r, err.Error(), nil)
+		case errors.Is(err, services.ErrBracketExists):
+			utils.Conflict(w, r, err.Error(), nil)
+		default:
+			utils.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	utils.Created(w, r, "Llave generada exitosamente", partidos)
+}
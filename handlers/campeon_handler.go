@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type CampeonHandler struct {
+	campeonService services.CampeonService
+}
+
+func NewCampeonHandler(campeonService services.CampeonService) *CampeonHandler {
+	return &CampeonHandler{
+		campeonService: campeonService,
+	}
+}
+
+// respondCampeonError traduce los errores del servicio de campeones a respuestas HTTP
+func respondCampeonError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrCampeonNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrCampeonAnio), errors.Is(err, services.ErrCampeonSinJugador),
+		errors.Is(err, services.ErrCampeonVinculo):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrCampeonDuplicado), errors.Is(err, services.ErrCampeonDerivado):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetCampeones lista el palmarés; acepta ?anio=, ?categoria_id= y ?jugador_id=
+func (h *CampeonHandler) GetCampeones(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	anio, _ := strconv.Atoi(query.Get("anio"))
+	categoriaID, _ := strconv.Atoi(query.Get("categoria_id"))
+	jugadorID, _ := strconv.Atoi(query.Get("jugador_id"))
+
+	campeones, err := h.campeonService.GetCampeones(services.FiltroCampeones{
+		Anio:        anio,
+		CategoriaID: categoriaID,
+		JugadorID:   jugadorID,
+	})
+	if err != nil {
+		respondCampeonError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, campeones)
+}
+
+// GetTitulos cuenta los títulos por campeón; acepta ?categoria_id= y ?min_titulos=
+// (2 para ver solo a los multicampeones)
+func (h *CampeonHandler) GetTitulos(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	categoriaID, _ := strconv.Atoi(query.Get("categoria_id"))
+	minimo, err := strconv.Atoi(query.Get("min_titulos"))
+	if err != nil || minimo < 1 {
+		minimo = 1
+	}
+
+	titulos, err := h.campeonService.GetTitulos(categoriaID, minimo)
+	if err != nil {
+		respondCampeonError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, titulos)
+}
+
+// campeonRequest es el cuerpo de alta y edición de un campeón histórico. Con torneo
+// el año puede omitirse; sin jugador registrado se indica su nombre.
+type campeonRequest struct {
+	TorneoID      int    `json:"torneo_id" validate:"omitempty,min=1"`
+	CategoriaID   int    `json:"categoria_id" validate:"required,min=1"`
+	JugadorID     int    `json:"jugador_id" validate:"omitempty,min=1"`
+	JugadorNombre string `json:"jugador_nombre" validate:"max=255"`
+	Anio          int    `json:"anio" validate:"omitempty,min=1900,max=2100"`
+}
+
+func (req campeonRequest) campeon() models.Campeon {
+	return models.Campeon{
+		TorneoID:      sql.NullInt32{Int32: int32(req.TorneoID), Valid: req.TorneoID != 0},
+		CategoriaID:   req.CategoriaID,
+		JugadorID:     sql.NullInt32{Int32: int32(req.JugadorID), Valid: req.JugadorID != 0},
+		JugadorNombre: utils.SanitizeString(req.JugadorNombre),
+		Anio:          req.Anio,
+	}
+}
+
+// parseCampeonRequest valida el cuerpo; sin torneo el año es obligatorio
+func parseCampeonRequest(w http.ResponseWriter, r *http.Request) (models.Campeon, bool) {
+	var request campeonRequest
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return models.Campeon{}, false
+	}
+	if request.TorneoID == 0 && request.Anio == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: sin torneo debe indicarse el año")
+		return models.Campeon{}, false
+	}
+	return request.campeon(), true
+}
+
+// CreateCampeon registra un campeón histórico, anterior al sistema
+func (h *CampeonHandler) CreateCampeon(w http.ResponseWriter, r *http.Request) {
+	campeon, ok := parseCampeonRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.campeonService.CreateCampeon(&campeon); err != nil {
+		respondCampeonError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, campeon)
+}
+
+func (h *CampeonHandler) UpdateCampeon(w http.ResponseWriter, r *http.Request) {
+	id, ok := campeonIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	campeon, ok := parseCampeonRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.campeonService.UpdateCampeon(id, &campeon); err != nil {
+		respondCampeonError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, campeon)
+}
+
+func (h *CampeonHandler) DeleteCampeon(w http.ResponseWriter, r *http.Request) {
+	id, ok := campeonIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.campeonService.DeleteCampeon(id); err != nil {
+		respondCampeonError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Campeón eliminado exitosamente"})
+}
+
+// DerivarCampeones recalcula los campeones desde las finales aprobadas; acepta
+// ?torneo_id= para limitarse a un torneo
+func (h *CampeonHandler) DerivarCampeones(w http.ResponseWriter, r *http.Request) {
+	torneoID, _ := strconv.Atoi(r.URL.Query().Get("torneo_id"))
+
+	resultado, err := h.campeonService.DerivarCampeones(torneoID)
+	if err != nil {
+		respondCampeonError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, resultado)
+}
+
+func campeonIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de campeón inválido")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// paginacionFotos muestra primero las fotos más recientes
+var paginacionFotos = utils.PaginationConfig{
+	DefaultLimit: 24,
+	MaxLimit:     100,
+	DefaultSort:  "created_at",
+	DefaultOrder: "desc",
+}
+
+type FotoHandler struct {
+	fotoService services.FotoService
+	maxBytes    int64
+}
+
+// NewFotoHandler recibe el tamaño máximo de una foto subida, en megabytes
+func NewFotoHandler(fotoService services.FotoService, maxMB int) *FotoHandler {
+	if maxMB <= 0 {
+		maxMB = 10
+	}
+	return &FotoHandler{
+		fotoService: fotoService,
+		maxBytes:    int64(maxMB) << 20,
+	}
+}
+
+// respondFotoError traduce los errores del servicio de fotos a respuestas HTTP
+func respondFotoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrFotoNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrFotoVinculo):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, utils.ErrImagenFormato), errors.Is(err, utils.ErrImagenDimensiones):
+		utils.RespondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetFotos lista la galería, paginada; acepta ?torneo_id= y ?partido_id=
+func (h *FotoHandler) GetFotos(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	torneoID, _ := strconv.Atoi(query.Get("torneo_id"))
+	partidoID, _ := strconv.Atoi(query.Get("partido_id"))
+
+	params := utils.ParsePaginationParams(r, paginacionFotos)
+	fotos, pagination, err := h.fotoService.GetFotos(params, services.FiltroFotos{TorneoID: torneoID, PartidoID: partidoID})
+	if err != nil {
+		respondFotoError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.CreatePaginatedResponse(fotos, pagination, ""))
+}
+
+func (h *FotoHandler) GetFoto(w http.ResponseWriter, r *http.Request) {
+	id, ok := fotoIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	foto, err := h.fotoService.GetFotoByID(id)
+	if err != nil {
+		respondFotoError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, foto)
+}
+
+// fotoDatos son los datos descriptivos de una foto, en el formulario de subida o
+// en el cuerpo JSON de la edición
+type fotoDatos struct {
+	Titulo      string `json:"titulo" validate:"max=255"`
+	Descripcion string `json:"descripcion" validate:"max=2000"`
+	TorneoID    int    `json:"torneo_id" validate:"omitempty,min=1"`
+	PartidoID   int    `json:"partido_id" validate:"omitempty,min=1"`
+}
+
+func (d fotoDatos) foto() models.FotoDestacada {
+	return models.FotoDestacada{
+		Titulo:      sql.NullString{String: utils.SanitizeString(d.Titulo), Valid: d.Titulo != ""},
+		Descripcion: sql.NullString{String: utils.SanitizeString(d.Descripcion), Valid: d.Descripcion != ""},
+		TorneoID:    sql.NullInt32{Int32: int32(d.TorneoID), Valid: d.TorneoID != 0},
+		PartidoID:   sql.NullInt32{Int32: int32(d.PartidoID), Valid: d.PartidoID != 0},
+	}
+}
+
+// SubirFoto recibe un formulario multipart con el archivo en "foto" y, opcionales,
+// "titulo", "descripcion", "torneo_id" y "partido_id"
+func (h *FotoHandler) SubirFoto(w http.ResponseWriter, r *http.Request) {
+	// Margen para los demás campos del formulario
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes+1<<20)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.RespondWithError(w, http.StatusRequestEntityTooLarge, h.mensajeTamano())
+			return
+		}
+		utils.RespondWithError(w, http.StatusBadRequest, "Se esperaba un formulario multipart con el archivo en el campo foto")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	archivo, _, err := r.FormFile("foto")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Falta el archivo en el campo foto")
+		return
+	}
+	defer archivo.Close()
+
+	datos, err := io.ReadAll(io.LimitReader(archivo, h.maxBytes+1))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "No se pudo leer el archivo")
+		return
+	}
+	if int64(len(datos)) > h.maxBytes {
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, h.mensajeTamano())
+		return
+	}
+
+	campos := fotoDatos{
+		Titulo:      strings.TrimSpace(r.FormValue("titulo")),
+		Descripcion: strings.TrimSpace(r.FormValue("descripcion")),
+	}
+	for _, campo := range []struct {
+		nombre  string
+		destino *int
+	}{{"torneo_id", &campos.TorneoID}, {"partido_id", &campos.PartidoID}} {
+		if valor := r.FormValue(campo.nombre); valor != "" {
+			if *campo.destino, err = strconv.Atoi(valor); err != nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+campo.nombre+" debe ser un número")
+				return
+			}
+		}
+	}
+	if err := utils.ValidateStruct(campos); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	imagen, err := utils.ProcesarImagen(datos)
+	if err != nil {
+		respondFotoError(w, err)
+		return
+	}
+
+	foto := campos.foto()
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+	foto.SubidaPor = sql.NullInt32{Int32: int32(usuarioID), Valid: usuarioID != 0}
+	if err := h.fotoService.SubirFoto(r.Context(), &foto, imagen); err != nil {
+		respondFotoError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, foto)
+}
+
+func (h *FotoHandler) mensajeTamano() string {
+	return fmt.Sprintf("La foto no puede superar los %d MB", h.maxBytes>>20)
+}
+
+// UpdateFoto reemplaza el título, la descripción, el torneo y el partido de la foto
+func (h *FotoHandler) UpdateFoto(w http.ResponseWriter, r *http.Request) {
+	id, ok := fotoIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request fotoDatos
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	foto := request.foto()
+	if err := h.fotoService.UpdateFoto(id, &foto); err != nil {
+		respondFotoError(w, err)
+		return
+	}
+
+	actualizada, err := h.fotoService.GetFotoByID(id)
+	if err != nil {
+		respondFotoError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, actualizada)
+}
+
+// DeleteFoto elimina la foto y sus archivos
+func (h *FotoHandler) DeleteFoto(w http.ResponseWriter, r *http.Request) {
+	id, ok := fotoIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.fotoService.DeleteFoto(r.Context(), id); err != nil {
+		respondFotoError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Foto eliminada exitosamente"})
+}
+
+func fotoIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de foto inválido")
+		return 0, false
+	}
+	return id, true
+}
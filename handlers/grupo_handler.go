@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type GrupoHandler struct {
+	grupoService services.GrupoService
+}
+
+func NewGrupoHandler(grupoService services.GrupoService) *GrupoHandler {
+	return &GrupoHandler{
+		grupoService: grupoService,
+	}
+}
+
+// respondGrupoError traduce los errores del servicio de grupos a respuestas HTTP
+func respondGrupoError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrGrupoNotFound):
+		utils.NotFound(w, r, "Grupo")
+	case errors.Is(err, services.ErrInvalidGrupo):
+		utils.BadRequest(w, r, err.Error(), nil)
+	case errors.Is(err, services.ErrFixtureExists), errors.Is(err, services.ErrGrupoDuplicado):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+func (h *GrupoHandler) GetGrupos(w http.ResponseWriter, r *http.Request) {
+	torneoID, categoriaID, err := parseTorneoCategoria(r)
+	if err != nil {
+		utils.BadRequest(w, r, err.Error(), nil)
+		return
+	}
+
+	grupos, err := h.grupoService.GetGrupos(torneoID, categoriaID)
+	if err != nil {
+		respondGrupoError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "", grupos)
+}
+
+func (h *GrupoHandler) GetGrupo(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID inválido", nil)
+		return
+	}
+
+	grupo, err := h.grupoService.GetGrupoByID(id)
+	if err != nil {
+		respondGrupoError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "", grupo)
+}
+
+func (h *GrupoHandler) CreateGrupo(w http.ResponseWriter, r *http.Request) {
+	torneoID, categoriaID, err := parseTorneoCategoria(r)
+	if err != nil {
+		utils.BadRequest(w, r, err.Error(), nil)
+		return
+	}
+
+	var request struct {
+		Nombre             string   `json:"nombre"`
+		JugadorIDs         []int    `json:"jugador_ids"`
+		CriteriosDesempate []string `json:"criterios_desempate"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.BadRequest(w, r, "Datos JSON inválidos", nil)
+		return
+	}
+
+	grupo := models.Grupo{
+		TorneoID:           torneoID,
+		CategoriaID:        categoriaID,
+		Nombre:             utils.SanitizeString(request.Nombre),
+		CriteriosDesempate: request.CriteriosDesempate,
+	}
+
+	if err := h.grupoService.CreateGrupo(&grupo, request.JugadorIDs); err != nil {
+		respondGrupoError(w, r, err)
+		return
+	}
+
+	utils.Created(w, r, "Grupo creado exitosamente", grupo)
+}
+
+func (h *GrupoHandler) GenerateFixture(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID inválido", nil)
+		return
+	}
+
+	partidos, err := h.grupoService.GenerateFixture(id)
+	if err != nil {
+		respondGrupoError(w, r, err)
+		return
+	}
+
+	utils.Created(w, r, "Fixture generado exitosamente", partidos)
+}
+
+func (h *GrupoHandler) GetStandings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID inválido", nil)
+		return
+	}
+
+	tabla, err := h.grupoService.GetStandings(id)
+	if err != nil {
+		respondGrupoError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "", tabla)
+}
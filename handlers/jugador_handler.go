@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"copa-litoral-backend/middlewares"
 	"copa-litoral-backend/models"
 	"copa-litoral-backend/services"
 	"copa-litoral-backend/utils"
@@ -56,6 +57,12 @@ func (h *JugadorHandler) CreateJugador(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// La ruta solo verifica que tenga el permiso en algún alcance
+	alcance := models.Alcance{CategoriaID: int(jugador.CategoriaID.Int32)}
+	if !middlewares.CheckPermission(w, r, models.PermisoJugadoresEdit, alcance) {
+		return
+	}
+
 	if err := h.jugadorService.CreateJugador(&jugador); err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -78,6 +85,12 @@ func (h *JugadorHandler) UpdateJugador(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// La ruta verificó la categoría actual del jugador; también debe poder editar la de destino
+	alcance := models.Alcance{CategoriaID: int(jugador.CategoriaID.Int32)}
+	if !middlewares.CheckPermission(w, r, models.PermisoJugadoresEdit, alcance) {
+		return
+	}
+
 	if err := h.jugadorService.UpdateJugador(id, &jugador); err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+)
+
+// respondMFAError traduce los errores de la verificación en dos pasos a respuestas HTTP
+func respondMFAError(w http.ResponseWriter, r *http.Request, err error) {
+	var bloqueado *services.LoginBloqueadoError
+	switch {
+	case errors.As(err, &bloqueado):
+		w.Header().Set("Retry-After", strconv.Itoa(bloqueado.RetryAfterSeconds()))
+		utils.RespondWithError(w, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, services.ErrMFATokenInvalido):
+		utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrCodigoMFAInvalido), errors.Is(err, services.ErrPasswordActualIncorrecta):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrCuentaDeshabilitada), errors.Is(err, services.ErrMFAObligatorio):
+		utils.Forbidden(w, r, err.Error())
+	case errors.Is(err, services.ErrUsuarioNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrMFAYaHabilitado), errors.Is(err, services.ErrMFANoHabilitado),
+		errors.Is(err, services.ErrMFASinEnrolar):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func respondTokens(w http.ResponseWriter, tokens *services.TokenPair, extra map[string]interface{}) {
+	response := map[string]interface{}{
+		"token":         tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"message":       "Login exitoso",
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// VerifyMFA completa el login con el mfa_token y un código TOTP o de recuperación
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		MFAToken string `json:"mfa_token" validate:"required"`
+		Codigo   string `json:"codigo" validate:"required,max=32"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	tokens, err := h.authService.VerifyMFA(request.MFAToken, request.Codigo, middlewares.GetClientIP(r))
+	if err != nil {
+		respondMFAError(w, r, err)
+		return
+	}
+
+	respondTokens(w, tokens, nil)
+}
+
+// EnrollMFAPending genera el secreto durante el login de una cuenta que debe activar MFA
+func (h *AuthHandler) EnrollMFAPending(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		MFAToken string `json:"mfa_token" validate:"required"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	enrolamiento, err := h.authService.EnrollMFAPending(request.MFAToken)
+	if err != nil {
+		respondMFAError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, enrolamiento)
+}
+
+// ActivateMFAPending activa MFA durante el login y entrega los tokens junto a los
+// códigos de recuperación
+func (h *AuthHandler) ActivateMFAPending(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		MFAToken string `json:"mfa_token" validate:"required"`
+		Codigo   string `json:"codigo" validate:"required,len=6,numeric"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	codigos, tokens, err := h.authService.ActivateMFAPending(request.MFAToken, request.Codigo)
+	if err != nil {
+		respondMFAError(w, r, err)
+		return
+	}
+
+	respondTokens(w, tokens, map[string]interface{}{"codigos_recuperacion": codigos})
+}
+
+func (h *AuthHandler) GetMFAStatus(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	estado, err := h.authService.GetMFAStatus(userID)
+	if err != nil {
+		respondMFAError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, estado)
+}
+
+// EnrollMFA genera el secreto TOTP y la URI para mostrar como QR
+func (h *AuthHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	enrolamiento, err := h.authService.EnrollMFA(userID)
+	if err != nil {
+		respondMFAError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, enrolamiento)
+}
+
+// ActivateMFA confirma el secreto con un código y devuelve los códigos de
+// recuperación, que no se vuelven a mostrar
+func (h *AuthHandler) ActivateMFA(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Codigo string `json:"codigo" validate:"required,len=6,numeric"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	userID, _ := middlewares.GetUserIDFromContext(r.Context())
+	codigos, err := h.authService.ActivateMFA(userID, request.Codigo)
+	if err != nil {
+		respondMFAError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"codigos_recuperacion": codigos})
+}
+
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Password string `json:"password" validate:"required,max=100"`
+		Codigo   string `json:"codigo" validate:"required,max=32"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	userID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.authService.DisableMFA(userID, request.Password, request.Codigo); err != nil {
+		respondMFAError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Verificación en dos pasos desactivada"})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Codigo string `json:"codigo" validate:"required,len=6,numeric"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	userID, _ := middlewares.GetUserIDFromContext(r.Context())
+	codigos, err := h.authService.RegenerateRecoveryCodes(userID, request.Codigo)
+	if err != nil {
+		respondMFAError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"codigos_recuperacion": codigos})
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// paginacionNoticias muestra primero las noticias más recientes
+var paginacionNoticias = utils.PaginationConfig{
+	DefaultLimit: 10,
+	MaxLimit:     50,
+	DefaultSort:  "fecha_publicacion",
+	DefaultOrder: "desc",
+}
+
+type NoticiaHandler struct {
+	noticiaService services.NoticiaService
+}
+
+func NewNoticiaHandler(noticiaService services.NoticiaService) *NoticiaHandler {
+	return &NoticiaHandler{
+		noticiaService: noticiaService,
+	}
+}
+
+// respondNoticiaError traduce los errores del servicio de noticias a respuestas HTTP
+func respondNoticiaError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrNoticiaNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrNoticiaEstado), errors.Is(err, services.ErrNoticiaSlugInvalido),
+		errors.Is(err, services.ErrNoticiaVinculo):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrNoticiaSlugEnUso):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// filtroNoticias lee los filtros ?torneo_id=, ?partido_id= y ?jugador_id=
+func filtroNoticias(r *http.Request) services.FiltroNoticias {
+	query := r.URL.Query()
+	torneoID, _ := strconv.Atoi(query.Get("torneo_id"))
+	partidoID, _ := strconv.Atoi(query.Get("partido_id"))
+	jugadorID, _ := strconv.Atoi(query.Get("jugador_id"))
+	return services.FiltroNoticias{TorneoID: torneoID, PartidoID: partidoID, JugadorID: jugadorID}
+}
+
+// GetNoticiasPublicadas lista las noticias visibles en la web, paginadas
+func (h *NoticiaHandler) GetNoticiasPublicadas(w http.ResponseWriter, r *http.Request) {
+	filtro := filtroNoticias(r)
+	filtro.Publicadas = true
+
+	h.listar(w, r, filtro)
+}
+
+// GetNoticias lista todas las noticias para los editores; acepta ?estado=
+func (h *NoticiaHandler) GetNoticias(w http.ResponseWriter, r *http.Request) {
+	filtro := filtroNoticias(r)
+	filtro.Estado = r.URL.Query().Get("estado")
+
+	h.listar(w, r, filtro)
+}
+
+func (h *NoticiaHandler) listar(w http.ResponseWriter, r *http.Request, filtro services.FiltroNoticias) {
+	params := utils.ParsePaginationParams(r, paginacionNoticias)
+	noticias, pagination, err := h.noticiaService.GetNoticias(params, filtro)
+	if err != nil {
+		respondNoticiaError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.CreatePaginatedResponse(noticias, pagination, ""))
+}
+
+func (h *NoticiaHandler) GetNoticiaBySlug(w http.ResponseWriter, r *http.Request) {
+	noticia, err := h.noticiaService.GetNoticiaPublicadaBySlug(mux.Vars(r)["slug"])
+	if err != nil {
+		respondNoticiaError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, noticia)
+}
+
+func (h *NoticiaHandler) GetNoticia(w http.ResponseWriter, r *http.Request) {
+	id, ok := noticiaIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	noticia, err := h.noticiaService.GetNoticiaByID(id)
+	if err != nil {
+		respondNoticiaError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, noticia)
+}
+
+// noticiaRequest es el cuerpo de alta y edición. Sin slug se genera a partir del
+// título; sin fecha, publicar publica en el momento y una fecha futura la programa.
+type noticiaRequest struct {
+	Titulo             string     `json:"titulo" validate:"required,max=255"`
+	Slug               string     `json:"slug" validate:"max=200"`
+	Contenido          string     `json:"contenido" validate:"required"`
+	Estado             string     `json:"estado" validate:"omitempty,oneof=borrador publicada archivada"`
+	FechaPublicacion   *time.Time `json:"fecha_publicacion"`
+	ImagenDestacadaURL string     `json:"imagen_destacada_url" validate:"omitempty,url,max=2048"`
+	TorneoIDs          []int      `json:"torneo_ids"`
+	PartidoIDs         []int      `json:"partido_ids"`
+	JugadorIDs         []int      `json:"jugador_ids"`
+}
+
+// noticia arma el modelo; el contenido se guarda tal cual porque admite formato
+func (req noticiaRequest) noticia() models.Noticia {
+	noticia := models.Noticia{
+		Titulo:     utils.SanitizeString(req.Titulo),
+		Slug:       strings.TrimSpace(req.Slug),
+		Contenido:  req.Contenido,
+		Estado:     req.Estado,
+		TorneoIDs:  req.TorneoIDs,
+		PartidoIDs: req.PartidoIDs,
+		JugadorIDs: req.JugadorIDs,
+	}
+	if req.FechaPublicacion != nil {
+		noticia.FechaPublicacion = sql.NullTime{Time: *req.FechaPublicacion, Valid: true}
+	}
+	if req.ImagenDestacadaURL != "" {
+		noticia.ImagenDestacadaURL = sql.NullString{String: req.ImagenDestacadaURL, Valid: true}
+	}
+	return noticia
+}
+
+func (h *NoticiaHandler) CreateNoticia(w http.ResponseWriter, r *http.Request) {
+	var request noticiaRequest
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	noticia := request.noticia()
+	autorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	noticia.AutorID = sql.NullInt32{Int32: int32(autorID), Valid: autorID != 0}
+	if err := h.noticiaService.CreateNoticia(&noticia); err != nil {
+		respondNoticiaError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, noticia)
+}
+
+func (h *NoticiaHandler) UpdateNoticia(w http.ResponseWriter, r *http.Request) {
+	id, ok := noticiaIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request noticiaRequest
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	noticia := request.noticia()
+	if err := h.noticiaService.UpdateNoticia(id, &noticia); err != nil {
+		respondNoticiaError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, noticia)
+}
+
+func (h *NoticiaHandler) DeleteNoticia(w http.ResponseWriter, r *http.Request) {
+	id, ok := noticiaIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.noticiaService.DeleteNoticia(id); err != nil {
+		respondNoticiaError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Noticia eliminada exitosamente"})
+}
+
+func noticiaIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de noticia inválido")
+		return 0, false
+	}
+	return id, true
+}
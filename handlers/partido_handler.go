@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
+	"copa-litoral-backend/middlewares"
 	"copa-litoral-backend/models"
 	"copa-litoral-backend/services"
 	"copa-litoral-backend/utils"
@@ -23,6 +25,48 @@ func NewPartidoHandler(partidoService services.PartidoService) *PartidoHandler {
 	}
 }
 
+// jugadorActuante resuelve en nombre de qué jugador actúa el usuario autenticado.
+// Un jugador solo puede actuar por sí mismo; un administrador puede indicar el
+// jugador en la solicitud o actuar como organizador (0).
+func jugadorActuante(r *http.Request, solicitado int) (int, error) {
+	if rol, _ := middlewares.GetRolFromContext(r.Context()); rol == "administrador" {
+		return solicitado, nil
+	}
+
+	jugadorID, ok := middlewares.GetJugadorIDFromContext(r.Context())
+	if !ok {
+		return 0, errors.New("El usuario no está vinculado a un jugador")
+	}
+	if solicitado != 0 && solicitado != jugadorID {
+		return 0, errors.New("No puede actuar en nombre de otro jugador")
+	}
+	return jugadorID, nil
+}
+
+// respondPartidoError traduce los errores del servicio de partidos a respuestas HTTP
+func respondPartidoError(w http.ResponseWriter, r *http.Request, err error) {
+	var scoreErr *services.ScoreValidationError
+	switch {
+	case errors.As(err, &scoreErr):
+		utils.ValidationErrors(w, r, scoreErr.Errors)
+	case errors.Is(err, services.ErrPartidoNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrJugadorAjeno):
+		utils.Forbidden(w, r, err.Error())
+	case errors.Is(err, services.ErrTransicionInvalida):
+		utils.Conflict(w, r, err.Error(), nil)
+	case errors.Is(err, services.ErrRivalesPorDefinir),
+		errors.Is(err, services.ErrResultadoEnDisputa),
+		errors.Is(err, services.ErrSinDisputa),
+		errors.Is(err, services.ErrResultNotReported),
+		errors.Is(err, services.ErrResultAlreadyApproved),
+		errors.Is(err, services.ErrNextMatchAlreadyPlayed):
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
 func (h *PartidoHandler) GetPartidos(w http.ResponseWriter, r *http.Request) {
 	categoriaIDStr := r.URL.Query().Get("categoria_id")
 	categoriaID := 0
@@ -69,8 +113,15 @@ func (h *PartidoHandler) CreatePartido(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.partidoService.CreatePartido(&partido); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	// La ruta solo verifica que tenga el permiso en algún alcance
+	alcance := models.Alcance{TorneoID: partido.TorneoID, CategoriaID: partido.CategoriaID}
+	if !middlewares.CheckPermission(w, r, models.PermisoPartidosEdit, alcance) {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.partidoService.CreatePartido(&partido, actorID); err != nil {
+		respondPartidoError(w, r, err)
 		return
 	}
 
@@ -91,8 +142,15 @@ func (h *PartidoHandler) UpdatePartido(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.partidoService.UpdatePartido(id, &partido); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	// La ruta verificó el alcance actual del partido; también debe poder editar el de destino
+	alcance := models.Alcance{TorneoID: partido.TorneoID, CategoriaID: partido.CategoriaID}
+	if !middlewares.CheckPermission(w, r, models.PermisoPartidosEdit, alcance) {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.partidoService.UpdatePartido(id, &partido, actorID); err != nil {
+		respondPartidoError(w, r, err)
 		return
 	}
 
@@ -115,7 +173,32 @@ func (h *PartidoHandler) DeletePartido(w http.ResponseWriter, r *http.Request) {
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Partido eliminado exitosamente"})
 }
 
-func (h *PartidoHandler) ProposeMatchTime(w http.ResponseWriter, r *http.Request) {
+// setRequest es un set tal como se reporta; los puntos del tie-break se omiten
+// si el set no se definió por tie-break
+type setRequest struct {
+	NumeroSet     int  `json:"numero_set"`
+	ScoreJugador1 int  `json:"score_jugador1"`
+	ScoreJugador2 int  `json:"score_jugador2"`
+	TieBreakJ1    *int `json:"tie_break_j1"`
+	TieBreakJ2    *int `json:"tie_break_j2"`
+}
+
+func (s setRequest) toModel() models.SetPartido {
+	set := models.SetPartido{
+		NumeroSet:     s.NumeroSet,
+		ScoreJugador1: s.ScoreJugador1,
+		ScoreJugador2: s.ScoreJugador2,
+	}
+	if s.TieBreakJ1 != nil {
+		set.TieBreakJ1 = sql.NullInt32{Int32: int32(*s.TieBreakJ1), Valid: true}
+	}
+	if s.TieBreakJ2 != nil {
+		set.TieBreakJ2 = sql.NullInt32{Int32: int32(*s.TieBreakJ2), Valid: true}
+	}
+	return set
+}
+
+func (h *PartidoHandler) ReportMatchResult(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	partidoID, err := strconv.Atoi(vars["id"])
 	if err != nil {
@@ -123,12 +206,12 @@ func (h *PartidoHandler) ProposeMatchTime(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Obtener el jugador ID del contexto JWT (esto se implementará en el middleware)
-	jugadorID := 1 // Placeholder - se obtendrá del contexto JWT
-
 	var request struct {
-		Fecha string `json:"fecha"`
-		Hora  string `json:"hora"`
+		JugadorID     int          `json:"jugador_id"`
+		SetsGanadosJ1 int          `json:"sets_ganados_j1"`
+		SetsGanadosJ2 int          `json:"sets_ganados_j2"`
+		GanadorID     int          `json:"ganador_id"`
+		Sets          []setRequest `json:"sets"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -136,58 +219,76 @@ func (h *PartidoHandler) ProposeMatchTime(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	fecha, err := time.Parse("2006-01-02", request.Fecha)
+	sets := make([]models.SetPartido, len(request.Sets))
+	for i, set := range request.Sets {
+		sets[i] = set.toModel()
+	}
+
+	jugadorID, err := jugadorActuante(r, request.JugadorID)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Formato de fecha inválido (YYYY-MM-DD)")
+		utils.Forbidden(w, r, err.Error())
 		return
 	}
 
-	hora, err := time.Parse("15:04", request.Hora)
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	estado, err := h.partidoService.ReportMatchResult(partidoID, jugadorID, request.SetsGanadosJ1, request.SetsGanadosJ2, request.GanadorID, sets, actorID)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Formato de hora inválido (HH:MM)")
+		respondPartidoError(w, r, err)
 		return
 	}
 
-	if err := h.partidoService.ProposeMatchTime(partidoID, jugadorID, fecha, hora); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
-		return
+	message := "Resultado reportado exitosamente"
+	switch estado {
+	case models.ReportePendiente:
+		message = "Resultado reportado; falta la confirmación del rival"
+	case models.ReporteConfirmado:
+		message = "Resultado confirmado; pendiente de aprobación"
+	case models.ReporteEnDisputa:
+		message = "El resultado no coincide con el reportado por el rival; se abrió una disputa"
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Propuesta de horario enviada exitosamente"})
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": message, "estado": string(estado)})
 }
 
-func (h *PartidoHandler) AcceptMatchTime(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	partidoID, err := strconv.Atoi(vars["id"])
+func (h *PartidoHandler) GetReportes(w http.ResponseWriter, r *http.Request) {
+	partidoID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "ID de partido inválido")
 		return
 	}
 
-	// Obtener el jugador ID del contexto JWT
-	jugadorID := 1 // Placeholder - se obtendrá del contexto JWT
+	reportes, err := h.partidoService.GetReportes(partidoID)
+	if err != nil {
+		respondPartidoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, reportes)
+}
 
-	if err := h.partidoService.AcceptMatchTime(partidoID, jugadorID); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+func (h *PartidoHandler) GetDisputas(w http.ResponseWriter, r *http.Request) {
+	disputas, err := h.partidoService.GetDisputasAbiertas()
+	if err != nil {
+		respondPartidoError(w, r, err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Horario aceptado exitosamente"})
+	utils.RespondWithJSON(w, http.StatusOK, disputas)
 }
 
-func (h *PartidoHandler) ReportMatchResult(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	partidoID, err := strconv.Atoi(vars["id"])
+func (h *PartidoHandler) ResolveDispute(w http.ResponseWriter, r *http.Request) {
+	partidoID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "ID de partido inválido")
 		return
 	}
 
 	var request struct {
-		SetsGanadosJ1 int              `json:"sets_ganados_j1"`
-		SetsGanadosJ2 int              `json:"sets_ganados_j2"`
-		GanadorID     int              `json:"ganador_id"`
-		Sets          []models.SetPartido `json:"sets"`
+		SetsGanadosJ1 int          `json:"sets_ganados_j1"`
+		SetsGanadosJ2 int          `json:"sets_ganados_j2"`
+		GanadorID     int          `json:"ganador_id"`
+		Sets          []setRequest `json:"sets"`
+		Nota          string       `json:"nota"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -195,12 +296,20 @@ func (h *PartidoHandler) ReportMatchResult(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := h.partidoService.ReportMatchResult(partidoID, request.SetsGanadosJ1, request.SetsGanadosJ2, request.GanadorID, request.Sets); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	sets := make([]models.SetPartido, len(request.Sets))
+	for i, set := range request.Sets {
+		sets[i] = set.toModel()
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	err = h.partidoService.ResolveDispute(partidoID, request.SetsGanadosJ1, request.SetsGanadosJ2, request.GanadorID,
+		sets, utils.SanitizeString(request.Nota), actorID)
+	if err != nil {
+		respondPartidoError(w, r, err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Resultado reportado exitosamente"})
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Disputa resuelta; el resultado quedó aprobado"})
 }
 
 func (h *PartidoHandler) ApproveResult(w http.ResponseWriter, r *http.Request) {
@@ -211,10 +320,132 @@ func (h *PartidoHandler) ApproveResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.partidoService.ApproveResult(partidoID); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.partidoService.ApproveResult(partidoID, actorID); err != nil {
+		respondPartidoError(w, r, err)
 		return
 	}
 
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Resultado aprobado exitosamente"})
-} 
\ No newline at end of file
+}
+
+// outcomeRequest es el cuerpo para registrar un walkover, abandono o descalificación
+type outcomeRequest struct {
+	BeneficiarioID int          `json:"beneficiario_id"`
+	Motivo         string       `json:"motivo"`
+	Sets           []setRequest `json:"sets"`
+}
+
+// decodeOutcome obtiene el ID del partido y el cuerpo de un resultado especial
+func decodeOutcome(w http.ResponseWriter, r *http.Request) (int, *outcomeRequest, []models.SetPartido, bool) {
+	partidoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de partido inválido")
+		return 0, nil, nil, false
+	}
+
+	var request outcomeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos JSON inválidos")
+		return 0, nil, nil, false
+	}
+	request.Motivo = utils.SanitizeString(request.Motivo)
+
+	sets := make([]models.SetPartido, len(request.Sets))
+	for i, set := range request.Sets {
+		sets[i] = set.toModel()
+	}
+
+	return partidoID, &request, sets, true
+}
+
+// respondOutcome traduce el resultado de registrar un resultado especial
+func respondOutcome(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if err != nil {
+		respondPartidoError(w, r, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+func (h *PartidoHandler) RecordWalkover(w http.ResponseWriter, r *http.Request) {
+	partidoID, request, _, ok := decodeOutcome(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	if len(request.Sets) > 0 {
+		err = &services.ScoreValidationError{Errors: []utils.ErrorDetail{
+			utils.CreateFieldError("sets", utils.ErrInvalidInput, "un walkover no tiene sets jugados", len(request.Sets)),
+		}}
+	} else {
+		actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+		err = h.partidoService.RecordWalkover(partidoID, request.BeneficiarioID, request.Motivo, actorID)
+	}
+	respondOutcome(w, r, err, "Walkover registrado exitosamente")
+}
+
+func (h *PartidoHandler) RecordRetirement(w http.ResponseWriter, r *http.Request) {
+	partidoID, request, sets, ok := decodeOutcome(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	err := h.partidoService.RecordRetirement(partidoID, request.BeneficiarioID, request.Motivo, sets, actorID)
+	respondOutcome(w, r, err, "Abandono registrado exitosamente")
+}
+
+func (h *PartidoHandler) RecordDefault(w http.ResponseWriter, r *http.Request) {
+	partidoID, request, sets, ok := decodeOutcome(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	err := h.partidoService.RecordDefault(partidoID, request.BeneficiarioID, request.Motivo, sets, actorID)
+	respondOutcome(w, r, err, "Descalificación registrada exitosamente")
+}
+
+func (h *PartidoHandler) ChangeEstado(w http.ResponseWriter, r *http.Request) {
+	partidoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de partido inválido")
+		return
+	}
+
+	var request struct {
+		Estado  models.EstadoPartido `json:"estado"`
+		Detalle string               `json:"detalle"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos JSON inválidos")
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.partidoService.ChangeEstado(partidoID, request.Estado, actorID, utils.SanitizeString(request.Detalle)); err != nil {
+		respondPartidoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Estado del partido actualizado exitosamente"})
+}
+
+func (h *PartidoHandler) GetHistorial(w http.ResponseWriter, r *http.Request) {
+	partidoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de partido inválido")
+		return
+	}
+
+	eventos, err := h.partidoService.GetHistorial(partidoID)
+	if err != nil {
+		respondPartidoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, eventos)
+}
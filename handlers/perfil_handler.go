@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+)
+
+type PerfilHandler struct {
+	perfilService services.PerfilService
+}
+
+func NewPerfilHandler(perfilService services.PerfilService) *PerfilHandler {
+	return &PerfilHandler{
+		perfilService: perfilService,
+	}
+}
+
+// respondPerfilError traduce los errores del servicio de perfil a respuestas HTTP
+func respondPerfilError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrUsuarioNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPasswordActualIncorrecta):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrEmailEnUso), errors.Is(err, services.ErrSinJugador):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetPerfil devuelve la cuenta del usuario autenticado y su jugador vinculado
+func (h *PerfilHandler) GetPerfil(w http.ResponseWriter, r *http.Request) {
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	perfil, err := h.perfilService.GetPerfil(usuarioID)
+	if err != nil {
+		respondPerfilError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, perfil)
+}
+
+// UpdatePerfil cambia el email, la visibilidad del contacto o la contraseña; para
+// cambiar la contraseña hay que enviar también la actual
+func (h *PerfilHandler) UpdatePerfil(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Email                *string `json:"email" validate:"omitempty,email,max=255"`
+		ContactoVisibleEnWeb *bool   `json:"contacto_visible_en_web"`
+		PasswordActual       string  `json:"password_actual" validate:"required_with=PasswordNuevo,max=100"`
+		PasswordNuevo        string  `json:"password_nuevo" validate:"omitempty,min=6,max=100"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+	sesionID, _ := middlewares.GetSesionIDFromContext(r.Context())
+	perfil, err := h.perfilService.UpdatePerfil(usuarioID, sesionID, services.CambiosPerfil{
+		Email:                request.Email,
+		ContactoVisibleEnWeb: request.ContactoVisibleEnWeb,
+		PasswordActual:       request.PasswordActual,
+		PasswordNuevo:        request.PasswordNuevo,
+	})
+	if err != nil {
+		respondPerfilError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, perfil)
+}
+
+// GetMisPartidos lista los partidos del jugador vinculado; acepta ?estado=
+func (h *PerfilHandler) GetMisPartidos(w http.ResponseWriter, r *http.Request) {
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	partidos, err := h.perfilService.GetMisPartidos(usuarioID, r.URL.Query().Get("estado"))
+	if err != nil {
+		respondPerfilError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, partidos)
+}
+
+func (h *PerfilHandler) GetMisPropuestas(w http.ResponseWriter, r *http.Request) {
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	propuestas, err := h.perfilService.GetMisPropuestasPendientes(usuarioID)
+	if err != nil {
+		respondPerfilError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, propuestas)
+}
+
+func (h *PerfilHandler) GetMisResultadosPorConfirmar(w http.ResponseWriter, r *http.Request) {
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	partidos, err := h.perfilService.GetMisResultadosPorConfirmar(usuarioID)
+	if err != nil {
+		respondPerfilError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, partidos)
+}
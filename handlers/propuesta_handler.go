@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type PropuestaHandler struct {
+	propuestaService services.PropuestaService
+}
+
+func NewPropuestaHandler(propuestaService services.PropuestaService) *PropuestaHandler {
+	return &PropuestaHandler{
+		propuestaService: propuestaService,
+	}
+}
+
+// horarioRequest es un horario candidato con el formato de fecha y hora de la API
+type horarioRequest struct {
+	Fecha string `json:"fecha"`
+	Hora  string `json:"hora"`
+}
+
+// parseHorarios convierte los horarios recibidos a la hora local del servidor
+func parseHorarios(horarios []horarioRequest) ([]time.Time, error) {
+	parsed := make([]time.Time, 0, len(horarios))
+	for _, h := range horarios {
+		t, err := time.ParseInLocation("2006-01-02 15:04", h.Fecha+" "+h.Hora, time.Local)
+		if err != nil {
+			return nil, errors.New("Formato de horario inválido (fecha YYYY-MM-DD, hora HH:MM)")
+		}
+		parsed = append(parsed, t)
+	}
+	return parsed, nil
+}
+
+// respondPropuestaError traduce los errores del servicio de propuestas a respuestas HTTP
+func respondPropuestaError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrPropuestaNotFound):
+		utils.NotFound(w, r, "Propuesta")
+	case errors.Is(err, services.ErrPartidoNotFound):
+		utils.NotFound(w, r, "Partido")
+	case errors.Is(err, services.ErrInvalidPropuesta):
+		utils.BadRequest(w, r, err.Error(), map[string]interface{}{
+			"max_opciones": services.MaxOpcionesPropuesta,
+		})
+	case errors.Is(err, services.ErrJugadorAjeno):
+		utils.Forbidden(w, r, err.Error())
+	case errors.Is(err, services.ErrPropuestaCerrada),
+		errors.Is(err, services.ErrPropuestaExpirada),
+		errors.Is(err, services.ErrPartidoNoAgendable),
+		errors.Is(err, services.ErrTransicionInvalida):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.InternalServerError(w, r, err)
+	}
+}
+
+// resolvePropuestaJugador determina el jugador que responde la propuesta; un
+// administrador debe indicar en nombre de qué jugador actúa
+func resolvePropuestaJugador(w http.ResponseWriter, r *http.Request, solicitado int) (int, bool) {
+	jugadorID, err := jugadorActuante(r, solicitado)
+	if err != nil {
+		utils.Forbidden(w, r, err.Error())
+		return 0, false
+	}
+	if jugadorID == 0 {
+		utils.BadRequest(w, r, "jugador_id es requerido", nil)
+		return 0, false
+	}
+	return jugadorID, true
+}
+
+func (h *PropuestaHandler) GetPropuestas(w http.ResponseWriter, r *http.Request) {
+	partidoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID de partido inválido", nil)
+		return
+	}
+
+	propuestas, err := h.propuestaService.GetPropuestas(partidoID)
+	if err != nil {
+		respondPropuestaError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "", propuestas)
+}
+
+func (h *PropuestaHandler) CreatePropuesta(w http.ResponseWriter, r *http.Request) {
+	partidoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID de partido inválido", nil)
+		return
+	}
+
+	var request struct {
+		JugadorID int              `json:"jugador_id"`
+		Horarios  []horarioRequest `json:"horarios"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.BadRequest(w, r, "Datos JSON inválidos", nil)
+		return
+	}
+
+	horarios, err := parseHorarios(request.Horarios)
+	if err != nil {
+		utils.BadRequest(w, r, err.Error(), nil)
+		return
+	}
+
+	jugadorID, ok := resolvePropuestaJugador(w, r, request.JugadorID)
+	if !ok {
+		return
+	}
+
+	propuesta, err := h.propuestaService.CreatePropuesta(partidoID, jugadorID, horarios)
+	if err != nil {
+		respondPropuestaError(w, r, err)
+		return
+	}
+
+	utils.Created(w, r, "Propuesta de horario enviada exitosamente", propuesta)
+}
+
+func (h *PropuestaHandler) CounterPropuesta(w http.ResponseWriter, r *http.Request) {
+	propuestaID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID de propuesta inválido", nil)
+		return
+	}
+
+	var request struct {
+		JugadorID int              `json:"jugador_id"`
+		Horarios  []horarioRequest `json:"horarios"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.BadRequest(w, r, "Datos JSON inválidos", nil)
+		return
+	}
+
+	horarios, err := parseHorarios(request.Horarios)
+	if err != nil {
+		utils.BadRequest(w, r, err.Error(), nil)
+		return
+	}
+
+	jugadorID, ok := resolvePropuestaJugador(w, r, request.JugadorID)
+	if !ok {
+		return
+	}
+
+	propuesta, err := h.propuestaService.CounterPropuesta(propuestaID, jugadorID, horarios)
+	if err != nil {
+		respondPropuestaError(w, r, err)
+		return
+	}
+
+	utils.Created(w, r, "Contrapropuesta enviada exitosamente", propuesta)
+}
+
+func (h *PropuestaHandler) AcceptPropuesta(w http.ResponseWriter, r *http.Request) {
+	propuestaID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID de propuesta inválido", nil)
+		return
+	}
+
+	var request struct {
+		JugadorID int `json:"jugador_id"`
+		OpcionID  int `json:"opcion_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.BadRequest(w, r, "Datos JSON inválidos", nil)
+		return
+	}
+
+	jugadorID, ok := resolvePropuestaJugador(w, r, request.JugadorID)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.propuestaService.AcceptPropuesta(propuestaID, jugadorID, request.OpcionID, actorID); err != nil {
+		respondPropuestaError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "Horario aceptado; el partido quedó agendado", nil)
+}
+
+func (h *PropuestaHandler) RejectPropuesta(w http.ResponseWriter, r *http.Request) {
+	propuestaID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID de propuesta inválido", nil)
+		return
+	}
+
+	var request struct {
+		JugadorID int    `json:"jugador_id"`
+		Motivo    string `json:"motivo"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.BadRequest(w, r, "Datos JSON inválidos", nil)
+		return
+	}
+
+	jugadorID, ok := resolvePropuestaJugador(w, r, request.JugadorID)
+	if !ok {
+		return
+	}
+
+	if err := h.propuestaService.RejectPropuesta(propuestaID, jugadorID, utils.SanitizeString(request.Motivo)); err != nil {
+		respondPropuestaError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "Propuesta rechazada", nil)
+}
+
+func (h *PropuestaHandler) GetPartidosEscalados(w http.ResponseWriter, r *http.Request) {
+	partidos, err := h.propuestaService.GetPartidosEscalados()
+	if err != nil {
+		utils.InternalServerError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "", partidos)
+}
+
+func (h *PropuestaHandler) AgendarPartido(w http.ResponseWriter, r *http.Request) {
+	partidoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.BadRequest(w, r, "ID de partido inválido", nil)
+		return
+	}
+
+	var request horarioRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.BadRequest(w, r, "Datos JSON inválidos", nil)
+		return
+	}
+
+	horarios, err := parseHorarios([]horarioRequest{request})
+	if err != nil {
+		utils.BadRequest(w, r, err.Error(), nil)
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.propuestaService.AgendarPartido(partidoID, horarios[0], actorID); err != nil {
+		respondPropuestaError(w, r, err)
+		return
+	}
+
+	utils.Success(w, r, "Partido agendado exitosamente", nil)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type ReclamoHandler struct {
+	reclamoService services.ReclamoService
+}
+
+func NewReclamoHandler(reclamoService services.ReclamoService) *ReclamoHandler {
+	return &ReclamoHandler{
+		reclamoService: reclamoService,
+	}
+}
+
+// respondReclamoError traduce los errores del servicio de reclamos a respuestas HTTP
+func respondReclamoError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrReclamoNotFound), errors.Is(err, services.ErrReclamoSinCoincidencia),
+		errors.Is(err, services.ErrJugadorNoEncontrado):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrCodigoReclamoInvalido):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrReclamoCerrado), errors.Is(err, services.ErrReclamoDuplicado),
+		errors.Is(err, services.ErrUsuarioConJugador), errors.Is(err, services.ErrJugadorYaVinculado):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func reclamoIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de reclamo inválido")
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateReclamo pide vincular la cuenta al jugador con el nombre y teléfono indicados
+func (h *ReclamoHandler) CreateReclamo(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Nombre   string `json:"nombre" validate:"required,max=255"`
+		Apellido string `json:"apellido" validate:"required,max=255"`
+		Telefono string `json:"telefono" validate:"required,max=50"`
+	}
+
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+	reclamo, err := h.reclamoService.CreateReclamo(usuarioID, request.Nombre, request.Apellido, request.Telefono)
+	if err != nil {
+		respondReclamoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, reclamo)
+}
+
+func (h *ReclamoHandler) GetMisReclamos(w http.ResponseWriter, r *http.Request) {
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+
+	reclamos, err := h.reclamoService.GetReclamosUsuario(usuarioID)
+	if err != nil {
+		respondReclamoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, reclamos)
+}
+
+// ConfirmReclamo aprueba el reclamo propio con el código enviado al teléfono del jugador
+func (h *ReclamoHandler) ConfirmReclamo(w http.ResponseWriter, r *http.Request) {
+	id, ok := reclamoIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Codigo string `json:"codigo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Codigo == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "codigo es requerido")
+		return
+	}
+
+	usuarioID, _ := middlewares.GetUserIDFromContext(r.Context())
+	reclamo, err := h.reclamoService.ConfirmReclamo(id, usuarioID, request.Codigo)
+	if err != nil {
+		respondReclamoError(w, r, err)
+		return
+	}
+
+	// El jugador vinculado llega en el próximo token de acceso (POST /auth/refresh)
+	utils.RespondWithJSON(w, http.StatusOK, reclamo)
+}
+
+func (h *ReclamoHandler) GetReclamosPendientes(w http.ResponseWriter, r *http.Request) {
+	reclamos, err := h.reclamoService.GetReclamosPendientes()
+	if err != nil {
+		respondReclamoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, reclamos)
+}
+
+func (h *ReclamoHandler) ApproveReclamo(w http.ResponseWriter, r *http.Request) {
+	id, ok := reclamoIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	reclamo, err := h.reclamoService.ApproveReclamo(id, actorID)
+	if err != nil {
+		respondReclamoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, reclamo)
+}
+
+func (h *ReclamoHandler) RejectReclamo(w http.ResponseWriter, r *http.Request) {
+	id, ok := reclamoIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Nota string `json:"nota"`
+	}
+	// La nota es opcional
+	json.NewDecoder(r.Body).Decode(&request)
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	reclamo, err := h.reclamoService.RejectReclamo(id, actorID, utils.SanitizeString(request.Nota))
+	if err != nil {
+		respondReclamoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, reclamo)
+}
+
+// IssueCodigo genera el código que el administrador envía al teléfono del jugador
+func (h *ReclamoHandler) IssueCodigo(w http.ResponseWriter, r *http.Request) {
+	id, ok := reclamoIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	codigo, err := h.reclamoService.IssueCodigo(id)
+	if err != nil {
+		respondReclamoError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, codigo)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type RolHandler struct {
+	rolService services.RolService
+}
+
+func NewRolHandler(rolService services.RolService) *RolHandler {
+	return &RolHandler{
+		rolService: rolService,
+	}
+}
+
+// respondRolError traduce los errores del servicio de roles a respuestas HTTP
+func respondRolError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrRolNotFound), errors.Is(err, services.ErrAsignacionNotFound),
+		errors.Is(err, services.ErrUsuarioNotFound), errors.Is(err, services.ErrAlcanceNoEncontrado):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPermisoInvalido):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrRolSistema), errors.Is(err, services.ErrRolDuplicado),
+		errors.Is(err, services.ErrAsignacionDuplicada):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *RolHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.rolService.GetRoles()
+	if err != nil {
+		respondRolError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, roles)
+}
+
+// GetPermisos devuelve el catálogo de permisos que se pueden asignar a un rol
+func (h *RolHandler) GetPermisos(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, models.Permisos)
+}
+
+func (h *RolHandler) CreateRol(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Nombre      string   `json:"nombre" validate:"required,min=3,max=50,lowercase,no_sql_injection,safe_string"`
+		Descripcion string   `json:"descripcion" validate:"max=255"`
+		Permisos    []string `json:"permisos"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	rol := models.Rol{
+		Nombre:      request.Nombre,
+		Descripcion: utils.SanitizeString(request.Descripcion),
+		Permisos:    request.Permisos,
+	}
+	if err := h.rolService.CreateRol(&rol); err != nil {
+		respondRolError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, rol)
+}
+
+func (h *RolHandler) UpdateRol(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de rol inválido")
+		return
+	}
+
+	var request struct {
+		Descripcion string   `json:"descripcion" validate:"max=255"`
+		Permisos    []string `json:"permisos"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	rol := models.Rol{Descripcion: utils.SanitizeString(request.Descripcion), Permisos: request.Permisos}
+	if err := h.rolService.UpdateRol(id, &rol); err != nil {
+		respondRolError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Rol actualizado exitosamente"})
+}
+
+func (h *RolHandler) DeleteRol(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de rol inválido")
+		return
+	}
+
+	if err := h.rolService.DeleteRol(id); err != nil {
+		respondRolError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Rol eliminado exitosamente"})
+}
+
+func (h *RolHandler) GetAsignaciones(w http.ResponseWriter, r *http.Request) {
+	usuarioID, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	asignaciones, err := h.rolService.GetAsignaciones(usuarioID)
+	if err != nil {
+		respondRolError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, asignaciones)
+}
+
+// AsignarRol otorga un rol al usuario; torneo_id y categoria_id limitan su alcance
+func (h *RolHandler) AsignarRol(w http.ResponseWriter, r *http.Request) {
+	usuarioID, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		RolID       int `json:"rol_id" validate:"required,gt=0"`
+		TorneoID    int `json:"torneo_id" validate:"gte=0"`
+		CategoriaID int `json:"categoria_id" validate:"gte=0"`
+	}
+	if err := utils.ParseAndValidateJSON(r, &request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	asignacion := models.AsignacionRol{UsuarioID: usuarioID, RolID: request.RolID}
+	asignacion.TorneoID.Int32, asignacion.TorneoID.Valid = int32(request.TorneoID), request.TorneoID > 0
+	asignacion.CategoriaID.Int32, asignacion.CategoriaID.Valid = int32(request.CategoriaID), request.CategoriaID > 0
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.rolService.AsignarRol(&asignacion, actorID); err != nil {
+		respondRolError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, asignacion)
+}
+
+func (h *RolHandler) RevocarRol(w http.ResponseWriter, r *http.Request) {
+	usuarioID, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	asignacionID, err := strconv.Atoi(mux.Vars(r)["asignacion_id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de asignación inválido")
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	if err := h.rolService.RevocarRol(usuarioID, asignacionID, actorID); err != nil {
+		respondRolError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Rol revocado exitosamente"})
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"copa-litoral-backend/models"
 	"copa-litoral-backend/services"
@@ -22,6 +25,21 @@ func NewTorneoHandler(torneoService services.TorneoService) *TorneoHandler {
 	}
 }
 
+// respondTorneoError traduce los errores del servicio de torneos a respuestas HTTP
+func respondTorneoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTorneoNotFound), errors.Is(err, services.ErrTorneoSinActual):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidFormato), errors.Is(err, services.ErrTorneoInscripcionCierre):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrTorneoTransicion), errors.Is(err, services.ErrTorneoActualExiste),
+		errors.Is(err, services.ErrTorneoSinPartidos), errors.Is(err, services.ErrTorneoPartidosPendientes):
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
 func (h *TorneoHandler) GetTorneos(w http.ResponseWriter, r *http.Request) {
 	torneos, err := h.torneoService.GetAllTorneos()
 	if err != nil {
@@ -42,7 +60,18 @@ func (h *TorneoHandler) GetTorneo(w http.ResponseWriter, r *http.Request) {
 
 	torneo, err := h.torneoService.GetTorneoByID(id)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		respondTorneoError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, torneo)
+}
+
+// GetTorneoActual devuelve la edición en juego o, entre ediciones, la última finalizada
+func (h *TorneoHandler) GetTorneoActual(w http.ResponseWriter, r *http.Request) {
+	torneo, err := h.torneoService.GetTorneoActual()
+	if err != nil {
+		respondTorneoError(w, err)
 		return
 	}
 
@@ -57,7 +86,7 @@ func (h *TorneoHandler) CreateTorneo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.torneoService.CreateTorneo(&torneo); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondTorneoError(w, err)
 		return
 	}
 
@@ -79,7 +108,7 @@ func (h *TorneoHandler) UpdateTorneo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.torneoService.UpdateTorneo(id, &torneo); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondTorneoError(w, err)
 		return
 	}
 
@@ -95,9 +124,51 @@ func (h *TorneoHandler) DeleteTorneo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.torneoService.DeleteTorneo(id); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondTorneoError(w, err)
 		return
 	}
 
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Torneo eliminado exitosamente"})
+}
+
+// ChangeEstado mueve el torneo por su ciclo de vida. Al abrir la inscripción se
+// puede indicar cuándo cierra.
+func (h *TorneoHandler) ChangeEstado(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var request struct {
+		Estado            models.EstadoTorneo `json:"estado"`
+		InscripcionCierre *time.Time          `json:"inscripcion_cierre"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos JSON inválidos")
+		return
+	}
+
+	var cierre sql.NullTime
+	if request.InscripcionCierre != nil {
+		if request.Estado != models.TorneoInscripcionAbierta {
+			utils.RespondWithError(w, http.StatusBadRequest, "inscripcion_cierre solo se indica al abrir la inscripción")
+			return
+		}
+		cierre = sql.NullTime{Time: *request.InscripcionCierre, Valid: true}
+	}
+
+	if err := h.torneoService.ChangeEstado(id, request.Estado, cierre); err != nil {
+		respondTorneoError(w, err)
+		return
+	}
+
+	torneo, err := h.torneoService.GetTorneoByID(id)
+	if err != nil {
+		respondTorneoError(w, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, torneo)
 } 
\ No newline at end of file
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type UsuarioHandler struct {
+	usuarioService services.UsuarioService
+	filters        *utils.FilterManager
+}
+
+func NewUsuarioHandler(usuarioService services.UsuarioService) *UsuarioHandler {
+	return &UsuarioHandler{
+		usuarioService: usuarioService,
+		filters:        utils.NewFilterManager(),
+	}
+}
+
+// respondUsuarioError traduce los errores del servicio de usuarios a respuestas HTTP
+func respondUsuarioError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrUsuarioNotFound), errors.Is(err, services.ErrJugadorNoEncontrado):
+		utils.RespondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrRolInvalido):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrAccionSobreSiMismo), errors.Is(err, services.ErrJugadorYaVinculado),
+		errors.Is(err, services.ErrMFANoHabilitado):
+		utils.Conflict(w, r, err.Error(), nil)
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func usuarioIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "ID de usuario inválido")
+		return 0, false
+	}
+	return id, true
+}
+
+// GetUsuarios lista los usuarios; acepta page, limit, sort, order, search y los
+// filtros avanzados de usuarios (ej: rol[eq]=administrador, activo[eq]=false)
+func (h *UsuarioHandler) GetUsuarios(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.filters.ParseAdvancedFilters(r.URL.Query(), "usuarios")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := utils.ParsePaginationParams(r)
+	usuarios, pagination, err := h.usuarioService.GetUsuarios(params, filter)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, utils.CreatePaginatedResponse(usuarios, pagination, ""))
+}
+
+func (h *UsuarioHandler) GetUsuario(w http.ResponseWriter, r *http.Request) {
+	id, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	usuario, err := h.usuarioService.GetUsuario(id)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, usuario)
+}
+
+func (h *UsuarioHandler) ChangeRol(w http.ResponseWriter, r *http.Request) {
+	id, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Rol string `json:"rol"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Datos JSON inválidos")
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	usuario, err := h.usuarioService.ChangeRol(id, request.Rol, actorID)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, usuario)
+}
+
+func (h *UsuarioHandler) LinkJugador(w http.ResponseWriter, r *http.Request) {
+	id, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		JugadorID int `json:"jugador_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.JugadorID <= 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "jugador_id es requerido")
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	usuario, err := h.usuarioService.SetJugador(id, request.JugadorID, actorID)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, usuario)
+}
+
+func (h *UsuarioHandler) UnlinkJugador(w http.ResponseWriter, r *http.Request) {
+	id, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	usuario, err := h.usuarioService.SetJugador(id, 0, actorID)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, usuario)
+}
+
+func (h *UsuarioHandler) SetActivo(w http.ResponseWriter, r *http.Request) {
+	id, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Activo *bool `json:"activo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Activo == nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "activo es requerido")
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	usuario, err := h.usuarioService.SetActivo(id, *request.Activo, actorID)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, usuario)
+}
+
+func (h *UsuarioHandler) GetAuditoria(w http.ResponseWriter, r *http.Request) {
+	id, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	registros, err := h.usuarioService.GetAuditoria(id)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, registros)
+}
+
+// Unlock levanta el bloqueo de login por intentos fallidos
+func (h *UsuarioHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	id, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	usuario, err := h.usuarioService.Unlock(id, actorID)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, usuario)
+}
+
+// ResetMFA quita la verificación en dos pasos del usuario para que vuelva a enrolarse
+func (h *UsuarioHandler) ResetMFA(w http.ResponseWriter, r *http.Request) {
+	id, ok := usuarioIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middlewares.GetUserIDFromContext(r.Context())
+	usuario, err := h.usuarioService.ResetMFA(id, actorID)
+	if err != nil {
+		respondUsuarioError(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, usuario)
+}
@@ -14,6 +14,7 @@ import (
 	"copa-litoral-backend/config"
 	"copa-litoral-backend/database"
 	"copa-litoral-backend/routes"
+	"copa-litoral-backend/services"
 	"copa-litoral-backend/utils"
 
 	_ "github.com/lib/pq"
@@ -78,8 +79,24 @@ func main() {
 		"max_idle_conns": cfg.DBMaxIdleConns,
 	})
 
+	// Cargar las claves de firma de los tokens
+	keys, err := utils.NewKeyRing(cfg)
+	if err != nil {
+		utils.LogError("Failed to load JWT keys", err, nil)
+		return
+	}
+	utils.LogInfo("JWT keys loaded", map[string]interface{}{
+		"active_kid": keys.ActiveKID(),
+	})
+
 	// Configurar rutas
-	router := routes.SetupRoutes(db, cfg)
+	router := routes.SetupRoutes(db, cfg, keys)
+
+	// Vencer propuestas de horario sin respuesta y escalarlas al administrador
+	services.SchedulePropuestaExpiry(
+		services.NewPropuestaService(time.Duration(cfg.PropuestaVigencia)*time.Hour),
+		time.Duration(cfg.PropuestaRevision)*time.Minute,
+	)
 
 	// Crear servidor HTTP
 	server := &http.Server{
@@ -3,24 +3,63 @@ package middlewares
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"strings"
 
-	"copa-litoral-backend/config"
+	"copa-litoral-backend/models"
 	"copa-litoral-backend/utils"
 )
 
 type contextKey string
 
 const (
-	UserIDKey contextKey = "user_id"
-	RolKey    contextKey = "rol"
+	UserIDKey    contextKey = "user_id"
+	JugadorIDKey contextKey = "jugador_id"
+	RolKey       contextKey = "rol"
+	SesionIDKey  contextKey = "sesion_id"
+	APIKeyKey    contextKey = "api_key"
 )
 
-func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
+// SessionChecker verifica que la sesión de un token de acceso no haya sido cerrada
+type SessionChecker interface {
+	IsSessionActive(usuarioID int, sesionID string) (bool, error)
+}
+
+var sessionChecker SessionChecker
+
+// SetSessionChecker establece cómo AuthMiddleware verifica las sesiones revocadas
+func SetSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// APIKeyAuthenticator valida las API keys; devuelve nil si la clave no es válida
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(clave string) (*models.APIKey, error)
+}
+
+var (
+	apiKeyAuthenticator APIKeyAuthenticator
+	apiKeyLimiter       *RateLimiter
+)
+
+// SetAPIKeyAuthenticator establece cómo AuthMiddleware valida el header X-API-Key
+// y el límite de solicitudes de cada clave, independiente del límite por IP
+func SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator, limiter *RateLimiter) {
+	apiKeyAuthenticator = authenticator
+	apiKeyLimiter = limiter
+}
+
+// AuthMiddleware autentica con el token de acceso del header Authorization o, si
+// no viene, con una API key en X-API-Key
+func AuthMiddleware(keys *utils.KeyRing) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Obtener el token del header Authorization
 		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" && r.Header.Get("X-API-Key") != "" {
+			autenticarAPIKey(w, r, next)
+			return
+		}
 		if authHeader == "" {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Token de autorización requerido")
 			return
@@ -36,15 +75,33 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 		tokenString := tokenParts[1]
 
 		// Parsear y validar el token JWT
-		claims, err := utils.ParseJWT(tokenString, cfg.JWTSecret)
-		if err != nil {
+		claims, err := utils.ParseJWT(tokenString, keys)
+		if err != nil || claims.SesionID == "" {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Token inválido")
 			return
 		}
 
+		// Un logout revoca la sesión aunque el token de acceso no haya vencido
+		if sessionChecker == nil {
+			utils.RespondWithError(w, http.StatusServiceUnavailable, "No se pudo verificar la sesión")
+			return
+		}
+		activa, err := sessionChecker.IsSessionActive(claims.UserID, claims.SesionID)
+		if err != nil {
+			utils.LogError("Failed to check session", err, map[string]interface{}{"user_id": claims.UserID})
+			utils.RespondWithError(w, http.StatusServiceUnavailable, "No se pudo verificar la sesión")
+			return
+		}
+		if !activa {
+			utils.RespondWithError(w, http.StatusUnauthorized, "La sesión fue cerrada")
+			return
+		}
+
 		// Agregar la información del usuario al contexto
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, JugadorIDKey, claims.JugadorID)
 		ctx = context.WithValue(ctx, RolKey, claims.Rol)
+		ctx = context.WithValue(ctx, SesionIDKey, claims.SesionID)
 
 		// Llamar al siguiente handler con el contexto actualizado
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -52,12 +109,70 @@ func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 	}
 }
 
+// autenticarAPIKey atiende la solicitud en nombre del creador de la clave; los
+// permisos quedan limitados a los de la clave (ver concesionesDe)
+func autenticarAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	if apiKeyAuthenticator == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "No se pudo verificar la API key")
+		return
+	}
+	key, err := apiKeyAuthenticator.AuthenticateAPIKey(r.Header.Get("X-API-Key"))
+	if err != nil {
+		utils.LogError("Failed to check API key", err, nil)
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "No se pudo verificar la API key")
+		return
+	}
+	if key == nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "API key inválida")
+		return
+	}
+	if apiKeyLimiter != nil && !apiKeyLimiter.Allow(strconv.Itoa(key.ID)) {
+		utils.RespondWithError(w, http.StatusTooManyRequests, "Demasiadas requests. Intenta más tarde.")
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, key.CreadaPor)
+	ctx = context.WithValue(ctx, APIKeyKey, key)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequireSession rechaza las API keys en las rutas que actúan sobre la cuenta
+// propia o administran credenciales
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetAPIKeyFromContext(r.Context()); ok {
+			utils.RespondWithError(w, http.StatusForbidden, "Esta ruta requiere iniciar sesión; no acepta API keys")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Helper functions para obtener datos del contexto
 func GetUserIDFromContext(ctx context.Context) (int, bool) {
 	userID, ok := ctx.Value(UserIDKey).(int)
 	return userID, ok
 }
 
+// GetJugadorIDFromContext devuelve el jugador vinculado al usuario autenticado;
+// ok es false si el usuario no tiene jugador
+func GetJugadorIDFromContext(ctx context.Context) (int, bool) {
+	jugadorID, ok := ctx.Value(JugadorIDKey).(int)
+	return jugadorID, ok && jugadorID > 0
+}
+
+// GetSesionIDFromContext devuelve la sesión del token de acceso autenticado
+func GetSesionIDFromContext(ctx context.Context) (string, bool) {
+	sesionID, ok := ctx.Value(SesionIDKey).(string)
+	return sesionID, ok
+}
+
+// GetAPIKeyFromContext devuelve la API key con la que se autenticó la solicitud
+func GetAPIKeyFromContext(ctx context.Context) (*models.APIKey, bool) {
+	key, ok := ctx.Value(APIKeyKey).(*models.APIKey)
+	return key, ok
+}
+
 func GetRolFromContext(ctx context.Context) (string, bool) {
 	rol, ok := ctx.Value(RolKey).(string)
 	return rol, ok
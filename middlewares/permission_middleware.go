@@ -0,0 +1,153 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// PermissionChecker obtiene los permisos efectivos de un usuario
+type PermissionChecker interface {
+	GetConcesiones(usuarioID int) (models.Concesiones, error)
+}
+
+var permissionChecker PermissionChecker
+
+// SetPermissionChecker establece de dónde obtienen los permisos RequirePermission y CheckPermission
+func SetPermissionChecker(checker PermissionChecker) {
+	permissionChecker = checker
+}
+
+// AlcanceResolver obtiene el torneo y la categoría del recurso de la solicitud
+type AlcanceResolver func(r *http.Request) (models.Alcance, error)
+
+// AlcanceTorneo toma el torneo de la variable de ruta indicada
+func AlcanceTorneo(variable string) AlcanceResolver {
+	return func(r *http.Request) (models.Alcance, error) {
+		id, _ := strconv.Atoi(mux.Vars(r)[variable])
+		return models.Alcance{TorneoID: id}, nil
+	}
+}
+
+// AlcanceCategoria toma la categoría de la variable de ruta indicada
+func AlcanceCategoria(variable string) AlcanceResolver {
+	return func(r *http.Request) (models.Alcance, error) {
+		id, _ := strconv.Atoi(mux.Vars(r)[variable])
+		return models.Alcance{CategoriaID: id}, nil
+	}
+}
+
+// AlcanceTorneoCategoria toma el torneo y la categoría de las variables torneo_id y categoria_id
+func AlcanceTorneoCategoria(r *http.Request) (models.Alcance, error) {
+	torneoID, _ := strconv.Atoi(mux.Vars(r)["torneo_id"])
+	categoriaID, _ := strconv.Atoi(mux.Vars(r)["categoria_id"])
+	return models.Alcance{TorneoID: torneoID, CategoriaID: categoriaID}, nil
+}
+
+// AlcancePorID busca el alcance del recurso cuyo ID está en la variable de ruta "id"
+func AlcancePorID(buscar func(id int) (models.Alcance, error)) AlcanceResolver {
+	return func(r *http.Request) (models.Alcance, error) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			return models.Alcance{}, nil
+		}
+		return buscar(id)
+	}
+}
+
+// RequirePermission exige el permiso sobre el alcance del recurso. Sin resolver
+// exige el permiso sin límite. El alcance solo se resuelve si el usuario tiene el
+// permiso limitado a algún torneo o categoría.
+func RequirePermission(permiso string, resolver AlcanceResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			concesiones, ok := concesionesDe(w, r)
+			if !ok {
+				return
+			}
+
+			if !concesiones.Permite(permiso, models.Alcance{}) {
+				if resolver == nil || !concesiones.TienePermiso(permiso) {
+					denegar(w)
+					return
+				}
+				alcance, err := resolver(r)
+				if err != nil {
+					utils.LogError("Failed to resolve permission scope", err, map[string]interface{}{"permiso": permiso})
+					utils.RespondWithError(w, http.StatusInternalServerError, "No se pudo verificar el permiso")
+					return
+				}
+				if !concesiones.Permite(permiso, alcance) {
+					denegar(w)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAnyPermission deja pasar a quien tenga el permiso en algún alcance. Es para
+// rutas cuyo alcance viene en el cuerpo: el handler debe completar la verificación
+// con CheckPermission.
+func RequireAnyPermission(permiso string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			concesiones, ok := concesionesDe(w, r)
+			if !ok {
+				return
+			}
+			if !concesiones.TienePermiso(permiso) {
+				denegar(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CheckPermission verifica el permiso sobre un alcance desde un handler; si no lo
+// tiene responde el error y devuelve false
+func CheckPermission(w http.ResponseWriter, r *http.Request, permiso string, alcance models.Alcance) bool {
+	concesiones, ok := concesionesDe(w, r)
+	if !ok {
+		return false
+	}
+	if !concesiones.Permite(permiso, alcance) {
+		denegar(w)
+		return false
+	}
+	return true
+}
+
+func concesionesDe(w http.ResponseWriter, r *http.Request) (models.Concesiones, bool) {
+	usuarioID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Información de usuario no disponible")
+		return nil, false
+	}
+	if permissionChecker == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "No se pudieron verificar los permisos")
+		return nil, false
+	}
+
+	concesiones, err := permissionChecker.GetConcesiones(usuarioID)
+	if err != nil {
+		utils.LogError("Failed to load permissions", err, map[string]interface{}{"user_id": usuarioID})
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "No se pudieron verificar los permisos")
+		return nil, false
+	}
+	if key, ok := GetAPIKeyFromContext(r.Context()); ok {
+		concesiones = concesiones.Limitar(key.Permisos)
+	}
+	return concesiones, true
+}
+
+func denegar(w http.ResponseWriter) {
+	utils.RespondWithError(w, http.StatusForbidden, "Acceso denegado: permisos insuficientes")
+}
@@ -72,8 +72,8 @@ func (rl *RateLimiter) Allow(ip string) bool {
 	return limiter.Allow()
 }
 
-// getClientIP extrae la IP real del cliente
-func getClientIP(r *http.Request) string {
+// GetClientIP extrae la IP real del cliente, considerando los headers de proxy
+func GetClientIP(r *http.Request) string {
 	// Verificar headers de proxy
 	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
 		// Tomar la primera IP de la lista
@@ -111,7 +111,7 @@ func parseForwardedFor(forwarded string) []string {
 func RateLimitMiddleware(rl *RateLimiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := getClientIP(r)
+			ip := GetClientIP(r)
 			
 			if !rl.Allow(ip) {
 				utils.RespondWithError(w, http.StatusTooManyRequests, "Demasiadas requests. Intenta más tarde.")
@@ -139,3 +139,11 @@ func StrictRateLimit() *RateLimiter {
 func AuthRateLimit() *RateLimiter {
 	return NewRateLimiter(rate.Every(time.Minute/10), 3)
 }
+
+// APIKeyRateLimit: porMinuto requests per minute for each API key (120 if not set)
+func APIKeyRateLimit(porMinuto int) *RateLimiter {
+	if porMinuto <= 0 {
+		porMinuto = 120
+	}
+	return NewRateLimiter(rate.Every(time.Minute/time.Duration(porMinuto)), 10)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// APIKey es una credencial para integraciones (tableros, bots) que se envía en el
+// header X-API-Key. Actúa con los permisos de quien la creó, limitados a Permisos.
+type APIKey struct {
+	ID          int          `json:"id"`
+	Nombre      string       `json:"nombre"`
+	Prefijo     string       `json:"prefijo"` // Comienzo de la clave, para reconocerla
+	Permisos    []string     `json:"permisos"`
+	CreadaPor   int          `json:"creada_por"`
+	ExpiraEn    sql.NullTime `json:"expira_en"`
+	UltimoUsoEn sql.NullTime `json:"ultimo_uso_en"`
+	RevocadaEn  sql.NullTime `json:"revocada_en"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
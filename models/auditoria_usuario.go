@@ -0,0 +1,34 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type AccionAuditoria string
+
+const (
+	AuditoriaCambioRol             AccionAuditoria = "cambio_rol"
+	AuditoriaVinculacionJugador    AccionAuditoria = "vinculacion_jugador"
+	AuditoriaDesvinculacionJugador AccionAuditoria = "desvinculacion_jugador"
+	AuditoriaDesactivacion         AccionAuditoria = "desactivacion"
+	AuditoriaActivacion            AccionAuditoria = "activacion"
+	AuditoriaReclamoJugador        AccionAuditoria = "reclamo_jugador" // Vinculación por un reclamo aprobado
+	AuditoriaDesbloqueo            AccionAuditoria = "desbloqueo"      // Levantamiento del bloqueo de login
+	AuditoriaAsignacionRol         AccionAuditoria = "asignacion_rol"  // Rol adicional otorgado, con su alcance
+	AuditoriaRevocacionRol         AccionAuditoria = "revocacion_rol"
+	AuditoriaMFAActivado           AccionAuditoria = "mfa_activado"
+	AuditoriaMFADesactivado        AccionAuditoria = "mfa_desactivado" // Por el usuario o reiniciado por un administrador
+)
+
+// AuditoriaUsuario registra un cambio que un administrador hizo sobre una cuenta
+type AuditoriaUsuario struct {
+	ID            int             `json:"id"`
+	UsuarioID     int             `json:"usuario_id"`
+	ActorID       sql.NullInt32   `json:"actor_id"`
+	ActorNombre   string          `json:"actor_nombre,omitempty"`
+	Accion        AccionAuditoria `json:"accion"`
+	ValorAnterior sql.NullString  `json:"valor_anterior"`
+	ValorNuevo    sql.NullString  `json:"valor_nuevo"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
@@ -5,6 +5,13 @@ import (
 	"time"
 )
 
+// Niveles de auspicio, de mayor a menor exposición
+const (
+	NivelOro    = "oro"
+	NivelPlata  = "plata"
+	NivelBronce = "bronce"
+)
+
 type Auspiciador struct {
 	ID          int            `json:"id"`
 	Nombre      string         `json:"nombre"`
@@ -13,6 +20,22 @@ type Auspiciador struct {
 	Descripcion sql.NullString `json:"descripcion"`
 	Activo      bool           `json:"activo"`
 	Orden       int            `json:"orden"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-} 
\ No newline at end of file
+	// Nivel es el mejor nivel entre los auspicios vigentes; solo en el listado público
+	Nivel     string     `json:"nivel,omitempty"`
+	Auspicios []Auspicio `json:"auspicios,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Auspicio es el patrocinio de un auspiciador, general o limitado a un torneo o a
+// una categoría, con un nivel y un período de vigencia opcional
+type Auspicio struct {
+	ID            int           `json:"id"`
+	AuspiciadorID int           `json:"auspiciador_id"`
+	TorneoID      sql.NullInt32 `json:"torneo_id"`
+	CategoriaID   sql.NullInt32 `json:"categoria_id"`
+	Nivel         string        `json:"nivel"`
+	VigenteDesde  sql.NullTime  `json:"vigente_desde"`
+	VigenteHasta  sql.NullTime  `json:"vigente_hasta"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
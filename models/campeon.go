@@ -5,12 +5,28 @@ import (
 	"time"
 )
 
+// Campeon es el ganador de una categoría en un año. Los que se derivan de la final
+// tienen torneo; los históricos, anteriores al sistema, pueden no tenerlo.
 type Campeon struct {
-	ID          int            `json:"id"`
-	TorneoID    int            `json:"torneo_id"`
-	CategoriaID int            `json:"categoria_id"`
-	JugadorID   sql.NullInt32  `json:"jugador_id"`
-	Anio        int            `json:"anio"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-} 
\ No newline at end of file
+	ID          int           `json:"id"`
+	TorneoID    sql.NullInt32 `json:"torneo_id"`
+	CategoriaID int           `json:"categoria_id"`
+	JugadorID   sql.NullInt32 `json:"jugador_id"`
+	// JugadorNombre es el nombre del jugador o, si no está registrado, el cargado a mano
+	JugadorNombre string    `json:"jugador_nombre"`
+	Anio          int       `json:"anio"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Campos adicionales para facilitar la respuesta JSON
+	TorneoNombre    string `json:"torneo_nombre,omitempty"`
+	CategoriaNombre string `json:"categoria_nombre,omitempty"`
+}
+
+// TitulosJugador resume los títulos de un campeón en el palmarés
+type TitulosJugador struct {
+	JugadorID     sql.NullInt32 `json:"jugador_id"`
+	JugadorNombre string        `json:"jugador_nombre"`
+	Titulos       int           `json:"titulos"`
+	Anios         []int         `json:"anios"`
+}
@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// FotoDestacada es una foto de la galería, de un torneo o de un partido. Las fotos
+// subidas guardan el original sin metadatos y sus miniaturas; las anteriores a la
+// galería solo tienen una URL externa.
 type FotoDestacada struct {
 	ID          int            `json:"id"`
 	Titulo      sql.NullString `json:"titulo"`
@@ -12,6 +15,22 @@ type FotoDestacada struct {
 	URL         string         `json:"url"`
 	PartidoID   sql.NullInt32  `json:"partido_id"`
 	TorneoID    sql.NullInt32  `json:"torneo_id"`
+	Clave       sql.NullString `json:"-"` // Ubicación del original en el almacenamiento
+	TipoMIME    string         `json:"tipo_mime"`
+	Ancho       int            `json:"ancho"`
+	Alto        int            `json:"alto"`
+	TamanoBytes int64          `json:"tamano_bytes"`
+	SubidaPor   sql.NullInt32  `json:"subida_por"`
+	Variantes   []FotoVariante `json:"variantes"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
-} 
\ No newline at end of file
+}
+
+// FotoVariante es una miniatura de la foto; solo se generan las más chicas que el original
+type FotoVariante struct {
+	Nombre string `json:"nombre"`
+	URL    string `json:"url"`
+	Clave  string `json:"-"`
+	Ancho  int    `json:"ancho"`
+	Alto   int    `json:"alto"`
+}
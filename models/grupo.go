@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Criterios de desempate para la tabla de posiciones de un grupo
+const (
+	DesempateEnfrentamientoDirecto = "enfrentamiento_directo"
+	DesempateDiferenciaSets        = "diferencia_sets"
+	DesempateDiferenciaGames       = "diferencia_games"
+)
+
+type Grupo struct {
+	ID                 int            `json:"id"`
+	TorneoID           int            `json:"torneo_id"`
+	CategoriaID        int            `json:"categoria_id"`
+	Nombre             string         `json:"nombre"`
+	CriteriosDesempate []string       `json:"criterios_desempate"`
+	Jugadores          []GrupoJugador `json:"jugadores,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+type GrupoJugador struct {
+	GrupoID       int    `json:"grupo_id"`
+	JugadorID     int    `json:"jugador_id"`
+	JugadorNombre string `json:"jugador_nombre,omitempty"`
+}
+
+// PosicionGrupo es una fila de la tabla de posiciones de un grupo
+type PosicionGrupo struct {
+	Posicion      int    `json:"posicion"`
+	JugadorID     int    `json:"jugador_id"`
+	JugadorNombre string `json:"jugador_nombre,omitempty"`
+	Jugados       int    `json:"jugados"`
+	Ganados       int    `json:"ganados"`
+	Perdidos      int    `json:"perdidos"`
+	SetsFavor     int    `json:"sets_favor"`
+	SetsContra    int    `json:"sets_contra"`
+	GamesFavor    int    `json:"games_favor"`
+	GamesContra   int    `json:"games_contra"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "database/sql"
+
+// EstadoMFA resume el segundo factor de una cuenta
+type EstadoMFA struct {
+	Habilitado       bool         `json:"habilitado"`
+	HabilitadoEn     sql.NullTime `json:"habilitado_en"`
+	Requerido        bool         `json:"requerido"` // La configuración lo exige para el rol de la cuenta
+	CodigosRestantes int          `json:"codigos_restantes"`
+}
+
+// EnrolamientoMFA es el secreto TOTP recién generado; queda pendiente hasta que el
+// usuario confirme un código de su aplicación autenticadora
+type EnrolamientoMFA struct {
+	Secreto string `json:"secreto"`
+	URI     string `json:"uri"` // otpauth:// para mostrar como QR
+}
@@ -5,14 +5,27 @@ import (
 	"time"
 )
 
+// Estados de una noticia. Una noticia publicada con fecha de publicación futura
+// queda programada: no se muestra hasta esa fecha.
+const (
+	NoticiaBorrador  = "borrador"
+	NoticiaPublicada = "publicada"
+	NoticiaArchivada = "archivada"
+)
+
 type Noticia struct {
-	ID                  int            `json:"id"`
-	Titulo              string         `json:"titulo"`
-	Slug                string         `json:"slug"`
-	Contenido           string         `json:"contenido"`
-	FechaPublicacion    time.Time      `json:"fecha_publicacion"`
-	AutorID             sql.NullInt32  `json:"autor_id"`
-	ImagenDestacadaURL  sql.NullString `json:"imagen_destacada_url"`
-	CreatedAt           time.Time      `json:"created_at"`
-	UpdatedAt           time.Time      `json:"updated_at"`
-} 
\ No newline at end of file
+	ID                 int            `json:"id"`
+	Titulo             string         `json:"titulo"`
+	Slug               string         `json:"slug"`
+	Contenido          string         `json:"contenido"`
+	Estado             string         `json:"estado"`
+	FechaPublicacion   sql.NullTime   `json:"fecha_publicacion"`
+	AutorID            sql.NullInt32  `json:"autor_id"`
+	ImagenDestacadaURL sql.NullString `json:"imagen_destacada_url"`
+	// Torneos, partidos y jugadores de los que trata la noticia
+	TorneoIDs  []int     `json:"torneo_ids"`
+	PartidoIDs []int     `json:"partido_ids"`
+	JugadorIDs []int     `json:"jugador_ids"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -8,12 +8,36 @@ import (
 type EstadoPartido string
 
 const (
+	EstadoPorDefinir  EstadoPartido = "por_definir" // Esperando rivales de la ronda anterior
+	EstadoPendiente   EstadoPartido = "pendiente"   // Rivales definidos, sin fecha agendada
 	EstadoAgendado    EstadoPartido = "agendado"
 	EstadoEnJuego     EstadoPartido = "en_juego"
-	EstadoFinalizado  EstadoPartido = "finalizado"
+	EstadoReportado   EstadoPartido = "reportado"   // Resultado cargado, pendiente de aprobación
+	EstadoEnDisputa   EstadoPartido = "en_disputa"  // Los jugadores reportaron resultados distintos
+	EstadoFinalizado  EstadoPartido = "finalizado"  // Resultado aprobado
+	EstadoWalkover    EstadoPartido = "walkover"
 	EstadoCancelado   EstadoPartido = "cancelado"
 )
 
+// TipoResultado indica cómo se definió el ganador de un partido
+type TipoResultado string
+
+const (
+	TipoResultadoNormal          TipoResultado = "normal"
+	TipoResultadoWalkover        TipoResultado = "walkover"        // El rival no se presentó
+	TipoResultadoAbandono        TipoResultado = "abandono"        // El rival se retiró durante el partido
+	TipoResultadoDescalificacion TipoResultado = "descalificacion" // El rival fue descalificado
+)
+
+// Fases de una llave de eliminación directa
+const (
+	FaseFinal     = "Final"
+	FaseSemifinal = "Semifinal"
+	FaseCuartos   = "Cuartos"
+	FaseOctavos   = "Octavos"
+	FaseGrupos    = "Fase de Grupos"
+)
+
 type Partido struct {
 	ID                  int            `json:"id"`
 	TorneoID            int            `json:"torneo_id"`
@@ -23,18 +47,21 @@ type Partido struct {
 	Fase                string         `json:"fase"`
 	FechaAgendada       sql.NullTime   `json:"fecha_agendada"`
 	HoraAgendada        sql.NullTime   `json:"hora_agendada"`
-	PropuestaFechaJ1    sql.NullTime   `json:"propuesta_fecha_j1"`
-	PropuestaHoraJ1     sql.NullTime   `json:"propuesta_hora_j1"`
-	PropuestaFechaJ2    sql.NullTime   `json:"propuesta_fecha_j2"`
-	PropuestaHoraJ2     sql.NullTime   `json:"propuesta_hora_j2"`
-	PropuestaAceptadaJ1 bool           `json:"propuesta_aceptada_j1"`
-	PropuestaAceptadaJ2 bool           `json:"propuesta_aceptada_j2"`
+	AgendaEscalada      bool           `json:"agenda_escalada"` // Los jugadores no acordaron horario a tiempo
 	Estado              EstadoPartido  `json:"estado"`
 	ResultadoSetsJ1     sql.NullInt32  `json:"resultado_sets_j1"`
 	ResultadoSetsJ2     sql.NullInt32  `json:"resultado_sets_j2"`
 	GanadorID           sql.NullInt32  `json:"ganador_id"`
 	PerdedorID          sql.NullInt32  `json:"perdedor_id"`
 	ResultadoAprobado   bool           `json:"resultado_aprobado"`
+	TipoResultado       TipoResultado  `json:"tipo_resultado"`
+	MotivoResultado     sql.NullString `json:"motivo_resultado"`
+	Ronda               sql.NullInt32  `json:"ronda"`
+	PosicionLlave       sql.NullInt32  `json:"posicion_llave"`
+	SiguientePartidoID  sql.NullInt32  `json:"siguiente_partido_id"`
+	SiguienteSlot       sql.NullInt32  `json:"siguiente_slot"`
+	GrupoID             sql.NullInt32  `json:"grupo_id"`
+	Jornada             sql.NullInt32  `json:"jornada"`
 	CreatedAt           time.Time      `json:"created_at"`
 	UpdatedAt           time.Time      `json:"updated_at"`
 	
@@ -42,4 +69,9 @@ type Partido struct {
 	Jugador1Nombre      string         `json:"jugador1_nombre,omitempty"`
 	Jugador2Nombre      string         `json:"jugador2_nombre,omitempty"`
 	CategoriaNombre     string         `json:"categoria_nombre,omitempty"`
+}
+
+// TieneJugador indica si el jugador es uno de los dos rivales del partido
+func (p *Partido) TieneJugador(jugadorID int) bool {
+	return jugadorID != 0 && (p.Jugador1ID == jugadorID || p.Jugador2ID == jugadorID)
 } 
\ No newline at end of file
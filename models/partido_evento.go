@@ -0,0 +1,18 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PartidoEvento registra un cambio de estado de un partido
+type PartidoEvento struct {
+	ID             int            `json:"id"`
+	PartidoID      int            `json:"partido_id"`
+	EstadoAnterior EstadoPartido  `json:"estado_anterior"`
+	EstadoNuevo    EstadoPartido  `json:"estado_nuevo"`
+	UsuarioID      sql.NullInt32  `json:"usuario_id"` // Nulo si el cambio lo hizo el sistema
+	UsuarioNombre  string         `json:"usuario_nombre,omitempty"`
+	Detalle        sql.NullString `json:"detalle"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
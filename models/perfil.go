@@ -0,0 +1,7 @@
+package models
+
+// Perfil es la cuenta del usuario autenticado junto al jugador vinculado, si tiene
+type Perfil struct {
+	Usuario Usuario  `json:"usuario"`
+	Jugador *Jugador `json:"jugador"`
+}
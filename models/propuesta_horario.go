@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EstadoPropuesta string
+
+const (
+	PropuestaPendiente       EstadoPropuesta = "pendiente"
+	PropuestaAceptada        EstadoPropuesta = "aceptada"
+	PropuestaRechazada       EstadoPropuesta = "rechazada"
+	PropuestaContrapropuesta EstadoPropuesta = "contrapropuesta" // El rival respondió con otros horarios
+	PropuestaReemplazada     EstadoPropuesta = "reemplazada"     // El mismo jugador envió una nueva propuesta
+	PropuestaExpirada        EstadoPropuesta = "expirada"
+	PropuestaCancelada       EstadoPropuesta = "cancelada" // El administrador agendó el partido
+)
+
+// PropuestaHorario es un conjunto de horarios que un jugador ofrece a su rival
+type PropuestaHorario struct {
+	ID                 int             `json:"id"`
+	PartidoID          int             `json:"partido_id"`
+	PropuestoPor       int             `json:"propuesto_por"`
+	PropuestoPorNombre string          `json:"propuesto_por_nombre,omitempty"`
+	Estado             EstadoPropuesta `json:"estado"`
+	RespondidaPor      sql.NullInt32   `json:"respondida_por"`
+	OpcionAceptadaID   sql.NullInt32   `json:"opcion_aceptada_id"`
+	Motivo             sql.NullString  `json:"motivo"`
+	ExpiraEn           time.Time       `json:"expira_en"`
+	Opciones           []OpcionHorario `json:"opciones"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// OpcionHorario es uno de los horarios candidatos de una propuesta
+type OpcionHorario struct {
+	ID          int       `json:"id"`
+	PropuestaID int       `json:"propuesta_id"`
+	FechaHora   time.Time `json:"fecha_hora"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EstadoReclamo string
+
+const (
+	ReclamoPendiente EstadoReclamo = "pendiente"
+	ReclamoAprobado  EstadoReclamo = "aprobado"
+	ReclamoRechazado EstadoReclamo = "rechazado"
+)
+
+// ReclamoJugador es el pedido de un usuario para vincularse a un jugador que el
+// administrador cargó antes de que el jugador tuviera cuenta
+type ReclamoJugador struct {
+	ID             int            `json:"id"`
+	UsuarioID      int            `json:"usuario_id"`
+	NombreUsuario  string         `json:"nombre_usuario,omitempty"`
+	JugadorID      int            `json:"jugador_id"`
+	JugadorNombre  string         `json:"jugador_nombre,omitempty"`
+	Estado         EstadoReclamo  `json:"estado"`
+	CodigoExpiraEn sql.NullTime   `json:"codigo_expira_en"` // Nulo si no hay un código vigente
+	ResueltoPor    sql.NullInt32  `json:"resuelto_por"`
+	Nota           sql.NullString `json:"nota"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EstadoReporte string
+
+const (
+	ReportePendiente   EstadoReporte = "pendiente"   // Esperando el reporte del rival
+	ReporteConfirmado  EstadoReporte = "confirmado"  // Coincide con el reporte del rival
+	ReporteEnDisputa   EstadoReporte = "en_disputa"  // No coincide con el reporte del rival
+	ReporteReemplazado EstadoReporte = "reemplazado" // El jugador volvió a reportar o lo cargó el administrador
+	ReporteResuelto    EstadoReporte = "resuelto"    // El administrador resolvió la disputa
+)
+
+// ReporteResultado es la versión del resultado que informa uno de los jugadores
+type ReporteResultado struct {
+	ID            int           `json:"id"`
+	PartidoID     int           `json:"partido_id"`
+	JugadorID     int           `json:"jugador_id"`
+	JugadorNombre string        `json:"jugador_nombre,omitempty"`
+	SetsGanadosJ1 int           `json:"sets_ganados_j1"`
+	SetsGanadosJ2 int           `json:"sets_ganados_j2"`
+	GanadorID     int           `json:"ganador_id"`
+	Estado        EstadoReporte `json:"estado"`
+	Sets          []SetPartido  `json:"sets"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+type EstadoDisputa string
+
+const (
+	DisputaAbierta  EstadoDisputa = "abierta"
+	DisputaResuelta EstadoDisputa = "resuelta"
+)
+
+// DisputaResultado se abre cuando los reportes de los jugadores no coinciden y
+// la cierra un administrador fijando el resultado final
+type DisputaResultado struct {
+	ID          int            `json:"id"`
+	PartidoID   int            `json:"partido_id"`
+	Estado      EstadoDisputa  `json:"estado"`
+	ResueltaPor sql.NullInt32  `json:"resuelta_por"`
+	Nota        sql.NullString `json:"nota"`
+	ResueltaEn  sql.NullTime   `json:"resuelta_en"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
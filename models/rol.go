@@ -0,0 +1,125 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Permisos que se asignan a los roles. El catálogo vive en el código; la base de
+// datos solo guarda qué permisos tiene cada rol.
+const (
+	PermisoTorneosEdit       = "torneos:edit"
+	PermisoCategoriasEdit    = "categorias:edit"
+	PermisoJugadoresEdit     = "jugadores:edit"
+	PermisoLlavesEdit        = "llaves:edit"       // Llaves, grupos y fixture
+	PermisoPartidosEdit      = "partidos:edit"     // Alta, edición, baja y estado de partidos
+	PermisoPartidosSchedule  = "partidos:schedule" // Agendar partidos escalados
+	PermisoPartidosApprove   = "partidos:approve"  // Aprobar resultados, resultados especiales y disputas
+	PermisoUsuariosManage    = "usuarios:manage"   // Cuentas y reclamos de jugador
+	PermisoRolesManage       = "roles:manage"
+	PermisoNoticiasPublish   = "noticias:publish"
+	PermisoAPIKeysManage     = "api_keys:manage"
+	PermisoAuspiciadoresEdit = "auspiciadores:edit"
+	PermisoFotosUpload       = "fotos:upload"
+)
+
+// Permisos es el catálogo completo, en el orden en que se documenta
+var Permisos = []string{
+	PermisoTorneosEdit, PermisoCategoriasEdit, PermisoJugadoresEdit, PermisoLlavesEdit,
+	PermisoPartidosEdit, PermisoPartidosSchedule, PermisoPartidosApprove,
+	PermisoUsuariosManage, PermisoRolesManage, PermisoNoticiasPublish, PermisoAPIKeysManage,
+	PermisoAuspiciadoresEdit, PermisoFotosUpload,
+}
+
+// EsPermiso indica si el permiso pertenece al catálogo
+func EsPermiso(permiso string) bool {
+	for _, p := range Permisos {
+		if p == permiso {
+			return true
+		}
+	}
+	return false
+}
+
+// Rol agrupa permisos. Los roles de sistema (administrador y jugador) son el rol
+// base de cada cuenta y no se editan; los demás se asignan a usuarios, con o sin alcance.
+type Rol struct {
+	ID          int       `json:"id"`
+	Nombre      string    `json:"nombre" validate:"required,min=3,max=50"`
+	Descripcion string    `json:"descripcion" validate:"max=255"`
+	Sistema     bool      `json:"sistema"`
+	Permisos    []string  `json:"permisos"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AsignacionRol es un rol otorgado a un usuario. Sin torneo ni categoría rige en
+// todo el sistema; con alguno de ellos, solo sobre los recursos de ese alcance.
+type AsignacionRol struct {
+	ID          int           `json:"id"`
+	UsuarioID   int           `json:"usuario_id"`
+	RolID       int           `json:"rol_id"`
+	RolNombre   string        `json:"rol_nombre,omitempty"`
+	TorneoID    sql.NullInt32 `json:"torneo_id"`
+	CategoriaID sql.NullInt32 `json:"categoria_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// Alcance es el torneo y la categoría a los que pertenece un recurso; 0 si no aplica
+type Alcance struct {
+	TorneoID    int
+	CategoriaID int
+}
+
+// Concesion es un permiso efectivo de un usuario; TorneoID y CategoriaID en 0
+// significan que no está limitado
+type Concesion struct {
+	Permiso     string
+	TorneoID    int
+	CategoriaID int
+}
+
+// Concesiones son todos los permisos efectivos de un usuario
+type Concesiones []Concesion
+
+// Permite indica si alguna concesión cubre el permiso sobre el alcance. Un recurso
+// sin torneo ni categoría solo lo cubre una concesión sin límite.
+func (c Concesiones) Permite(permiso string, alcance Alcance) bool {
+	for _, concesion := range c {
+		if concesion.Permiso != permiso {
+			continue
+		}
+		if concesion.TorneoID != 0 && concesion.TorneoID != alcance.TorneoID {
+			continue
+		}
+		if concesion.CategoriaID != 0 && concesion.CategoriaID != alcance.CategoriaID {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Limitar devuelve solo las concesiones de los permisos indicados
+func (c Concesiones) Limitar(permisos []string) Concesiones {
+	limitadas := Concesiones{}
+	for _, concesion := range c {
+		for _, permiso := range permisos {
+			if concesion.Permiso == permiso {
+				limitadas = append(limitadas, concesion)
+				break
+			}
+		}
+	}
+	return limitadas
+}
+
+// TienePermiso indica si el usuario tiene el permiso en algún alcance
+func (c Concesiones) TienePermiso(permiso string) bool {
+	for _, concesion := range c {
+		if concesion.Permiso == permiso {
+			return true
+		}
+	}
+	return false
+}
@@ -5,15 +5,37 @@ import (
 	"time"
 )
 
+type EstadoTorneo string
+
+// Estados de un torneo. Entre la apertura de la inscripción y el final hay un solo
+// torneo a la vez: el torneo actual.
+const (
+	TorneoPlanificado        EstadoTorneo = "planificado"
+	TorneoInscripcionAbierta EstadoTorneo = "inscripcion_abierta"
+	TorneoInscripcionCerrada EstadoTorneo = "inscripcion_cerrada"
+	TorneoEnCurso            EstadoTorneo = "en_curso"
+	TorneoFinalizado         EstadoTorneo = "finalizado"
+	TorneoArchivado          EstadoTorneo = "archivado"
+)
+
+// EnJuego indica si el estado es uno de los del torneo actual, de la apertura de la
+// inscripción al final
+func (e EstadoTorneo) EnJuego() bool {
+	return e == TorneoInscripcionAbierta || e == TorneoInscripcionCerrada || e == TorneoEnCurso
+}
+
 type Torneo struct {
-	ID              int            `json:"id"`
-	Nombre          string         `json:"nombre"`
-	Anio            int            `json:"anio"`
-	FechaInicio     sql.NullTime   `json:"fecha_inicio"`
-	FechaFin        sql.NullTime   `json:"fecha_fin"`
-	FotoURL         string         `json:"foto_url"`
-	FraseDestacada  string         `json:"frase_destacada"`
-	Activo          bool           `json:"activo"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-} 
\ No newline at end of file
+	ID                int          `json:"id"`
+	Nombre            string       `json:"nombre"`
+	Anio              int          `json:"anio"`
+	FechaInicio       sql.NullTime `json:"fecha_inicio"`
+	FechaFin          sql.NullTime `json:"fecha_fin"`
+	FotoURL           string       `json:"foto_url"`
+	FraseDestacada    string       `json:"frase_destacada"`
+	Estado            EstadoTorneo `json:"estado"`
+	InscripcionCierre sql.NullTime `json:"inscripcion_cierre"`
+	Activo            bool         `json:"activo"` // Derivado del estado; se mantiene por compatibilidad
+	FormatoPartido    string       `json:"formato_partido"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
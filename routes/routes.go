@@ -4,18 +4,21 @@ import (
 	"database/sql"
 	"net/http"
 	"strings"
+	"time"
 
 	"copa-litoral-backend/services"
 
 	"github.com/gorilla/mux"
 
 	"copa-litoral-backend/config"
+	"copa-litoral-backend/docs"
 	"copa-litoral-backend/handlers"
 	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/models"
 	"copa-litoral-backend/utils"
 )
 
-func SetupRoutes(db *sql.DB, cfg *config.Config) *mux.Router {
+func SetupRoutes(db *sql.DB, cfg *config.Config, keys *utils.KeyRing) *mux.Router {
 	r := mux.NewRouter()
 
 	// Middlewares globales básicos
@@ -25,26 +28,221 @@ func SetupRoutes(db *sql.DB, cfg *config.Config) *mux.Router {
 	r.Use(utils.MetricsMiddleware())
 	r.Use(middlewares.RateLimitMiddleware(middlewares.NewRateLimiter(100, 1)))
 
-	// Health check
+	// Health checks y métricas
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		utils.RespondWithJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+		utils.Success(w, r, "", map[string]string{"status": "healthy"})
 	}).Methods("GET")
+	r.HandleFunc("/health/ready", utils.ReadinessHandler).Methods("GET")
+	r.HandleFunc("/health/live", utils.LivenessHandler).Methods("GET")
+	r.Handle("/metrics", utils.GetMetricsHandler()).Methods("GET")
+
+	// Claves públicas para verificar los tokens de acceso
+	r.HandleFunc("/.well-known/jwks.json", utils.JWKSHandler(keys)).Methods("GET")
 
 	// Inicializar servicios y handlers
-	authService := services.NewAuthService(cfg)
+	loginThrottler := services.NewLoginThrottler(cfg)
+	authService := services.NewAuthService(cfg, keys, services.NewMailer(cfg), loginThrottler)
 	authHandler := handlers.NewAuthHandler(authService, cfg)
+	middlewares.SetSessionChecker(authService)
+	torneoService := services.NewTorneoService()
+	torneoHandler := handlers.NewTorneoHandler(torneoService)
+	categoriaService := services.NewCategoriaService()
+	categoriaHandler := handlers.NewCategoriaHandler(categoriaService)
+	jugadorService := services.NewJugadorService()
+	jugadorHandler := handlers.NewJugadorHandler(jugadorService)
+	bracketService := services.NewBracketService()
+	bracketHandler := handlers.NewBracketHandler(bracketService)
+	grupoService := services.NewGrupoService()
+	grupoHandler := handlers.NewGrupoHandler(grupoService)
+	partidoService := services.NewPartidoService()
+	partidoHandler := handlers.NewPartidoHandler(partidoService)
+	propuestaService := services.NewPropuestaService(time.Duration(cfg.PropuestaVigencia) * time.Hour)
+	propuestaHandler := handlers.NewPropuestaHandler(propuestaService)
+	usuarioService := services.NewUsuarioService(loginThrottler)
+	usuarioHandler := handlers.NewUsuarioHandler(usuarioService)
+	reclamoService := services.NewReclamoService()
+	reclamoHandler := handlers.NewReclamoHandler(reclamoService)
+	rolService := services.NewRolService()
+	rolHandler := handlers.NewRolHandler(rolService)
+	middlewares.SetPermissionChecker(rolService)
+	perfilService := services.NewPerfilService(authService, jugadorService)
+	perfilHandler := handlers.NewPerfilHandler(perfilService)
+	noticiaService := services.NewNoticiaService()
+	noticiaHandler := handlers.NewNoticiaHandler(noticiaService)
+	auspiciadorService := services.NewAuspiciadorService()
+	auspiciadorHandler := handlers.NewAuspiciadorHandler(auspiciadorService)
+	campeonService := services.NewCampeonService()
+	campeonHandler := handlers.NewCampeonHandler(campeonService)
+	storage := services.NewStorage(cfg)
+	fotoService := services.NewFotoService(storage)
+	fotoHandler := handlers.NewFotoHandler(fotoService, cfg.UploadMaxMB)
+	apiKeyService := services.NewAPIKeyService(rolService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	middlewares.SetAPIKeyAuthenticator(apiKeyService, middlewares.APIKeyRateLimit(cfg.APIKeyRateLimit))
+
+	// Fotos subidas, cuando se guardan en el disco de la API
+	if local, ok := storage.(*services.LocalStorage); ok && strings.HasPrefix(local.URLBase(), "/") {
+		r.PathPrefix(local.URLBase()+"/").Handler(local.Handler()).Methods("GET", "HEAD")
+	}
+
+	// Documentación de la API
+	docs.RegisterSwaggerRoutes(r.PathPrefix("/api/v1").Subrouter())
 
-	// Rutas públicas básicas
+	// Rutas públicas de autenticación, con un límite por IP más estricto
+	auth := r.PathPrefix("/api/v1/auth").Subrouter()
+	auth.Use(middlewares.RateLimitMiddleware(middlewares.AuthRateLimit()))
+	auth.HandleFunc("/login", authHandler.Login).Methods("POST")
+	auth.HandleFunc("/register", authHandler.Register).Methods("POST")
+	auth.HandleFunc("/refresh", authHandler.Refresh).Methods("POST")
+	auth.HandleFunc("/forgot-password", authHandler.ForgotPassword).Methods("POST")
+	auth.HandleFunc("/reset-password", authHandler.ResetPassword).Methods("POST")
+	auth.HandleFunc("/verify-email", authHandler.VerifyEmail).Methods("POST")
+	auth.HandleFunc("/mfa/verify", authHandler.VerifyMFA).Methods("POST")
+	auth.HandleFunc("/mfa/enroll", authHandler.EnrollMFAPending).Methods("POST")
+	auth.HandleFunc("/mfa/activate", authHandler.ActivateMFAPending).Methods("POST")
+
+	// Rutas públicas de consulta
 	public := r.PathPrefix("/api/v1").Subrouter()
-	public.HandleFunc("/auth/login", authHandler.Login).Methods("POST")
-	public.HandleFunc("/auth/register", authHandler.Register).Methods("POST")
-
-	// Rutas protegidas básicas
-	protected := r.PathPrefix("/api/v1/protected").Subrouter()
-	protected.Use(middlewares.AuthMiddleware(cfg))
-	protected.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
-		utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Profile endpoint"})
-	}).Methods("GET")
+	public.HandleFunc("/torneos", torneoHandler.GetTorneos).Methods("GET")
+	public.HandleFunc("/torneos/actual", torneoHandler.GetTorneoActual).Methods("GET")
+	public.HandleFunc("/torneos/{id:[0-9]+}", torneoHandler.GetTorneo).Methods("GET")
+	public.HandleFunc("/torneos/{torneo_id:[0-9]+}/categorias/{categoria_id:[0-9]+}/llave", bracketHandler.GetBracket).Methods("GET")
+	public.HandleFunc("/torneos/{torneo_id:[0-9]+}/categorias/{categoria_id:[0-9]+}/grupos", grupoHandler.GetGrupos).Methods("GET")
+	public.HandleFunc("/categorias", categoriaHandler.GetCategorias).Methods("GET")
+	public.HandleFunc("/categorias/{id:[0-9]+}", categoriaHandler.GetCategoria).Methods("GET")
+	public.HandleFunc("/jugadores", jugadorHandler.GetJugadores).Methods("GET")
+	public.HandleFunc("/jugadores/{id:[0-9]+}", jugadorHandler.GetJugador).Methods("GET")
+	public.HandleFunc("/grupos/{id:[0-9]+}", grupoHandler.GetGrupo).Methods("GET")
+	public.HandleFunc("/grupos/{id:[0-9]+}/posiciones", grupoHandler.GetStandings).Methods("GET")
+	public.HandleFunc("/partidos", partidoHandler.GetPartidos).Methods("GET")
+	public.HandleFunc("/partidos/{id:[0-9]+}", partidoHandler.GetPartido).Methods("GET")
+	public.HandleFunc("/partidos/{id:[0-9]+}/historial", partidoHandler.GetHistorial).Methods("GET")
+	public.HandleFunc("/noticias", noticiaHandler.GetNoticiasPublicadas).Methods("GET")
+	public.HandleFunc("/noticias/{slug:[a-z0-9-]+}", noticiaHandler.GetNoticiaBySlug).Methods("GET")
+	public.HandleFunc("/auspiciadores", auspiciadorHandler.GetAuspiciadoresVigentes).Methods("GET")
+	public.HandleFunc("/campeones", campeonHandler.GetCampeones).Methods("GET")
+	public.HandleFunc("/campeones/titulos", campeonHandler.GetTitulos).Methods("GET")
+	public.HandleFunc("/fotos", fotoHandler.GetFotos).Methods("GET")
+	public.HandleFunc("/fotos/{id:[0-9]+}", fotoHandler.GetFoto).Methods("GET")
+
+	// Las rutas que verifican una contraseña o un código de MFA se limitan como el login
+	credencialesLimiter := middlewares.AuthRateLimit()
+	limiteCredenciales := func(h http.HandlerFunc) http.Handler {
+		return middlewares.RateLimitMiddleware(credencialesLimiter)(h)
+	}
+
+	// Rutas de jugadores autenticados; actúan sobre la cuenta propia y no aceptan API keys
+	jugador := r.PathPrefix("/api/v1").Subrouter()
+	jugador.Use(middlewares.AuthMiddleware(keys))
+	jugador.Use(middlewares.RequireSession)
+	jugador.HandleFunc("/auth/logout", authHandler.Logout).Methods("POST")
+	jugador.HandleFunc("/auth/logout-all", authHandler.LogoutAll).Methods("POST")
+	jugador.HandleFunc("/auth/resend-verification", authHandler.ResendVerification).Methods("POST")
+	jugador.HandleFunc("/me", perfilHandler.GetPerfil).Methods("GET")
+	jugador.Handle("/me", limiteCredenciales(perfilHandler.UpdatePerfil)).Methods("PATCH")
+	jugador.HandleFunc("/me/partidos", perfilHandler.GetMisPartidos).Methods("GET")
+	jugador.HandleFunc("/me/propuestas", perfilHandler.GetMisPropuestas).Methods("GET")
+	jugador.HandleFunc("/me/resultados-pendientes", perfilHandler.GetMisResultadosPorConfirmar).Methods("GET")
+	jugador.HandleFunc("/me/mfa", authHandler.GetMFAStatus).Methods("GET")
+	jugador.HandleFunc("/me/mfa/enroll", authHandler.EnrollMFA).Methods("POST")
+	jugador.Handle("/me/mfa/activate", limiteCredenciales(authHandler.ActivateMFA)).Methods("POST")
+	jugador.Handle("/me/mfa/disable", limiteCredenciales(authHandler.DisableMFA)).Methods("POST")
+	jugador.Handle("/me/mfa/recovery-codes", limiteCredenciales(authHandler.RegenerateRecoveryCodes)).Methods("POST")
+	jugador.HandleFunc("/reclamos", reclamoHandler.GetMisReclamos).Methods("GET")
+	jugador.HandleFunc("/reclamos", reclamoHandler.CreateReclamo).Methods("POST")
+	jugador.HandleFunc("/reclamos/{id:[0-9]+}/confirmar", reclamoHandler.ConfirmReclamo).Methods("POST")
+	jugador.HandleFunc("/partidos/{id:[0-9]+}/propuestas", propuestaHandler.GetPropuestas).Methods("GET")
+	jugador.HandleFunc("/partidos/{id:[0-9]+}/propuestas", propuestaHandler.CreatePropuesta).Methods("POST")
+	jugador.HandleFunc("/propuestas/{id:[0-9]+}/aceptar", propuestaHandler.AcceptPropuesta).Methods("POST")
+	jugador.HandleFunc("/propuestas/{id:[0-9]+}/rechazar", propuestaHandler.RejectPropuesta).Methods("POST")
+	jugador.HandleFunc("/propuestas/{id:[0-9]+}/contrapropuesta", propuestaHandler.CounterPropuesta).Methods("POST")
+	jugador.HandleFunc("/partidos/{id:[0-9]+}/resultado", partidoHandler.ReportMatchResult).Methods("POST")
+	jugador.HandleFunc("/partidos/{id:[0-9]+}/reportes", partidoHandler.GetReportes).Methods("GET")
+
+	// Rutas de administración: cada una exige un permiso, algunos limitables a un
+	// torneo o a una categoría (ver models.Permisos)
+	admin := r.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(middlewares.AuthMiddleware(keys))
+	permiso := func(p string, alcance middlewares.AlcanceResolver, h http.HandlerFunc) http.Handler {
+		return middlewares.RequirePermission(p, alcance)(h)
+	}
+	alcancePartido := middlewares.AlcancePorID(rolService.AlcancePartido)
+	alcanceGrupo := middlewares.AlcancePorID(rolService.AlcanceGrupo)
+	alcanceJugador := middlewares.AlcancePorID(rolService.AlcanceJugador)
+
+	admin.Handle("/torneos", permiso(models.PermisoTorneosEdit, nil, torneoHandler.CreateTorneo)).Methods("POST")
+	admin.Handle("/torneos/{id:[0-9]+}", permiso(models.PermisoTorneosEdit, middlewares.AlcanceTorneo("id"), torneoHandler.UpdateTorneo)).Methods("PUT")
+	admin.Handle("/torneos/{id:[0-9]+}", permiso(models.PermisoTorneosEdit, middlewares.AlcanceTorneo("id"), torneoHandler.DeleteTorneo)).Methods("DELETE")
+	admin.Handle("/torneos/{id:[0-9]+}/estado", permiso(models.PermisoTorneosEdit, middlewares.AlcanceTorneo("id"), torneoHandler.ChangeEstado)).Methods("POST")
+	admin.Handle("/torneos/{torneo_id:[0-9]+}/categorias/{categoria_id:[0-9]+}/llave", permiso(models.PermisoLlavesEdit, middlewares.AlcanceTorneoCategoria, bracketHandler.GenerateBracket)).Methods("POST")
+	admin.Handle("/torneos/{torneo_id:[0-9]+}/categorias/{categoria_id:[0-9]+}/grupos", permiso(models.PermisoLlavesEdit, middlewares.AlcanceTorneoCategoria, grupoHandler.CreateGrupo)).Methods("POST")
+	admin.Handle("/categorias", permiso(models.PermisoCategoriasEdit, nil, categoriaHandler.CreateCategoria)).Methods("POST")
+	admin.Handle("/categorias/{id:[0-9]+}", permiso(models.PermisoCategoriasEdit, middlewares.AlcanceCategoria("id"), categoriaHandler.UpdateCategoria)).Methods("PUT")
+	admin.Handle("/categorias/{id:[0-9]+}", permiso(models.PermisoCategoriasEdit, middlewares.AlcanceCategoria("id"), categoriaHandler.DeleteCategoria)).Methods("DELETE")
+	// La categoría de un jugador nuevo viene en el cuerpo; el handler la verifica
+	admin.Handle("/jugadores", middlewares.RequireAnyPermission(models.PermisoJugadoresEdit)(http.HandlerFunc(jugadorHandler.CreateJugador))).Methods("POST")
+	admin.Handle("/jugadores/{id:[0-9]+}", permiso(models.PermisoJugadoresEdit, alcanceJugador, jugadorHandler.UpdateJugador)).Methods("PUT")
+	admin.Handle("/jugadores/{id:[0-9]+}", permiso(models.PermisoJugadoresEdit, alcanceJugador, jugadorHandler.DeleteJugador)).Methods("DELETE")
+	admin.Handle("/grupos/{id:[0-9]+}/fixture", permiso(models.PermisoLlavesEdit, alcanceGrupo, grupoHandler.GenerateFixture)).Methods("POST")
+	// El alcance de un partido nuevo viene en el cuerpo; el handler lo verifica
+	admin.Handle("/partidos", middlewares.RequireAnyPermission(models.PermisoPartidosEdit)(http.HandlerFunc(partidoHandler.CreatePartido))).Methods("POST")
+	admin.Handle("/partidos/escalados", permiso(models.PermisoPartidosSchedule, nil, propuestaHandler.GetPartidosEscalados)).Methods("GET")
+	admin.Handle("/partidos/{id:[0-9]+}", permiso(models.PermisoPartidosEdit, alcancePartido, partidoHandler.UpdatePartido)).Methods("PUT")
+	admin.Handle("/partidos/{id:[0-9]+}", permiso(models.PermisoPartidosEdit, alcancePartido, partidoHandler.DeletePartido)).Methods("DELETE")
+	admin.Handle("/partidos/{id:[0-9]+}/agendar", permiso(models.PermisoPartidosSchedule, alcancePartido, propuestaHandler.AgendarPartido)).Methods("POST")
+	admin.Handle("/partidos/{id:[0-9]+}/aprobar", permiso(models.PermisoPartidosApprove, alcancePartido, partidoHandler.ApproveResult)).Methods("POST")
+	admin.Handle("/partidos/{id:[0-9]+}/estado", permiso(models.PermisoPartidosEdit, alcancePartido, partidoHandler.ChangeEstado)).Methods("POST")
+	admin.Handle("/partidos/{id:[0-9]+}/walkover", permiso(models.PermisoPartidosApprove, alcancePartido, partidoHandler.RecordWalkover)).Methods("POST")
+	admin.Handle("/partidos/{id:[0-9]+}/abandono", permiso(models.PermisoPartidosApprove, alcancePartido, partidoHandler.RecordRetirement)).Methods("POST")
+	admin.Handle("/partidos/{id:[0-9]+}/descalificacion", permiso(models.PermisoPartidosApprove, alcancePartido, partidoHandler.RecordDefault)).Methods("POST")
+	admin.Handle("/partidos/{id:[0-9]+}/disputa/resolver", permiso(models.PermisoPartidosApprove, alcancePartido, partidoHandler.ResolveDispute)).Methods("POST")
+	admin.Handle("/disputas", permiso(models.PermisoPartidosApprove, nil, partidoHandler.GetDisputas)).Methods("GET")
+	admin.Handle("/reclamos", permiso(models.PermisoUsuariosManage, nil, reclamoHandler.GetReclamosPendientes)).Methods("GET")
+	admin.Handle("/reclamos/{id:[0-9]+}/aprobar", permiso(models.PermisoUsuariosManage, nil, reclamoHandler.ApproveReclamo)).Methods("POST")
+	admin.Handle("/reclamos/{id:[0-9]+}/rechazar", permiso(models.PermisoUsuariosManage, nil, reclamoHandler.RejectReclamo)).Methods("POST")
+	admin.Handle("/reclamos/{id:[0-9]+}/codigo", permiso(models.PermisoUsuariosManage, nil, reclamoHandler.IssueCodigo)).Methods("POST")
+	admin.Handle("/usuarios", permiso(models.PermisoUsuariosManage, nil, usuarioHandler.GetUsuarios)).Methods("GET")
+	admin.Handle("/usuarios/{id:[0-9]+}", permiso(models.PermisoUsuariosManage, nil, usuarioHandler.GetUsuario)).Methods("GET")
+	admin.Handle("/usuarios/{id:[0-9]+}/jugador", permiso(models.PermisoUsuariosManage, nil, usuarioHandler.LinkJugador)).Methods("PUT")
+	admin.Handle("/usuarios/{id:[0-9]+}/jugador", permiso(models.PermisoUsuariosManage, nil, usuarioHandler.UnlinkJugador)).Methods("DELETE")
+	admin.Handle("/usuarios/{id:[0-9]+}/activo", permiso(models.PermisoUsuariosManage, nil, usuarioHandler.SetActivo)).Methods("PUT")
+	admin.Handle("/usuarios/{id:[0-9]+}/auditoria", permiso(models.PermisoUsuariosManage, nil, usuarioHandler.GetAuditoria)).Methods("GET")
+	admin.Handle("/usuarios/{id:[0-9]+}/desbloquear", permiso(models.PermisoUsuariosManage, nil, usuarioHandler.Unlock)).Methods("POST")
+	admin.Handle("/usuarios/{id:[0-9]+}/mfa", permiso(models.PermisoUsuariosManage, nil, usuarioHandler.ResetMFA)).Methods("DELETE")
+	// Cambiar el rol base o asignar roles equivale a otorgar permisos
+	admin.Handle("/usuarios/{id:[0-9]+}/rol", permiso(models.PermisoRolesManage, nil, usuarioHandler.ChangeRol)).Methods("PUT")
+	admin.Handle("/usuarios/{id:[0-9]+}/roles", permiso(models.PermisoRolesManage, nil, rolHandler.GetAsignaciones)).Methods("GET")
+	admin.Handle("/usuarios/{id:[0-9]+}/roles", permiso(models.PermisoRolesManage, nil, rolHandler.AsignarRol)).Methods("POST")
+	admin.Handle("/usuarios/{id:[0-9]+}/roles/{asignacion_id:[0-9]+}", permiso(models.PermisoRolesManage, nil, rolHandler.RevocarRol)).Methods("DELETE")
+	admin.Handle("/roles", permiso(models.PermisoRolesManage, nil, rolHandler.GetRoles)).Methods("GET")
+	admin.Handle("/roles", permiso(models.PermisoRolesManage, nil, rolHandler.CreateRol)).Methods("POST")
+	admin.Handle("/roles/{id:[0-9]+}", permiso(models.PermisoRolesManage, nil, rolHandler.UpdateRol)).Methods("PUT")
+	admin.Handle("/roles/{id:[0-9]+}", permiso(models.PermisoRolesManage, nil, rolHandler.DeleteRol)).Methods("DELETE")
+	admin.Handle("/permisos", permiso(models.PermisoRolesManage, nil, rolHandler.GetPermisos)).Methods("GET")
+	admin.Handle("/noticias", permiso(models.PermisoNoticiasPublish, nil, noticiaHandler.GetNoticias)).Methods("GET")
+	admin.Handle("/noticias", permiso(models.PermisoNoticiasPublish, nil, noticiaHandler.CreateNoticia)).Methods("POST")
+	admin.Handle("/noticias/{id:[0-9]+}", permiso(models.PermisoNoticiasPublish, nil, noticiaHandler.GetNoticia)).Methods("GET")
+	admin.Handle("/noticias/{id:[0-9]+}", permiso(models.PermisoNoticiasPublish, nil, noticiaHandler.UpdateNoticia)).Methods("PUT")
+	admin.Handle("/noticias/{id:[0-9]+}", permiso(models.PermisoNoticiasPublish, nil, noticiaHandler.DeleteNoticia)).Methods("DELETE")
+	admin.Handle("/auspiciadores", permiso(models.PermisoAuspiciadoresEdit, nil, auspiciadorHandler.GetAuspiciadores)).Methods("GET")
+	admin.Handle("/auspiciadores", permiso(models.PermisoAuspiciadoresEdit, nil, auspiciadorHandler.CreateAuspiciador)).Methods("POST")
+	admin.Handle("/auspiciadores/orden", permiso(models.PermisoAuspiciadoresEdit, nil, auspiciadorHandler.ReordenarAuspiciadores)).Methods("PUT")
+	admin.Handle("/auspiciadores/{id:[0-9]+}", permiso(models.PermisoAuspiciadoresEdit, nil, auspiciadorHandler.GetAuspiciador)).Methods("GET")
+	admin.Handle("/auspiciadores/{id:[0-9]+}", permiso(models.PermisoAuspiciadoresEdit, nil, auspiciadorHandler.UpdateAuspiciador)).Methods("PUT")
+	admin.Handle("/auspiciadores/{id:[0-9]+}", permiso(models.PermisoAuspiciadoresEdit, nil, auspiciadorHandler.DeleteAuspiciador)).Methods("DELETE")
+	admin.Handle("/auspiciadores/{id:[0-9]+}/auspicios", permiso(models.PermisoAuspiciadoresEdit, nil, auspiciadorHandler.CreateAuspicio)).Methods("POST")
+	admin.Handle("/auspiciadores/{id:[0-9]+}/auspicios/{auspicio_id:[0-9]+}", permiso(models.PermisoAuspiciadoresEdit, nil, auspiciadorHandler.DeleteAuspicio)).Methods("DELETE")
+	admin.Handle("/campeones", permiso(models.PermisoTorneosEdit, nil, campeonHandler.CreateCampeon)).Methods("POST")
+	admin.Handle("/campeones/derivar", permiso(models.PermisoTorneosEdit, nil, campeonHandler.DerivarCampeones)).Methods("POST")
+	admin.Handle("/campeones/{id:[0-9]+}", permiso(models.PermisoTorneosEdit, nil, campeonHandler.UpdateCampeon)).Methods("PUT")
+	admin.Handle("/campeones/{id:[0-9]+}", permiso(models.PermisoTorneosEdit, nil, campeonHandler.DeleteCampeon)).Methods("DELETE")
+	admin.Handle("/fotos", permiso(models.PermisoFotosUpload, nil, fotoHandler.SubirFoto)).Methods("POST")
+	admin.Handle("/fotos/{id:[0-9]+}", permiso(models.PermisoFotosUpload, nil, fotoHandler.UpdateFoto)).Methods("PUT")
+	admin.Handle("/fotos/{id:[0-9]+}", permiso(models.PermisoFotosUpload, nil, fotoHandler.DeleteFoto)).Methods("DELETE")
+	// Una API key no puede administrar otras claves
+	admin.Handle("/api-keys", middlewares.RequireSession(permiso(models.PermisoAPIKeysManage, nil, apiKeyHandler.GetAPIKeys))).Methods("GET")
+	admin.Handle("/api-keys", middlewares.RequireSession(permiso(models.PermisoAPIKeysManage, nil, apiKeyHandler.CreateAPIKey))).Methods("POST")
+	admin.Handle("/api-keys/{id:[0-9]+}", middlewares.RequireSession(permiso(models.PermisoAPIKeysManage, nil, apiKeyHandler.RevokeAPIKey))).Methods("DELETE")
 
 	return r
 }
@@ -1,5 +0,0 @@
-package routes
-
-// Backup of original routes.go before simplification
-// This file contains the original complex routing structure
-// that had many undefined handlers and middlewares
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+
+	"github.com/lib/pq"
+)
+
+// largoPrefijoAPIKey es cuántos caracteres de la clave se guardan en claro para reconocerla
+const largoPrefijoAPIKey = 11
+
+var (
+	// ErrAPIKeyNotFound indica que la API key no existe o ya fue revocada
+	ErrAPIKeyNotFound = errors.New("API key no encontrada")
+	// ErrAPIKeySinPermisos indica que se intenta crear una API key sin permisos
+	ErrAPIKeySinPermisos = errors.New("la API key debe tener al menos un permiso")
+	// ErrAPIKeyPermisoAjeno indica que se intenta otorgar a la API key un permiso que su creador no tiene
+	ErrAPIKeyPermisoAjeno = errors.New("no se puede otorgar a una API key un permiso que no se tiene")
+	// ErrAPIKeyExpiracion indica una fecha de expiración pasada
+	ErrAPIKeyExpiracion = errors.New("la fecha de expiración debe ser futura")
+)
+
+type APIKeyService interface {
+	GetAPIKeys() ([]models.APIKey, error)
+	CreateAPIKey(key *models.APIKey) (string, error)
+	RevokeAPIKey(id int) error
+	AuthenticateAPIKey(clave string) (*models.APIKey, error)
+}
+
+type apiKeyServiceImpl struct {
+	rolService RolService
+}
+
+// NewAPIKeyService crea el servicio de API keys; rolService verifica que quien crea
+// una clave tenga los permisos que le otorga
+func NewAPIKeyService(rolService RolService) APIKeyService {
+	return &apiKeyServiceImpl{
+		rolService: rolService,
+	}
+}
+
+const apiKeySelect = `
+	SELECT id, nombre, prefijo, permisos, creada_por, expira_en, ultimo_uso_en, revocada_en, created_at
+	FROM api_keys`
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var k models.APIKey
+	err := row.Scan(&k.ID, &k.Nombre, &k.Prefijo, pq.Array(&k.Permisos), &k.CreadaPor,
+		&k.ExpiraEn, &k.UltimoUsoEn, &k.RevocadaEn, &k.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// GetAPIKeys lista todas las claves, las más recientes primero, incluidas las
+// revocadas y vencidas
+func (s *apiKeyServiceImpl) GetAPIKeys() ([]models.APIKey, error) {
+	rows, err := database.DB.Query(apiKeySelect + ` ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+
+	return keys, rows.Err()
+}
+
+// CreateAPIKey guarda la clave a nombre de key.CreadaPor y la devuelve en claro;
+// es la única vez que se puede ver
+func (s *apiKeyServiceImpl) CreateAPIKey(key *models.APIKey) (string, error) {
+	permisos, err := normalizarPermisos(key.Permisos)
+	if err != nil {
+		return "", err
+	}
+	if len(permisos) == 0 {
+		return "", ErrAPIKeySinPermisos
+	}
+	if key.ExpiraEn.Valid && !key.ExpiraEn.Time.After(time.Now()) {
+		return "", ErrAPIKeyExpiracion
+	}
+
+	concesiones, err := s.rolService.GetConcesiones(key.CreadaPor)
+	if err != nil {
+		return "", err
+	}
+	for _, permiso := range permisos {
+		if !concesiones.TienePermiso(permiso) {
+			return "", fmt.Errorf("%w: %s", ErrAPIKeyPermisoAjeno, permiso)
+		}
+	}
+
+	clave, err := utils.GenerateAPIKey()
+	if err != nil {
+		return "", err
+	}
+	key.Prefijo = clave[:largoPrefijoAPIKey]
+	key.Permisos = permisos
+
+	err = database.DB.QueryRow(`
+		INSERT INTO api_keys (nombre, prefijo, clave_hash, permisos, creada_por, expira_en, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at`,
+		key.Nombre, key.Prefijo, utils.HashToken(clave), pq.Array(key.Permisos), key.CreadaPor, key.ExpiraEn,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+
+	return clave, nil
+}
+
+func (s *apiKeyServiceImpl) RevokeAPIKey(id int) error {
+	result, err := database.DB.Exec(`UPDATE api_keys SET revocada_en = NOW() WHERE id = $1 AND revocada_en IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey devuelve la clave vigente que corresponde al valor recibido y
+// registra su uso; nil si no existe, venció, fue revocada o su creador está
+// deshabilitado
+func (s *apiKeyServiceImpl) AuthenticateAPIKey(clave string) (*models.APIKey, error) {
+	k, err := scanAPIKey(database.DB.QueryRow(`
+		UPDATE api_keys k SET ultimo_uso_en = NOW()
+		FROM usuarios u
+		WHERE k.clave_hash = $1 AND k.revocada_en IS NULL AND (k.expira_en IS NULL OR k.expira_en > NOW())
+		  AND u.id = k.creada_por AND u.activo
+		RETURNING k.id, k.nombre, k.prefijo, k.permisos, k.creada_por, k.expira_en, k.ultimo_uso_en,
+		          k.revocada_en, k.created_at`, utils.HashToken(clave)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return k, err
+}
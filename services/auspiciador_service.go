@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrAuspiciadorNotFound indica que el auspiciador no existe
+	ErrAuspiciadorNotFound = errors.New("auspiciador no encontrado")
+	// ErrAuspicioNotFound indica que el auspiciador no tiene ese auspicio
+	ErrAuspicioNotFound = errors.New("auspicio no encontrado")
+	// ErrAuspicioInvalido indica un nivel desconocido o una vigencia que termina antes de empezar
+	ErrAuspicioInvalido = errors.New("el nivel debe ser oro, plata o bronce y la vigencia no puede terminar antes de empezar")
+	// ErrAuspicioVinculo indica que el torneo o la categoría del auspicio no existen
+	ErrAuspicioVinculo = errors.New("el torneo o la categoría del auspicio no existen")
+	// ErrOrdenIncompleto indica que el reordenamiento no incluye a todos los auspiciadores una sola vez
+	ErrOrdenIncompleto = errors.New("el orden debe incluir a todos los auspiciadores una sola vez")
+)
+
+type AuspiciadorService interface {
+	GetAuspiciadores() ([]models.Auspiciador, error)
+	GetAuspiciadoresVigentes(categoriaID int) ([]models.Auspiciador, error)
+	GetAuspiciadorByID(id int) (*models.Auspiciador, error)
+	CreateAuspiciador(auspiciador *models.Auspiciador) error
+	UpdateAuspiciador(id int, auspiciador *models.Auspiciador) error
+	DeleteAuspiciador(id int) error
+	Reordenar(ids []int) error
+	CreateAuspicio(auspicio *models.Auspicio) error
+	DeleteAuspicio(auspiciadorID, auspicioID int) error
+}
+
+type auspiciadorServiceImpl struct{}
+
+func NewAuspiciadorService() AuspiciadorService {
+	return &auspiciadorServiceImpl{}
+}
+
+const auspiciadorSelect = `
+	SELECT a.id, a.nombre, a.logo_url, a.enlace_web, a.descripcion, a.activo, a.orden, a.created_at, a.updated_at
+	FROM auspiciadores a`
+
+func scanAuspiciador(row rowScanner) (*models.Auspiciador, error) {
+	var a models.Auspiciador
+	err := row.Scan(&a.ID, &a.Nombre, &a.LogoURL, &a.EnlaceWeb, &a.Descripcion, &a.Activo, &a.Orden,
+		&a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetAuspiciadores lista todos los auspiciadores en orden de visualización, con
+// sus auspicios
+func (s *auspiciadorServiceImpl) GetAuspiciadores() ([]models.Auspiciador, error) {
+	rows, err := database.DB.Query(auspiciadorSelect + ` ORDER BY a.orden, a.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	auspiciadores := []models.Auspiciador{}
+	indices := make(map[int]int)
+	var ids []int64
+	for rows.Next() {
+		a, err := scanAuspiciador(rows)
+		if err != nil {
+			return nil, err
+		}
+		a.Auspicios = []models.Auspicio{}
+		indices[a.ID] = len(auspiciadores)
+		ids = append(ids, int64(a.ID))
+		auspiciadores = append(auspiciadores, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return auspiciadores, nil
+	}
+
+	auspicios, err := queryAuspicios(`WHERE auspiciador_id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	for _, auspicio := range auspicios {
+		a := &auspiciadores[indices[auspicio.AuspiciadorID]]
+		a.Auspicios = append(a.Auspicios, auspicio)
+	}
+	return auspiciadores, nil
+}
+
+// GetAuspiciadoresVigentes devuelve los auspiciadores activos con algún auspicio
+// vigente hoy para el torneo actual: los generales, los del torneo y, si se indica
+// una categoría, los de esa categoría. Se ordenan para mostrarlos y cada uno lleva
+// su mejor nivel.
+func (s *auspiciadorServiceImpl) GetAuspiciadoresVigentes(categoriaID int) ([]models.Auspiciador, error) {
+	torneoID, err := torneoActualID()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT a.id, a.nombre, a.logo_url, a.enlace_web, a.descripcion, a.activo, a.orden, a.created_at, a.updated_at,
+		       (ARRAY_AGG(x.nivel ORDER BY CASE x.nivel WHEN 'oro' THEN 1 WHEN 'plata' THEN 2 ELSE 3 END))[1]
+		FROM auspiciadores a
+		JOIN auspicios x ON x.auspiciador_id = a.id
+		WHERE a.activo
+		  AND (x.torneo_id IS NULL OR x.torneo_id = $1)
+		  AND (x.categoria_id IS NULL OR x.categoria_id = $2)
+		  AND (x.vigente_desde IS NULL OR x.vigente_desde <= CURRENT_DATE)
+		  AND (x.vigente_hasta IS NULL OR x.vigente_hasta >= CURRENT_DATE)
+		GROUP BY a.id
+		ORDER BY a.orden, a.id`, torneoID, categoriaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	auspiciadores := []models.Auspiciador{}
+	for rows.Next() {
+		var a models.Auspiciador
+		err := rows.Scan(&a.ID, &a.Nombre, &a.LogoURL, &a.EnlaceWeb, &a.Descripcion, &a.Activo, &a.Orden,
+			&a.CreatedAt, &a.UpdatedAt, &a.Nivel)
+		if err != nil {
+			return nil, err
+		}
+		auspiciadores = append(auspiciadores, a)
+	}
+
+	return auspiciadores, rows.Err()
+}
+
+func (s *auspiciadorServiceImpl) GetAuspiciadorByID(id int) (*models.Auspiciador, error) {
+	a, err := scanAuspiciador(database.DB.QueryRow(auspiciadorSelect+" WHERE a.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAuspiciadorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Auspicios, err = queryAuspicios(`WHERE auspiciador_id = $1`, id); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// CreateAuspiciador agrega el auspiciador al final del orden
+func (s *auspiciadorServiceImpl) CreateAuspiciador(auspiciador *models.Auspiciador) error {
+	return database.DB.QueryRow(`
+		INSERT INTO auspiciadores (nombre, logo_url, enlace_web, descripcion, activo, orden, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, (SELECT COALESCE(MAX(orden), 0) + 1 FROM auspiciadores), NOW(), NOW())
+		RETURNING id, orden, created_at, updated_at`,
+		auspiciador.Nombre, auspiciador.LogoURL, auspiciador.EnlaceWeb, auspiciador.Descripcion, auspiciador.Activo,
+	).Scan(&auspiciador.ID, &auspiciador.Orden, &auspiciador.CreatedAt, &auspiciador.UpdatedAt)
+}
+
+// UpdateAuspiciador cambia los datos del auspiciador; el orden se cambia con Reordenar
+func (s *auspiciadorServiceImpl) UpdateAuspiciador(id int, auspiciador *models.Auspiciador) error {
+	result, err := database.DB.Exec(`
+		UPDATE auspiciadores
+		SET nombre = $1, logo_url = $2, enlace_web = $3, descripcion = $4, activo = $5, updated_at = NOW()
+		WHERE id = $6`,
+		auspiciador.Nombre, auspiciador.LogoURL, auspiciador.EnlaceWeb, auspiciador.Descripcion, auspiciador.Activo, id,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAuspiciadorNotFound
+	}
+	return nil
+}
+
+func (s *auspiciadorServiceImpl) DeleteAuspiciador(id int) error {
+	result, err := database.DB.Exec(`DELETE FROM auspiciadores WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAuspiciadorNotFound
+	}
+	return nil
+}
+
+// Reordenar asigna el orden de visualización según la posición de cada ID en la
+// lista, que debe incluir a todos los auspiciadores. Se aplica todo o nada.
+func (s *auspiciadorServiceImpl) Reordenar(ids []int) error {
+	vistos := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if vistos[id] {
+			return ErrOrdenIncompleto
+		}
+		vistos[id] = true
+	}
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		// El bloqueo impide que se agregue o borre un auspiciador mientras tanto
+		var total int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM (SELECT id FROM auspiciadores FOR UPDATE) a`).Scan(&total); err != nil {
+			return err
+		}
+		if total != len(ids) {
+			domainErr = ErrOrdenIncompleto
+			return domainErr
+		}
+
+		for posicion, id := range ids {
+			result, err := tx.Exec(`UPDATE auspiciadores SET orden = $1, updated_at = NOW() WHERE id = $2`, posicion+1, id)
+			if err != nil {
+				return err
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if rows == 0 {
+				domainErr = ErrOrdenIncompleto
+				return domainErr
+			}
+		}
+		return nil
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+func queryAuspicios(where string, args ...interface{}) ([]models.Auspicio, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, auspiciador_id, torneo_id, categoria_id, nivel, vigente_desde, vigente_hasta, created_at
+		FROM auspicios `+where+`
+		ORDER BY vigente_desde NULLS FIRST, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	auspicios := []models.Auspicio{}
+	for rows.Next() {
+		var x models.Auspicio
+		err := rows.Scan(&x.ID, &x.AuspiciadorID, &x.TorneoID, &x.CategoriaID, &x.Nivel,
+			&x.VigenteDesde, &x.VigenteHasta, &x.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		auspicios = append(auspicios, x)
+	}
+
+	return auspicios, rows.Err()
+}
+
+// ValidateAuspicio exige un nivel conocido y una vigencia que no termine antes de empezar
+func ValidateAuspicio(auspicio *models.Auspicio) error {
+	switch auspicio.Nivel {
+	case models.NivelOro, models.NivelPlata, models.NivelBronce:
+	default:
+		return ErrAuspicioInvalido
+	}
+	if auspicio.VigenteDesde.Valid && auspicio.VigenteHasta.Valid &&
+		auspicio.VigenteHasta.Time.Before(auspicio.VigenteDesde.Time) {
+		return ErrAuspicioInvalido
+	}
+	return nil
+}
+
+func (s *auspiciadorServiceImpl) CreateAuspicio(auspicio *models.Auspicio) error {
+	if auspicio.Nivel == "" {
+		auspicio.Nivel = models.NivelBronce
+	}
+	if err := ValidateAuspicio(auspicio); err != nil {
+		return err
+	}
+
+	err := database.DB.QueryRow(`
+		INSERT INTO auspicios (auspiciador_id, torneo_id, categoria_id, nivel, vigente_desde, vigente_hasta, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at`,
+		auspicio.AuspiciadorID, auspicio.TorneoID, auspicio.CategoriaID, auspicio.Nivel,
+		auspicio.VigenteDesde, auspicio.VigenteHasta,
+	).Scan(&auspicio.ID, &auspicio.CreatedAt)
+	if esViolacionForanea(err) {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "auspicios_auspiciador_id_fkey" {
+			return ErrAuspiciadorNotFound
+		}
+		return ErrAuspicioVinculo
+	}
+	return err
+}
+
+func (s *auspiciadorServiceImpl) DeleteAuspicio(auspiciadorID, auspicioID int) error {
+	result, err := database.DB.Exec(`DELETE FROM auspicios WHERE id = $1 AND auspiciador_id = $2`, auspicioID, auspiciadorID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAuspicioNotFound
+	}
+	return nil
+}
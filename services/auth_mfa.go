@@ -0,0 +1,446 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+)
+
+// Propósitos del token de MFA pendiente que entrega el login
+const (
+	mfaVerificacion = "verificacion" // La cuenta tiene MFA: falta el código
+	mfaEnrolamiento = "enrolamiento" // MFA es obligatorio y la cuenta todavía no lo activó
+)
+
+// CodigosRecuperacionMFA es la cantidad de códigos de recuperación que se emiten
+const CodigosRecuperacionMFA = 10
+
+var (
+	// ErrCodigoMFAInvalido indica que el código TOTP o de recuperación no es válido o ya se usó
+	ErrCodigoMFAInvalido = errors.New("código de verificación inválido")
+	// ErrMFATokenInvalido indica que el token de MFA pendiente venció o no corresponde al paso
+	ErrMFATokenInvalido = errors.New("la verificación venció; iniciá sesión de nuevo")
+	// ErrMFAYaHabilitado indica que la cuenta ya tiene MFA activo
+	ErrMFAYaHabilitado = errors.New("la verificación en dos pasos ya está activa")
+	// ErrMFANoHabilitado indica que la cuenta no tiene MFA activo
+	ErrMFANoHabilitado = errors.New("la verificación en dos pasos no está activa")
+	// ErrMFASinEnrolar indica que se intenta activar MFA sin haber generado el secreto
+	ErrMFASinEnrolar = errors.New("primero hay que generar el secreto de la verificación en dos pasos")
+	// ErrMFAObligatorio indica que la configuración exige MFA para el rol de la cuenta
+	ErrMFAObligatorio = errors.New("la verificación en dos pasos es obligatoria para esta cuenta")
+)
+
+// MFAPendiente es la respuesta del login cuando falta el segundo factor
+type MFAPendiente struct {
+	Token        string `json:"mfa_token"`
+	Enrolamiento bool   `json:"mfa_enrolamiento"` // Hay que activar MFA antes de ingresar
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// LoginResult es el resultado de un login correcto: los tokens de la sesión o,
+// si la cuenta usa MFA, el paso pendiente
+type LoginResult struct {
+	Tokens *TokenPair
+	MFA    *MFAPendiente
+}
+
+// cuentaMFA son los datos de la cuenta que intervienen en la verificación
+type cuentaMFA struct {
+	usuario      models.Usuario
+	secreto      sql.NullString
+	habilitadoEn sql.NullTime
+	ultimoPaso   sql.NullInt64
+}
+
+func lockCuentaMFA(tx *sql.Tx, usuarioID int) (*cuentaMFA, error) {
+	var c cuentaMFA
+	err := tx.QueryRow(`
+		SELECT id, nombre_usuario, password_hash, rol, jugador_id, activo,
+		       mfa_secreto, mfa_habilitado_en, mfa_ultimo_paso
+		FROM usuarios
+		WHERE id = $1
+		FOR UPDATE`, usuarioID,
+	).Scan(&c.usuario.ID, &c.usuario.NombreUsuario, &c.usuario.PasswordHash, &c.usuario.Rol, &c.usuario.JugadorID,
+		&c.usuario.Activo, &c.secreto, &c.habilitadoEn, &c.ultimoPaso)
+	if err == sql.ErrNoRows {
+		return nil, ErrUsuarioNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// mfaRequerido indica si la configuración exige MFA para el rol
+func (s *authServiceImpl) mfaRequerido(rol string) bool {
+	return s.config.MFARequeridoAdmin && rol == models.RolAdministrador
+}
+
+func (s *authServiceImpl) emitirMFAPendiente(usuarioID int, proposito string) (*MFAPendiente, error) {
+	ttl := time.Duration(s.config.MFATokenTTL) * time.Minute
+	token, err := utils.GenerateMFAToken(usuarioID, proposito, s.keys, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAPendiente{
+		Token:        token,
+		Enrolamiento: proposito == mfaEnrolamiento,
+		ExpiresIn:    int(ttl.Seconds()),
+	}, nil
+}
+
+// verificarCodigoMFA acepta un código TOTP que no se haya usado antes o, si
+// recuperacion es true, un código de recuperación sin usar. El código aceptado
+// queda consumido.
+func verificarCodigoMFA(tx *sql.Tx, cuenta *cuentaMFA, codigo string, recuperacion bool) (bool, error) {
+	codigo = strings.TrimSpace(codigo)
+	if len(codigo) == utils.TOTPDigitos {
+		paso, ok := utils.VerifyTOTP(cuenta.secreto.String, codigo, time.Now())
+		if !ok || (cuenta.ultimoPaso.Valid && paso <= cuenta.ultimoPaso.Int64) {
+			return false, nil
+		}
+		_, err := tx.Exec(`UPDATE usuarios SET mfa_ultimo_paso = $1 WHERE id = $2`, paso, cuenta.usuario.ID)
+		return err == nil, err
+	}
+
+	if !recuperacion {
+		return false, nil
+	}
+	result, err := tx.Exec(`
+		UPDATE mfa_codigos_recuperacion SET usado_en = NOW()
+		WHERE usuario_id = $1 AND codigo_hash = $2 AND usado_en IS NULL`,
+		cuenta.usuario.ID, utils.HashToken(utils.NormalizeRecoveryCode(codigo)),
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// reemplazarCodigosRecuperacion invalida los códigos anteriores y emite otros; se
+// devuelven en claro una única vez
+func reemplazarCodigosRecuperacion(tx *sql.Tx, usuarioID int) ([]string, error) {
+	if _, err := tx.Exec(`DELETE FROM mfa_codigos_recuperacion WHERE usuario_id = $1`, usuarioID); err != nil {
+		return nil, err
+	}
+
+	codigos := make([]string, 0, CodigosRecuperacionMFA)
+	for i := 0; i < CodigosRecuperacionMFA; i++ {
+		codigo, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO mfa_codigos_recuperacion (usuario_id, codigo_hash, created_at)
+			VALUES ($1, $2, NOW())`, usuarioID, utils.HashToken(utils.NormalizeRecoveryCode(codigo)))
+		if err != nil {
+			return nil, err
+		}
+		codigos = append(codigos, codigo)
+	}
+	return codigos, nil
+}
+
+// desactivarMFA borra el secreto y los códigos de recuperación de la cuenta
+func desactivarMFA(tx *sql.Tx, usuarioID, actorID int) error {
+	_, err := tx.Exec(`
+		UPDATE usuarios SET mfa_secreto = NULL, mfa_habilitado_en = NULL, mfa_ultimo_paso = NULL, updated_at = NOW()
+		WHERE id = $1`, usuarioID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM mfa_codigos_recuperacion WHERE usuario_id = $1`, usuarioID); err != nil {
+		return err
+	}
+	return recordAuditoria(tx, usuarioID, actorID, models.AuditoriaMFADesactivado, "", "")
+}
+
+func isMFADomainError(err error) bool {
+	for _, target := range []error{
+		ErrCodigoMFAInvalido, ErrMFATokenInvalido, ErrMFAYaHabilitado, ErrMFANoHabilitado,
+		ErrMFASinEnrolar, ErrMFAObligatorio, ErrUsuarioNotFound, ErrCuentaDeshabilitada,
+		ErrPasswordActualIncorrecta,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	var bloqueado *LoginBloqueadoError
+	return errors.As(err, &bloqueado)
+}
+
+// conCuentaMFA ejecuta fn en una transacción con la cuenta bloqueada y separa los
+// errores de dominio de los de la base de datos
+func conCuentaMFA(usuarioID int, fn func(tx *sql.Tx, cuenta *cuentaMFA) error) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		cuenta, err := lockCuentaMFA(tx, usuarioID)
+		if err == nil {
+			err = fn(tx, cuenta)
+		}
+		if isMFADomainError(err) {
+			domainErr = err
+		}
+		return err
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+// VerifyMFA completa un login con el código de la aplicación autenticadora o un
+// código de recuperación. Los códigos incorrectos cuentan para el bloqueo de login.
+func (s *authServiceImpl) VerifyMFA(mfaToken, codigo, ip string) (*TokenPair, error) {
+	usuarioID, err := utils.ParseMFAToken(mfaToken, mfaVerificacion, s.keys)
+	if err != nil {
+		return nil, ErrMFATokenInvalido
+	}
+
+	var pair *TokenPair
+	var nombreUsuario string
+	err = conCuentaMFA(usuarioID, func(tx *sql.Tx, cuenta *cuentaMFA) error {
+		nombreUsuario = cuenta.usuario.NombreUsuario
+		if err := s.throttler.Check(nombreUsuario, ip); err != nil {
+			return err
+		}
+		if !cuenta.usuario.Activo {
+			return ErrCuentaDeshabilitada
+		}
+		if !cuenta.habilitadoEn.Valid {
+			return ErrMFATokenInvalido
+		}
+
+		ok, err := verificarCodigoMFA(tx, cuenta, codigo, true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrCodigoMFAInvalido
+		}
+
+		sesionID, err := utils.GenerateSessionID()
+		if err != nil {
+			return err
+		}
+		pair, err = s.emitirTokens(tx, &cuenta.usuario, sesionID)
+		return err
+	})
+	if err != nil {
+		var bloqueado *LoginBloqueadoError
+		switch {
+		case errors.As(err, &bloqueado):
+			s.registrarLoginFallido("bloqueado", ip, usuarioID)
+		case errors.Is(err, ErrCodigoMFAInvalido):
+			s.throttler.RegistrarFallo(nombreUsuario, ip)
+			s.registrarLoginFallido("mfa_incorrecto", ip, usuarioID)
+		case errors.Is(err, ErrUsuarioNotFound):
+			err = ErrMFATokenInvalido
+		}
+		return nil, err
+	}
+
+	s.throttler.RegistrarExito(nombreUsuario)
+	utils.RecordAuthAttempt(true)
+	return pair, nil
+}
+
+func (s *authServiceImpl) GetMFAStatus(usuarioID int) (*models.EstadoMFA, error) {
+	var estado models.EstadoMFA
+	var rol string
+	err := database.DB.QueryRow(`
+		SELECT u.rol, u.mfa_habilitado_en,
+		       (SELECT COUNT(*) FROM mfa_codigos_recuperacion c WHERE c.usuario_id = u.id AND c.usado_en IS NULL)
+		FROM usuarios u
+		WHERE u.id = $1`, usuarioID,
+	).Scan(&rol, &estado.HabilitadoEn, &estado.CodigosRestantes)
+	if err == sql.ErrNoRows {
+		return nil, ErrUsuarioNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	estado.Habilitado = estado.HabilitadoEn.Valid
+	estado.Requerido = s.mfaRequerido(rol)
+	return &estado, nil
+}
+
+// EnrollMFA genera un secreto nuevo para la cuenta. Queda pendiente, sin afectar
+// el login, hasta que ActivateMFA confirme un código generado con él.
+func (s *authServiceImpl) EnrollMFA(usuarioID int) (*models.EnrolamientoMFA, error) {
+	var enrolamiento *models.EnrolamientoMFA
+	err := conCuentaMFA(usuarioID, func(tx *sql.Tx, cuenta *cuentaMFA) error {
+		if cuenta.habilitadoEn.Valid {
+			return ErrMFAYaHabilitado
+		}
+
+		secreto, err := utils.GenerateTOTPSecret()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE usuarios SET mfa_secreto = $1, mfa_ultimo_paso = NULL WHERE id = $2`, secreto, usuarioID)
+		if err != nil {
+			return err
+		}
+
+		enrolamiento = &models.EnrolamientoMFA{
+			Secreto: secreto,
+			URI:     utils.TOTPProvisioningURI(s.config.MFAEmisor, cuenta.usuario.NombreUsuario, secreto),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enrolamiento, nil
+}
+
+// activarMFA confirma el secreto pendiente con un código TOTP y emite los códigos
+// de recuperación
+func activarMFA(tx *sql.Tx, cuenta *cuentaMFA, codigo string) ([]string, error) {
+	if cuenta.habilitadoEn.Valid {
+		return nil, ErrMFAYaHabilitado
+	}
+	if !cuenta.secreto.Valid {
+		return nil, ErrMFASinEnrolar
+	}
+
+	ok, err := verificarCodigoMFA(tx, cuenta, codigo, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrCodigoMFAInvalido
+	}
+
+	_, err = tx.Exec(`UPDATE usuarios SET mfa_habilitado_en = NOW(), updated_at = NOW() WHERE id = $1`, cuenta.usuario.ID)
+	if err != nil {
+		return nil, err
+	}
+	codigos, err := reemplazarCodigosRecuperacion(tx, cuenta.usuario.ID)
+	if err != nil {
+		return nil, err
+	}
+	return codigos, recordAuditoria(tx, cuenta.usuario.ID, cuenta.usuario.ID, models.AuditoriaMFAActivado, "", "")
+}
+
+// ActivateMFA activa MFA en la cuenta y devuelve los códigos de recuperación
+func (s *authServiceImpl) ActivateMFA(usuarioID int, codigo string) ([]string, error) {
+	var codigos []string
+	err := conCuentaMFA(usuarioID, func(tx *sql.Tx, cuenta *cuentaMFA) error {
+		var err error
+		codigos, err = activarMFA(tx, cuenta, codigo)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codigos, nil
+}
+
+// EnrollMFAPending genera el secreto para una cuenta que debe activar MFA durante el login
+func (s *authServiceImpl) EnrollMFAPending(mfaToken string) (*models.EnrolamientoMFA, error) {
+	usuarioID, err := utils.ParseMFAToken(mfaToken, mfaEnrolamiento, s.keys)
+	if err != nil {
+		return nil, ErrMFATokenInvalido
+	}
+	return s.EnrollMFA(usuarioID)
+}
+
+// ActivateMFAPending activa MFA durante el login y completa el ingreso: devuelve los
+// códigos de recuperación y los tokens de la sesión
+func (s *authServiceImpl) ActivateMFAPending(mfaToken, codigo string) ([]string, *TokenPair, error) {
+	usuarioID, err := utils.ParseMFAToken(mfaToken, mfaEnrolamiento, s.keys)
+	if err != nil {
+		return nil, nil, ErrMFATokenInvalido
+	}
+
+	var codigos []string
+	var pair *TokenPair
+	var nombreUsuario string
+	err = conCuentaMFA(usuarioID, func(tx *sql.Tx, cuenta *cuentaMFA) error {
+		nombreUsuario = cuenta.usuario.NombreUsuario
+		if !cuenta.usuario.Activo {
+			return ErrCuentaDeshabilitada
+		}
+
+		var err error
+		if codigos, err = activarMFA(tx, cuenta, codigo); err != nil {
+			return err
+		}
+
+		sesionID, err := utils.GenerateSessionID()
+		if err != nil {
+			return err
+		}
+		pair, err = s.emitirTokens(tx, &cuenta.usuario, sesionID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.throttler.RegistrarExito(nombreUsuario)
+	utils.RecordAuthAttempt(true)
+	return codigos, pair, nil
+}
+
+// DisableMFA desactiva MFA con la contraseña y un código vigente. No se permite si
+// la configuración lo exige para el rol de la cuenta.
+func (s *authServiceImpl) DisableMFA(usuarioID int, password, codigo string) error {
+	return conCuentaMFA(usuarioID, func(tx *sql.Tx, cuenta *cuentaMFA) error {
+		if !cuenta.habilitadoEn.Valid {
+			return ErrMFANoHabilitado
+		}
+		if s.mfaRequerido(cuenta.usuario.Rol) {
+			return ErrMFAObligatorio
+		}
+		if utils.CheckPasswordHash(password, cuenta.usuario.PasswordHash) != nil {
+			return ErrPasswordActualIncorrecta
+		}
+
+		ok, err := verificarCodigoMFA(tx, cuenta, codigo, true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrCodigoMFAInvalido
+		}
+		return desactivarMFA(tx, usuarioID, usuarioID)
+	})
+}
+
+// RegenerateRecoveryCodes reemplaza los códigos de recuperación; exige un código
+// de la aplicación autenticadora
+func (s *authServiceImpl) RegenerateRecoveryCodes(usuarioID int, codigo string) ([]string, error) {
+	var codigos []string
+	err := conCuentaMFA(usuarioID, func(tx *sql.Tx, cuenta *cuentaMFA) error {
+		if !cuenta.habilitadoEn.Valid {
+			return ErrMFANoHabilitado
+		}
+
+		ok, err := verificarCodigoMFA(tx, cuenta, codigo, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrCodigoMFAInvalido
+		}
+
+		codigos, err = reemplazarCodigosRecuperacion(tx, usuarioID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codigos, nil
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/utils"
+)
+
+const (
+	tokenRecuperacionPassword = "recuperacion_password"
+	tokenVerificacionEmail    = "verificacion_email"
+)
+
+var (
+	// ErrTokenUsuarioInvalido indica que el enlace enviado por correo no existe, venció o ya se usó
+	ErrTokenUsuarioInvalido = errors.New("el enlace es inválido o venció")
+	// ErrSinEmail indica que el usuario no tiene un email cargado
+	ErrSinEmail = errors.New("el usuario no tiene un email registrado")
+	// ErrEmailYaVerificado indica que el email del usuario ya fue verificado
+	ErrEmailYaVerificado = errors.New("el email ya fue verificado")
+)
+
+// RequestPasswordReset envía un enlace de recuperación si el email pertenece a un
+// usuario. No informa si el email existe para no exponer qué cuentas hay.
+func (s *authServiceImpl) RequestPasswordReset(email string) error {
+	email = normalizarEmail(email)
+
+	var usuarioID int
+	err := database.DB.QueryRow(`SELECT id FROM usuarios WHERE LOWER(email) = $1`, email).Scan(&usuarioID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	ttl := time.Duration(s.config.PasswordResetTTL) * time.Minute
+	token, err := emitirTokenUsuario(database.DB, usuarioID, tokenRecuperacionPassword, email, ttl)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Recibimos un pedido para restablecer tu contraseña de Copa Litoral.\n\n"+
+			"Ingresá al siguiente enlace para elegir una nueva (vence en %d minutos):\n%s\n\n"+
+			"Si no lo pediste, ignorá este correo; tu contraseña no cambia.",
+		s.config.PasswordResetTTL, s.enlace("/restablecer-password", token),
+	)
+	if err := s.mailer.SendEmail(email, "Restablecer contraseña", body); err != nil {
+		utils.LogError("Error enviando correo de recuperación", err, map[string]interface{}{
+			"usuario_id": usuarioID,
+		})
+	}
+
+	return nil
+}
+
+// ResetPassword cambia la contraseña con un token de recuperación y cierra todas
+// las sesiones abiertas del usuario
+func (s *authServiceImpl) ResetPassword(token, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err = txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		usuarioID, _, err := consumirTokenUsuario(tx, token, tokenRecuperacionPassword)
+		if err != nil {
+			if errors.Is(err, ErrTokenUsuarioInvalido) {
+				domainErr = err
+			}
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE usuarios SET password_hash = $1, updated_at = NOW() WHERE id = $2`,
+			hashedPassword, usuarioID)
+		if err != nil {
+			return err
+		}
+
+		return revocarSesiones(tx, usuarioID, "")
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+// SendVerificationEmail envía al usuario un enlace para verificar su email
+func (s *authServiceImpl) SendVerificationEmail(usuarioID int) error {
+	var email sql.NullString
+	var verificadoEn sql.NullTime
+	err := database.DB.QueryRow(`SELECT email, email_verificado_en FROM usuarios WHERE id = $1`, usuarioID).
+		Scan(&email, &verificadoEn)
+	if err != nil {
+		return err
+	}
+	if !email.Valid || email.String == "" {
+		return ErrSinEmail
+	}
+	if verificadoEn.Valid {
+		return ErrEmailYaVerificado
+	}
+
+	ttl := time.Duration(s.config.EmailVerificationTTL) * time.Hour
+	token, err := emitirTokenUsuario(database.DB, usuarioID, tokenVerificacionEmail, email.String, ttl)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Confirmá tu email de Copa Litoral ingresando al siguiente enlace (vence en %d horas):\n%s",
+		s.config.EmailVerificationTTL, s.enlace("/verificar-email", token),
+	)
+	return s.mailer.SendEmail(email.String, "Verificá tu email", body)
+}
+
+// VerifyEmail marca como verificado el email al que se envió el token
+func (s *authServiceImpl) VerifyEmail(token string) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		usuarioID, email, err := consumirTokenUsuario(tx, token, tokenVerificacionEmail)
+		if err != nil {
+			if errors.Is(err, ErrTokenUsuarioInvalido) {
+				domainErr = err
+			}
+			return err
+		}
+
+		result, err := tx.Exec(`
+			UPDATE usuarios SET email_verificado_en = NOW(), updated_at = NOW()
+			WHERE id = $1 AND LOWER(email) = $2`, usuarioID, email)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			// El usuario cambió su email después de recibir el enlace
+			domainErr = ErrTokenUsuarioInvalido
+			return domainErr
+		}
+		return nil
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+func (s *authServiceImpl) enlace(ruta, token string) string {
+	return strings.TrimRight(s.config.FrontendURL, "/") + ruta + "?token=" + url.QueryEscape(token)
+}
+
+// emitirTokenUsuario invalida los tokens pendientes del mismo tipo y emite uno nuevo
+func emitirTokenUsuario(db execer, usuarioID int, tipo, email string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.Exec(`
+		UPDATE tokens_usuario SET usado_en = NOW()
+		WHERE usuario_id = $1 AND tipo = $2 AND usado_en IS NULL`, usuarioID, tipo)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO tokens_usuario (usuario_id, tipo, token_hash, email, expira_en, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())`,
+		usuarioID, tipo, utils.HashToken(token), normalizarEmail(email), time.Now().Add(ttl),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumirTokenUsuario valida un token de un solo uso y lo marca como usado
+func consumirTokenUsuario(tx *sql.Tx, token, tipo string) (int, string, error) {
+	var id, usuarioID int
+	var email string
+	var expiraEn time.Time
+	var usadoEn sql.NullTime
+	err := tx.QueryRow(`
+		SELECT id, usuario_id, email, expira_en, usado_en
+		FROM tokens_usuario
+		WHERE token_hash = $1 AND tipo = $2
+		FOR UPDATE`, utils.HashToken(token), tipo,
+	).Scan(&id, &usuarioID, &email, &expiraEn, &usadoEn)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", ErrTokenUsuarioInvalido
+		}
+		return 0, "", err
+	}
+
+	if usadoEn.Valid || time.Now().After(expiraEn) {
+		return 0, "", ErrTokenUsuarioInvalido
+	}
+
+	if _, err := tx.Exec(`UPDATE tokens_usuario SET usado_en = NOW() WHERE id = $1`, id); err != nil {
+		return 0, "", err
+	}
+
+	return usuarioID, email, nil
+}
+
+func normalizarEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -1,8 +1,11 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"sync"
+	"time"
 
 	"copa-litoral-backend/config"
 	"copa-litoral-backend/database"
@@ -10,18 +13,72 @@ import (
 	"copa-litoral-backend/utils"
 )
 
+var (
+	// ErrRefreshTokenInvalido indica que el refresh token no existe, venció o fue revocado
+	ErrRefreshTokenInvalido = errors.New("refresh token inválido o vencido")
+	// ErrRefreshTokenReutilizado indica que se presentó un refresh token ya rotado
+	ErrRefreshTokenReutilizado = errors.New("refresh token reutilizado; la sesión fue revocada")
+	// ErrCuentaDeshabilitada indica que un administrador deshabilitó la cuenta
+	ErrCuentaDeshabilitada = errors.New("la cuenta está deshabilitada")
+	// ErrCredencialesInvalidas no distingue entre usuario inexistente y contraseña incorrecta
+	ErrCredencialesInvalidas = errors.New("credenciales inválidas")
+)
+
+// hashReferencia se compara cuando el usuario no existe, para que la respuesta
+// tarde lo mismo que con una contraseña incorrecta
+var (
+	hashReferencia     string
+	hashReferenciaOnce sync.Once
+)
+
+func compararHashReferencia(password string) {
+	hashReferenciaOnce.Do(func() {
+		hashReferencia, _ = utils.HashPassword("copa-litoral-referencia")
+	})
+	utils.CheckPasswordHash(password, hashReferencia)
+}
+
+// TokenPair es el par de tokens que recibe el cliente al iniciar o renovar una sesión
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // Segundos de validez del token de acceso
+}
+
 type AuthService interface {
 	RegisterUser(user *models.Usuario) error
-	LoginUser(username, password string) (string, error)
+	LoginUser(username, password, ip string) (*LoginResult, error)
+	VerifyMFA(mfaToken, codigo, ip string) (*TokenPair, error)
+	RefreshSession(refreshToken string) (*TokenPair, error)
+	Logout(usuarioID int, sesionID string) error
+	LogoutAll(usuarioID int) error
+	IsSessionActive(usuarioID int, sesionID string) (bool, error)
+	RequestPasswordReset(email string) error
+	ResetPassword(token, password string) error
+	SendVerificationEmail(usuarioID int) error
+	VerifyEmail(token string) error
+	GetMFAStatus(usuarioID int) (*models.EstadoMFA, error)
+	EnrollMFA(usuarioID int) (*models.EnrolamientoMFA, error)
+	ActivateMFA(usuarioID int, codigo string) ([]string, error)
+	EnrollMFAPending(mfaToken string) (*models.EnrolamientoMFA, error)
+	ActivateMFAPending(mfaToken, codigo string) ([]string, *TokenPair, error)
+	DisableMFA(usuarioID int, password, codigo string) error
+	RegenerateRecoveryCodes(usuarioID int, codigo string) ([]string, error)
 }
 
 type authServiceImpl struct{
-	config *config.Config
+	config    *config.Config
+	keys      *utils.KeyRing
+	mailer    Mailer
+	throttler *LoginThrottler
 }
 
-func NewAuthService(cfg *config.Config) AuthService {
+func NewAuthService(cfg *config.Config, keys *utils.KeyRing, mailer Mailer, throttler *LoginThrottler) AuthService {
 	return &authServiceImpl{
-		config: cfg,
+		config:    cfg,
+		keys:      keys,
+		mailer:    mailer,
+		throttler: throttler,
 	}
 }
 
@@ -42,48 +99,280 @@ func (s *authServiceImpl) RegisterUser(user *models.Usuario) error {
 		return err
 	}
 
+	// El email es opcional, pero no puede repetirse
+	if user.Email.Valid {
+		user.Email.String = normalizarEmail(user.Email.String)
+		err = database.DB.QueryRow("SELECT id FROM usuarios WHERE LOWER(email) = $1", user.Email.String).Scan(&existingID)
+		if err == nil {
+			return errors.New("el email ya está registrado")
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+	}
+
+	// El registro público siempre crea cuentas de jugador; los administradores
+	// se designan desde la gestión de usuarios
+	user.Rol = models.RolJugador
+
 	// Insertar el nuevo usuario
 	query := `
 		INSERT INTO usuarios (nombre_usuario, email, password_hash, rol, jugador_id, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
-	return database.DB.QueryRow(query,
+	err = database.DB.QueryRow(query,
 		user.NombreUsuario, user.Email, user.PasswordHash, user.Rol, user.JugadorID,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Un fallo en el envío no impide el registro; el usuario puede pedir otro enlace
+	if user.Email.Valid {
+		if err := s.SendVerificationEmail(user.ID); err != nil {
+			utils.LogError("Error enviando correo de verificación", err, map[string]interface{}{
+				"usuario_id": user.ID,
+			})
+		}
+	}
+
+	return nil
 }
 
-func (s *authServiceImpl) LoginUser(username, password string) (string, error) {
+// LoginUser valida las credenciales desde la IP indicada. Los fallos se cuentan por
+// nombre de usuario y por IP; superado el umbral devuelve *LoginBloqueadoError. Si
+// la cuenta usa MFA, o debe activarlo, devuelve el paso pendiente en lugar de los tokens.
+func (s *authServiceImpl) LoginUser(username, password, ip string) (*LoginResult, error) {
+	if err := s.throttler.Check(username, ip); err != nil {
+		s.registrarLoginFallido("bloqueado", ip, 0)
+		return nil, err
+	}
+
 	// Buscar el usuario por nombre de usuario
 	var user models.Usuario
+	var mfaHabilitadoEn sql.NullTime
 	query := `
-		SELECT id, nombre_usuario, email, password_hash, rol, jugador_id, created_at, updated_at
+		SELECT id, nombre_usuario, email, password_hash, rol, jugador_id, activo, created_at, updated_at,
+		       mfa_habilitado_en
 		FROM usuarios
 		WHERE nombre_usuario = $1`
 
 	err := database.DB.QueryRow(query, username).Scan(
 		&user.ID, &user.NombreUsuario, &user.Email, &user.PasswordHash, &user.Rol, &user.JugadorID,
-		&user.CreatedAt, &user.UpdatedAt,
+		&user.Activo, &user.CreatedAt, &user.UpdatedAt, &mfaHabilitadoEn,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", errors.New("credenciales inválidas")
+			compararHashReferencia(password)
+			s.throttler.RegistrarFallo(username, ip)
+			s.registrarLoginFallido("usuario_inexistente", ip, 0)
+			return nil, ErrCredencialesInvalidas
 		}
-		return "", err
+		return nil, err
 	}
 
 	// Verificar la contraseña
 	err = utils.CheckPasswordHash(password, user.PasswordHash)
 	if err != nil {
-		return "", errors.New("credenciales inválidas")
+		s.throttler.RegistrarFallo(username, ip)
+		s.registrarLoginFallido("password_incorrecto", ip, user.ID)
+		return nil, ErrCredencialesInvalidas
+	}
+	if !user.Activo {
+		s.registrarLoginFallido("cuenta_deshabilitada", ip, user.ID)
+		return nil, ErrCuentaDeshabilitada
+	}
+
+	// Con MFA el login se completa al verificar el código; hasta entonces los
+	// fallos acumulados siguen contando
+	if mfaHabilitadoEn.Valid || s.mfaRequerido(user.Rol) {
+		proposito := mfaVerificacion
+		if !mfaHabilitadoEn.Valid {
+			proposito = mfaEnrolamiento
+		}
+		pendiente, err := s.emitirMFAPendiente(user.ID, proposito)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MFA: pendiente}, nil
+	}
+
+	s.throttler.RegistrarExito(username)
+	utils.RecordAuthAttempt(true)
+
+	// Cada login abre una sesión nueva: una familia de refresh tokens propia
+	sesionID, err := utils.GenerateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := s.emitirTokens(database.DB, &user, sesionID)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: pair}, nil
+}
+
+// registrarLoginFallido deja el motivo solo en el log; el cliente recibe siempre
+// el mismo error. El nombre de usuario no se registra para no guardar contraseñas
+// tipeadas por error en ese campo.
+func (s *authServiceImpl) registrarLoginFallido(motivo, ip string, usuarioID int) {
+	utils.RecordAuthAttempt(false)
+
+	fields := map[string]interface{}{
+		"motivo": motivo,
+		"ip":     ip,
+	}
+	if usuarioID != 0 {
+		fields["usuario_id"] = usuarioID
+	}
+	utils.LogInfo("Login fallido", fields)
+}
+
+// RefreshSession rota un refresh token: lo marca como usado y entrega un par nuevo
+// de la misma sesión. Presentar un token ya rotado revoca la sesión completa.
+func (s *authServiceImpl) RefreshSession(refreshToken string) (*TokenPair, error) {
+	var pair *TokenPair
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		var tokenID, usuarioID int
+		var sesionID string
+		var expiraEn time.Time
+		var usadoEn, revocadoEn sql.NullTime
+		err := tx.QueryRow(`
+			SELECT id, usuario_id, familia, expira_en, usado_en, revocado_en
+			FROM refresh_tokens
+			WHERE token_hash = $1
+			FOR UPDATE`, utils.HashToken(refreshToken),
+		).Scan(&tokenID, &usuarioID, &sesionID, &expiraEn, &usadoEn, &revocadoEn)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				domainErr = ErrRefreshTokenInvalido
+			}
+			return err
+		}
+
+		if revocadoEn.Valid || time.Now().After(expiraEn) {
+			domainErr = ErrRefreshTokenInvalido
+			return domainErr
+		}
+		if usadoEn.Valid {
+			// El token ya fue rotado: quien lo presenta puede haberlo robado, así que
+			// se revoca la sesión completa y se confirma la transacción
+			utils.LogError("Reutilización de refresh token", ErrRefreshTokenReutilizado, map[string]interface{}{
+				"usuario_id": usuarioID,
+				"sesion_id":  sesionID,
+			})
+			domainErr = ErrRefreshTokenReutilizado
+			return revocarSesiones(tx, usuarioID, sesionID)
+		}
+
+		if _, err := tx.Exec(`UPDATE refresh_tokens SET usado_en = NOW() WHERE id = $1`, tokenID); err != nil {
+			return err
+		}
+
+		var user models.Usuario
+		var mfaHabilitadoEn sql.NullTime
+		err = tx.QueryRow(`SELECT id, rol, jugador_id, activo, mfa_habilitado_en FROM usuarios WHERE id = $1`, usuarioID).
+			Scan(&user.ID, &user.Rol, &user.JugadorID, &user.Activo, &mfaHabilitadoEn)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				domainErr = ErrRefreshTokenInvalido
+			}
+			return err
+		}
+		if !user.Activo {
+			domainErr = ErrCuentaDeshabilitada
+			return domainErr
+		}
+		// Una sesión abierta antes de que MFA fuera obligatorio no se renueva: el
+		// usuario debe ingresar de nuevo y activarlo
+		if s.mfaRequerido(user.Rol) && !mfaHabilitadoEn.Valid {
+			domainErr = ErrMFAObligatorio
+			return revocarSesiones(tx, usuarioID, sesionID)
+		}
+
+		pair, err = s.emitirTokens(tx, &user, sesionID)
+		return err
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Logout revoca la sesión a la que pertenece el token de acceso
+func (s *authServiceImpl) Logout(usuarioID int, sesionID string) error {
+	return revocarSesiones(database.DB, usuarioID, sesionID)
+}
+
+// LogoutAll revoca todas las sesiones del usuario, en todos sus dispositivos
+func (s *authServiceImpl) LogoutAll(usuarioID int) error {
+	return revocarSesiones(database.DB, usuarioID, "")
+}
+
+// IsSessionActive indica si la sesión sigue abierta: tiene un refresh token
+// vigente sin rotar ni revocar
+func (s *authServiceImpl) IsSessionActive(usuarioID int, sesionID string) (bool, error) {
+	var activa bool
+	err := database.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM refresh_tokens
+			WHERE familia = $1 AND usuario_id = $2
+			  AND usado_en IS NULL AND revocado_en IS NULL AND expira_en > NOW()
+		)`, sesionID, usuarioID,
+	).Scan(&activa)
+	return activa, err
+}
+
+// execer abstrae *sql.DB y *sql.Tx para sentencias sin resultado
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// emitirTokens genera el token de acceso y un refresh token nuevo para la sesión
+func (s *authServiceImpl) emitirTokens(db execer, user *models.Usuario, sesionID string) (*TokenPair, error) {
+	accessTTL := time.Duration(s.config.AccessTokenTTL) * time.Minute
+	token, err := utils.GenerateJWT(user.ID, int(user.JugadorID.Int32), user.Rol, sesionID, s.keys, accessTTL)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generar JWT
-	token, err := utils.GenerateJWT(user.ID, user.Rol, s.config.JWTSecret)
+	refreshToken, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO refresh_tokens (usuario_id, familia, token_hash, expira_en, created_at)
+		VALUES ($1, $2, $3, $4, NOW())`,
+		user.ID, sesionID, utils.HashToken(refreshToken),
+		time.Now().Add(time.Duration(s.config.RefreshTokenTTL)*time.Hour),
+	)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return token, nil
-} 
\ No newline at end of file
+	return &TokenPair{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTTL.Seconds()),
+	}, nil
+}
+
+// revocarSesiones revoca los refresh tokens de una sesión del usuario, o de todas
+// si sesionID está vacío
+func revocarSesiones(db execer, usuarioID int, sesionID string) error {
+	_, err := db.Exec(`
+		UPDATE refresh_tokens SET revocado_en = NOW()
+		WHERE usuario_id = $1 AND ($2 = '' OR familia = $2) AND revocado_en IS NULL`,
+		usuarioID, sesionID,
+	)
+	return err
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrInvalidBracketSeeds indica que la lista de cabezas de serie no permite armar una llave
+	ErrInvalidBracketSeeds = errors.New("lista de jugadores inválida para la llave")
+	// ErrBracketExists indica que el torneo/categoría ya tiene una llave generada
+	ErrBracketExists = errors.New("ya existe una llave para este torneo y categoría")
+)
+
+// BracketMatch representa un partido planificado dentro de una llave de eliminación directa.
+// Un ID de jugador 0 significa que el lugar se define con el ganador de la ronda anterior.
+type BracketMatch struct {
+	Ronda      int    `json:"ronda"`
+	Posicion   int    `json:"posicion"`
+	Fase       string `json:"fase"`
+	Jugador1ID int    `json:"jugador1_id"`
+	Jugador2ID int    `json:"jugador2_id"`
+	Bye        bool   `json:"bye"` // El jugador presente avanza sin jugar
+}
+
+type BracketService interface {
+	GenerateBracket(torneoID int, categoriaID int, jugadorIDs []int) ([]models.Partido, error)
+	GetBracket(torneoID int, categoriaID int) ([]models.Partido, error)
+}
+
+type bracketServiceImpl struct{}
+
+func NewBracketService() BracketService {
+	return &bracketServiceImpl{}
+}
+
+// PlanBracket arma las rondas de una llave a partir de los jugadores ordenados por
+// cabeza de serie (el primero es el preclasificado 1). Si la cantidad de jugadores no
+// es potencia de dos, los mejores preclasificados reciben un bye en la primera ronda
+// y quedan ubicados directamente en la segunda.
+func PlanBracket(jugadorIDs []int) ([][]BracketMatch, error) {
+	n := len(jugadorIDs)
+	if n < 2 {
+		return nil, fmt.Errorf("%w: se requieren al menos 2 jugadores", ErrInvalidBracketSeeds)
+	}
+
+	seen := make(map[int]bool, n)
+	for _, id := range jugadorIDs {
+		if id <= 0 {
+			return nil, fmt.Errorf("%w: ID de jugador %d no válido", ErrInvalidBracketSeeds, id)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("%w: el jugador %d está repetido", ErrInvalidBracketSeeds, id)
+		}
+		seen[id] = true
+	}
+
+	size := 1
+	numRounds := 0
+	for size < n {
+		size *= 2
+		numRounds++
+	}
+
+	rounds := make([][]BracketMatch, numRounds)
+	for r := 0; r < numRounds; r++ {
+		matches := size >> (r + 1)
+		rounds[r] = make([]BracketMatch, matches)
+		for p := range rounds[r] {
+			rounds[r][p] = BracketMatch{
+				Ronda:    r + 1,
+				Posicion: p,
+				Fase:     faseForPlayers(size >> r),
+			}
+		}
+	}
+
+	// Ubicar a los preclasificados en la primera ronda; los lugares sobrantes son byes
+	order := seedOrder(size)
+	playerForSeed := func(seed int) int {
+		if seed > n {
+			return 0
+		}
+		return jugadorIDs[seed-1]
+	}
+
+	for p := range rounds[0] {
+		match := &rounds[0][p]
+		match.Jugador1ID = playerForSeed(order[2*p])
+		match.Jugador2ID = playerForSeed(order[2*p+1])
+
+		if match.Jugador1ID == 0 || match.Jugador2ID == 0 {
+			match.Bye = true
+			advancing := match.Jugador1ID + match.Jugador2ID
+			next := &rounds[1][p/2]
+			if p%2 == 0 {
+				next.Jugador1ID = advancing
+			} else {
+				next.Jugador2ID = advancing
+			}
+		}
+	}
+
+	return rounds, nil
+}
+
+// seedOrder devuelve los números de cabeza de serie en el orden en que se ubican
+// en una llave de tamaño size, de modo que el 1 y el 2 solo se crucen en la final.
+func seedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		total := len(order)*2 + 1
+		for _, seed := range order {
+			next = append(next, seed, total-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// faseForPlayers devuelve el nombre de la fase según la cantidad de jugadores de la ronda
+func faseForPlayers(players int) string {
+	switch players {
+	case 2:
+		return models.FaseFinal
+	case 4:
+		return models.FaseSemifinal
+	case 8:
+		return models.FaseCuartos
+	case 16:
+		return models.FaseOctavos
+	default:
+		return fmt.Sprintf("Ronda de %d", players)
+	}
+}
+
+func (s *bracketServiceImpl) GenerateBracket(torneoID int, categoriaID int, jugadorIDs []int) ([]models.Partido, error) {
+	rounds, err := PlanBracket(jugadorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// domainErr conserva los errores de negocio sin el envoltorio del TxManager
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err = txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		var existing int
+		err := tx.QueryRow(
+			`SELECT COUNT(*) FROM partidos WHERE torneo_id = $1 AND categoria_id = $2 AND ronda IS NOT NULL`,
+			torneoID, categoriaID,
+		).Scan(&existing)
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			domainErr = ErrBracketExists
+			return domainErr
+		}
+
+		var found int
+		err = tx.QueryRow(`SELECT COUNT(*) FROM jugadores WHERE id = ANY($1)`, pq.Array(jugadorIDs)).Scan(&found)
+		if err != nil {
+			return err
+		}
+		if found != len(jugadorIDs) {
+			domainErr = fmt.Errorf("%w: hay jugadores que no existen", ErrInvalidBracketSeeds)
+			return domainErr
+		}
+
+		// Se inserta desde la final hacia atrás para conocer el partido siguiente de cada uno
+		insertQuery := `
+			INSERT INTO partidos (torneo_id, categoria_id, jugador1_id, jugador2_id, fase, estado,
+			                     ronda, posicion_llave, siguiente_partido_id, siguiente_slot,
+			                     created_at, updated_at)
+			VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5, $6, $7, $8, $9, $10, NOW(), NOW())
+			RETURNING id`
+
+		ids := make([][]int, len(rounds))
+		for r := len(rounds) - 1; r >= 0; r-- {
+			ids[r] = make([]int, len(rounds[r]))
+			for p, match := range rounds[r] {
+				if match.Bye {
+					continue
+				}
+
+				var siguienteID, siguienteSlot sql.NullInt32
+				if r < len(rounds)-1 {
+					siguienteID = sql.NullInt32{Int32: int32(ids[r+1][p/2]), Valid: true}
+					siguienteSlot = sql.NullInt32{Int32: int32(p%2 + 1), Valid: true}
+				}
+
+				estado := models.EstadoPorDefinir
+				if match.Jugador1ID != 0 && match.Jugador2ID != 0 {
+					estado = models.EstadoPendiente
+				}
+
+				err := tx.QueryRow(insertQuery,
+					torneoID, categoriaID, match.Jugador1ID, match.Jugador2ID, match.Fase, estado,
+					match.Ronda, match.Posicion, siguienteID, siguienteSlot,
+				).Scan(&ids[r][p])
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetBracket(torneoID, categoriaID)
+}
+
+func (s *bracketServiceImpl) GetBracket(torneoID int, categoriaID int) ([]models.Partido, error) {
+	return queryPartidos(partidoSelectQuery+`
+		WHERE p.torneo_id = $1 AND p.categoria_id = $2 AND p.ronda IS NOT NULL
+		ORDER BY p.ronda, p.posicion_llave`, torneoID, categoriaID)
+}
@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrCampeonNotFound indica que el campeón no existe
+	ErrCampeonNotFound = errors.New("campeón no encontrado")
+	// ErrCampeonDuplicado indica que ya hay un campeón para ese torneo, categoría y año
+	ErrCampeonDuplicado = errors.New("ya hay un campeón registrado para ese torneo, categoría y año")
+	// ErrCampeonDerivado indica que la categoría tiene una final aprobada: su campeón
+	// sale del resultado y no se carga a mano
+	ErrCampeonDerivado = errors.New("el campeón de esa categoría surge de la final aprobada; se corrige desde el partido")
+	// ErrCampeonAnio indica que el año no coincide con el del torneo
+	ErrCampeonAnio = errors.New("el año no coincide con el del torneo")
+	// ErrCampeonSinJugador indica que no se indicó el jugador ni su nombre
+	ErrCampeonSinJugador = errors.New("debe indicarse el jugador o su nombre")
+	// ErrCampeonVinculo indica que el torneo, la categoría o el jugador no existen
+	ErrCampeonVinculo = errors.New("el torneo, la categoría o el jugador no existen")
+)
+
+// FiltroCampeones restringe el palmarés; los campos en cero no filtran
+type FiltroCampeones struct {
+	Anio        int
+	CategoriaID int
+	JugadorID   int
+}
+
+// ResultadoDerivacion resume una derivación de campeones desde las finales
+type ResultadoDerivacion struct {
+	Actualizados []models.Campeon `json:"actualizados"`
+	Eliminados   int              `json:"eliminados"`
+}
+
+type CampeonService interface {
+	GetCampeones(filtro FiltroCampeones) ([]models.Campeon, error)
+	GetTitulos(categoriaID, minimo int) ([]models.TitulosJugador, error)
+	GetCampeonByID(id int) (*models.Campeon, error)
+	CreateCampeon(campeon *models.Campeon) error
+	UpdateCampeon(id int, campeon *models.Campeon) error
+	DeleteCampeon(id int) error
+	DerivarCampeones(torneoID int) (*ResultadoDerivacion, error)
+}
+
+type campeonServiceImpl struct{}
+
+func NewCampeonService() CampeonService {
+	return &campeonServiceImpl{}
+}
+
+const campeonSelect = `
+	SELECT c.id, c.torneo_id, c.categoria_id, c.jugador_id,
+	       COALESCE(j.nombre || ' ' || j.apellido, c.jugador_nombre, ''), c.anio, c.created_at, c.updated_at,
+	       COALESCE(t.nombre, ''), COALESCE(cat.nombre, '')
+	FROM campeones c
+	LEFT JOIN jugadores j ON j.id = c.jugador_id
+	LEFT JOIN torneos t ON t.id = c.torneo_id
+	LEFT JOIN categorias cat ON cat.id = c.categoria_id`
+
+// finalAprobada es la condición sobre p de una final con ganador aprobado
+const finalAprobada = `p.fase = 'Final' AND p.siguiente_partido_id IS NULL
+	AND p.resultado_aprobado AND p.ganador_id IS NOT NULL`
+
+func scanCampeon(row rowScanner) (*models.Campeon, error) {
+	var c models.Campeon
+	err := row.Scan(&c.ID, &c.TorneoID, &c.CategoriaID, &c.JugadorID, &c.JugadorNombre, &c.Anio,
+		&c.CreatedAt, &c.UpdatedAt, &c.TorneoNombre, &c.CategoriaNombre)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func queryCampeones(where string, args ...interface{}) ([]models.Campeon, error) {
+	rows, err := database.DB.Query(campeonSelect+where+` ORDER BY c.anio DESC, cat.nombre, c.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campeones := []models.Campeon{}
+	for rows.Next() {
+		c, err := scanCampeon(rows)
+		if err != nil {
+			return nil, err
+		}
+		campeones = append(campeones, *c)
+	}
+
+	return campeones, rows.Err()
+}
+
+// GetCampeones lista el palmarés, del año más reciente al más antiguo
+func (s *campeonServiceImpl) GetCampeones(filtro FiltroCampeones) ([]models.Campeon, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	agregar := func(condicion string, valor interface{}) {
+		args = append(args, valor)
+		conditions = append(conditions, fmt.Sprintf(condicion, len(args)))
+	}
+
+	if filtro.Anio != 0 {
+		agregar("c.anio = $%d", filtro.Anio)
+	}
+	if filtro.CategoriaID != 0 {
+		agregar("c.categoria_id = $%d", filtro.CategoriaID)
+	}
+	if filtro.JugadorID != 0 {
+		agregar("c.jugador_id = $%d", filtro.JugadorID)
+	}
+	clause := ""
+	if len(conditions) > 0 {
+		clause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	return queryCampeones(clause, args...)
+}
+
+// GetTitulos cuenta los títulos de cada campeón con al menos minimo títulos, de más
+// a menos; los campeones históricos sin jugador registrado se agrupan por nombre
+func (s *campeonServiceImpl) GetTitulos(categoriaID, minimo int) ([]models.TitulosJugador, error) {
+	rows, err := database.DB.Query(`
+		SELECT c.jugador_id, COALESCE(j.nombre || ' ' || j.apellido, c.jugador_nombre) AS nombre,
+		       COUNT(*), ARRAY_AGG(c.anio ORDER BY c.anio)
+		FROM campeones c
+		LEFT JOIN jugadores j ON j.id = c.jugador_id
+		WHERE ($1 = 0 OR c.categoria_id = $1)
+		  AND (c.jugador_id IS NOT NULL OR c.jugador_nombre IS NOT NULL)
+		GROUP BY c.jugador_id, nombre
+		HAVING COUNT(*) >= $2
+		ORDER BY COUNT(*) DESC, MAX(c.anio) DESC, nombre`, categoriaID, minimo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titulos := []models.TitulosJugador{}
+	for rows.Next() {
+		var t models.TitulosJugador
+		var anios pq.Int64Array
+		if err := rows.Scan(&t.JugadorID, &t.JugadorNombre, &t.Titulos, &anios); err != nil {
+			return nil, err
+		}
+		t.Anios = enteros(anios)
+		titulos = append(titulos, t)
+	}
+
+	return titulos, rows.Err()
+}
+
+func (s *campeonServiceImpl) GetCampeonByID(id int) (*models.Campeon, error) {
+	c, err := scanCampeon(database.DB.QueryRow(campeonSelect+" WHERE c.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrCampeonNotFound
+	}
+	return c, err
+}
+
+// prepararCampeon valida un campeón cargado a mano: con torneo, el año es el del
+// torneo y la categoría no puede tener una final aprobada
+func prepararCampeon(tx *sql.Tx, campeon *models.Campeon) error {
+	if !campeon.JugadorID.Valid && campeon.JugadorNombre == "" {
+		return ErrCampeonSinJugador
+	}
+	if !campeon.TorneoID.Valid {
+		return nil
+	}
+
+	var anio int
+	err := tx.QueryRow(`SELECT anio FROM torneos WHERE id = $1`, campeon.TorneoID.Int32).Scan(&anio)
+	if err == sql.ErrNoRows {
+		return ErrCampeonVinculo
+	}
+	if err != nil {
+		return err
+	}
+	if campeon.Anio == 0 {
+		campeon.Anio = anio
+	} else if campeon.Anio != anio {
+		return ErrCampeonAnio
+	}
+
+	return verificarSinFinal(tx, int(campeon.TorneoID.Int32), campeon.CategoriaID)
+}
+
+// verificarSinFinal devuelve ErrCampeonDerivado si la categoría del torneo tiene una
+// final aprobada
+func verificarSinFinal(tx *sql.Tx, torneoID, categoriaID int) error {
+	var derivado bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM partidos p WHERE p.torneo_id = $1 AND p.categoria_id = $2 AND `+finalAprobada+`)`,
+		torneoID, categoriaID).Scan(&derivado)
+	if err != nil {
+		return err
+	}
+	if derivado {
+		return ErrCampeonDerivado
+	}
+	return nil
+}
+
+// errorGuardarCampeon traduce las violaciones de restricciones al guardar un campeón;
+// nil si err no es una de ellas
+func errorGuardarCampeon(err error) error {
+	switch {
+	case esViolacionUnica(err, "campeones_torneo_id_categoria_id_anio_key"),
+		esViolacionUnica(err, "idx_campeones_historicos"):
+		return ErrCampeonDuplicado
+	case esViolacionForanea(err):
+		return ErrCampeonVinculo
+	}
+	return nil
+}
+
+// jugadorNombreManual es el nombre que se guarda: solo si el campeón no es un jugador registrado
+func jugadorNombreManual(campeon *models.Campeon) sql.NullString {
+	if campeon.JugadorID.Valid {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: campeon.JugadorNombre, Valid: true}
+}
+
+// CreateCampeon registra un campeón histórico
+func (s *campeonServiceImpl) CreateCampeon(campeon *models.Campeon) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		if domainErr = prepararCampeon(tx, campeon); domainErr != nil {
+			return domainErr
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO campeones (torneo_id, categoria_id, jugador_id, jugador_nombre, anio, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+			RETURNING id`,
+			campeon.TorneoID, campeon.CategoriaID, campeon.JugadorID, jugadorNombreManual(campeon), campeon.Anio,
+		).Scan(&campeon.ID)
+		if domainErr = errorGuardarCampeon(err); domainErr != nil {
+			return domainErr
+		}
+		return err
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	if err != nil {
+		return err
+	}
+
+	creado, err := s.GetCampeonByID(campeon.ID)
+	if err != nil {
+		return err
+	}
+	*campeon = *creado
+	return nil
+}
+
+// UpdateCampeon corrige un campeón histórico; los derivados de una final no se editan
+func (s *campeonServiceImpl) UpdateCampeon(id int, campeon *models.Campeon) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		if domainErr = verificarCampeonManual(tx, id); domainErr != nil {
+			return domainErr
+		}
+		if domainErr = prepararCampeon(tx, campeon); domainErr != nil {
+			return domainErr
+		}
+
+		_, err := tx.Exec(`
+			UPDATE campeones
+			SET torneo_id = $1, categoria_id = $2, jugador_id = $3, jugador_nombre = $4, anio = $5, updated_at = NOW()
+			WHERE id = $6`,
+			campeon.TorneoID, campeon.CategoriaID, campeon.JugadorID, jugadorNombreManual(campeon), campeon.Anio, id,
+		)
+		if domainErr = errorGuardarCampeon(err); domainErr != nil {
+			return domainErr
+		}
+		return err
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	if err != nil {
+		return err
+	}
+
+	actualizado, err := s.GetCampeonByID(id)
+	if err != nil {
+		return err
+	}
+	*campeon = *actualizado
+	return nil
+}
+
+// DeleteCampeon elimina un campeón histórico; los derivados de una final no se eliminan
+func (s *campeonServiceImpl) DeleteCampeon(id int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		if domainErr = verificarCampeonManual(tx, id); domainErr != nil {
+			return domainErr
+		}
+
+		_, err := tx.Exec(`DELETE FROM campeones WHERE id = $1`, id)
+		return err
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+// verificarCampeonManual bloquea el campeón y comprueba que no surja de una final aprobada
+func verificarCampeonManual(tx *sql.Tx, id int) error {
+	var torneoID sql.NullInt32
+	var categoriaID sql.NullInt32
+	err := tx.QueryRow(`SELECT torneo_id, categoria_id FROM campeones WHERE id = $1 FOR UPDATE`, id).Scan(&torneoID, &categoriaID)
+	if err == sql.ErrNoRows {
+		return ErrCampeonNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !torneoID.Valid || !categoriaID.Valid {
+		return nil
+	}
+	return verificarSinFinal(tx, int(torneoID.Int32), int(categoriaID.Int32))
+}
+
+// DerivarCampeones vuelve a calcular los campeones a partir de las finales aprobadas,
+// de un torneo o de todos si torneoID es 0. Quita los campeones de las categorías
+// cuya final ya no tiene un resultado aprobado y reemplaza los que no coinciden con
+// el ganador. Los campeones de categorías sin final en el sistema no se tocan.
+func (s *campeonServiceImpl) DerivarCampeones(torneoID int) (*ResultadoDerivacion, error) {
+	resultado := &ResultadoDerivacion{Actualizados: []models.Campeon{}}
+	var ids pq.Int64Array
+
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		// Finales sin resultado aprobado, o campeones con un año distinto al del torneo
+		result, err := tx.Exec(`
+			DELETE FROM campeones c
+			USING torneos t
+			WHERE t.id = c.torneo_id
+			  AND ($1 = 0 OR c.torneo_id = $1)
+			  AND EXISTS (
+			      SELECT 1 FROM partidos p
+			      WHERE p.torneo_id = c.torneo_id AND p.categoria_id = c.categoria_id
+			        AND p.fase = 'Final' AND p.siguiente_partido_id IS NULL)
+			  AND (c.anio <> t.anio OR NOT EXISTS (
+			      SELECT 1 FROM partidos p
+			      WHERE p.torneo_id = c.torneo_id AND p.categoria_id = c.categoria_id AND `+finalAprobada+`))`,
+			torneoID)
+		if err != nil {
+			return err
+		}
+		eliminados, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		resultado.Eliminados = int(eliminados)
+
+		// Si una categoría tiene más de una final aprobada vale la última aprobada
+		return tx.QueryRow(`
+			WITH derivados AS (
+				INSERT INTO campeones (torneo_id, categoria_id, jugador_id, anio, created_at, updated_at)
+				SELECT DISTINCT ON (p.torneo_id, p.categoria_id) p.torneo_id, p.categoria_id, p.ganador_id, t.anio, NOW(), NOW()
+				FROM partidos p
+				JOIN torneos t ON t.id = p.torneo_id
+				WHERE ($1 = 0 OR p.torneo_id = $1) AND `+finalAprobada+`
+				ORDER BY p.torneo_id, p.categoria_id, p.updated_at DESC
+				ON CONFLICT (torneo_id, categoria_id, anio)
+				DO UPDATE SET jugador_id = EXCLUDED.jugador_id, jugador_nombre = NULL, updated_at = NOW()
+				WHERE campeones.jugador_id IS DISTINCT FROM EXCLUDED.jugador_id OR campeones.jugador_nombre IS NOT NULL
+				RETURNING id
+			)
+			SELECT COALESCE(ARRAY_AGG(id), '{}') FROM derivados`, torneoID).Scan(&ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) > 0 {
+		if resultado.Actualizados, err = queryCampeones(` WHERE c.id = ANY($1)`, ids); err != nil {
+			return nil, err
+		}
+	}
+	return resultado, nil
+}
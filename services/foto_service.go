@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrFotoNotFound indica que la foto no existe
+	ErrFotoNotFound = errors.New("foto no encontrada")
+	// ErrFotoVinculo indica que el torneo o el partido no existen, o que el partido
+	// es de otro torneo
+	ErrFotoVinculo = errors.New("el torneo o el partido no existen, o el partido es de otro torneo")
+)
+
+// FiltroFotos restringe la galería; los campos en cero no filtran
+type FiltroFotos struct {
+	TorneoID  int
+	PartidoID int
+}
+
+type FotoService interface {
+	GetFotos(params utils.PaginationParams, filtro FiltroFotos) ([]models.FotoDestacada, utils.PaginationInfo, error)
+	GetFotoByID(id int) (*models.FotoDestacada, error)
+	SubirFoto(ctx context.Context, foto *models.FotoDestacada, imagen *utils.ImagenProcesada) error
+	UpdateFoto(id int, foto *models.FotoDestacada) error
+	DeleteFoto(ctx context.Context, id int) error
+}
+
+type fotoServiceImpl struct {
+	storage Storage
+}
+
+func NewFotoService(storage Storage) FotoService {
+	return &fotoServiceImpl{storage: storage}
+}
+
+const fotoSelect = `
+	SELECT f.id, f.titulo, f.descripcion, f.url, f.partido_id, f.torneo_id, f.clave, f.tipo_mime,
+	       f.ancho, f.alto, f.tamano_bytes, f.subida_por, f.created_at, f.updated_at
+	FROM fotos_destacadas f`
+
+func scanFoto(row rowScanner) (*models.FotoDestacada, error) {
+	var f models.FotoDestacada
+	err := row.Scan(&f.ID, &f.Titulo, &f.Descripcion, &f.URL, &f.PartidoID, &f.TorneoID, &f.Clave, &f.TipoMIME,
+		&f.Ancho, &f.Alto, &f.TamanoBytes, &f.SubidaPor, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	f.Variantes = []models.FotoVariante{}
+	return &f, nil
+}
+
+// cargarVariantes completa las miniaturas de las fotos, de la más chica a la más grande
+func cargarVariantes(fotos []models.FotoDestacada) error {
+	if len(fotos) == 0 {
+		return nil
+	}
+	indices := make(map[int]int, len(fotos))
+	ids := make([]int64, len(fotos))
+	for i, f := range fotos {
+		indices[f.ID] = i
+		ids[i] = int64(f.ID)
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT foto_id, nombre, clave, url, ancho, alto
+		FROM foto_variantes
+		WHERE foto_id = ANY($1)
+		ORDER BY foto_id, ancho * alto`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fotoID int
+		var v models.FotoVariante
+		if err := rows.Scan(&fotoID, &v.Nombre, &v.Clave, &v.URL, &v.Ancho, &v.Alto); err != nil {
+			return err
+		}
+		f := &fotos[indices[fotoID]]
+		f.Variantes = append(f.Variantes, v)
+	}
+	return rows.Err()
+}
+
+// GetFotos lista la galería paginada, las más recientes primero. Filtrar por torneo
+// incluye las fotos de sus partidos.
+func (s *fotoServiceImpl) GetFotos(params utils.PaginationParams, filtro FiltroFotos) ([]models.FotoDestacada, utils.PaginationInfo, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	agregar := func(condicion string, valor interface{}) {
+		args = append(args, valor)
+		conditions = append(conditions, fmt.Sprintf(condicion, len(args)))
+	}
+
+	if filtro.TorneoID != 0 {
+		agregar("f.torneo_id = $%d", filtro.TorneoID)
+	}
+	if filtro.PartidoID != 0 {
+		agregar("f.partido_id = $%d", filtro.PartidoID)
+	}
+	if params.Search != "" {
+		args = append(args, "%"+params.Search+"%")
+		conditions = append(conditions, fmt.Sprintf("(f.titulo ILIKE $%d OR f.descripcion ILIKE $%d)", len(args), len(args)))
+	}
+	clause := ""
+	if len(conditions) > 0 {
+		clause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM fotos_destacadas f"+clause, args...).Scan(&total); err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+
+	query := fmt.Sprintf("%s%s ORDER BY f.created_at %s, f.id %s LIMIT $%d OFFSET $%d",
+		fotoSelect, clause, strings.ToUpper(params.Order), strings.ToUpper(params.Order), len(args)+1, len(args)+2)
+
+	rows, err := database.DB.Query(query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+	defer rows.Close()
+
+	fotos := []models.FotoDestacada{}
+	for rows.Next() {
+		f, err := scanFoto(rows)
+		if err != nil {
+			return nil, utils.PaginationInfo{}, err
+		}
+		fotos = append(fotos, *f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+
+	if err := cargarVariantes(fotos); err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+	return fotos, params.CalculatePaginationInfo(total), nil
+}
+
+func (s *fotoServiceImpl) GetFotoByID(id int) (*models.FotoDestacada, error) {
+	f, err := scanFoto(database.DB.QueryRow(fotoSelect+" WHERE f.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrFotoNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fotos := []models.FotoDestacada{*f}
+	if err := cargarVariantes(fotos); err != nil {
+		return nil, err
+	}
+	return &fotos[0], nil
+}
+
+// resolverTorneoFoto completa el torneo de una foto de partido con el del partido,
+// para que aparezca en la galería del torneo
+func resolverTorneoFoto(tx *sql.Tx, foto *models.FotoDestacada) error {
+	if !foto.PartidoID.Valid {
+		return nil
+	}
+
+	var torneoID int32
+	err := tx.QueryRow(`SELECT torneo_id FROM partidos WHERE id = $1`, foto.PartidoID.Int32).Scan(&torneoID)
+	if err == sql.ErrNoRows {
+		return ErrFotoVinculo
+	}
+	if err != nil {
+		return err
+	}
+	if foto.TorneoID.Valid && foto.TorneoID.Int32 != torneoID {
+		return ErrFotoVinculo
+	}
+	foto.TorneoID = sql.NullInt32{Int32: torneoID, Valid: true}
+	return nil
+}
+
+// claveFoto genera la clave base de una foto nueva, ej: "fotos/2026/05/3f9a..."
+func claveFoto() (string, error) {
+	aleatorio, err := utils.GenerateSessionID()
+	if err != nil {
+		return "", err
+	}
+	return "fotos/" + time.Now().Format("2006/01") + "/" + aleatorio, nil
+}
+
+// SubirFoto guarda la imagen procesada y sus miniaturas en el almacenamiento y
+// registra la foto. Si el registro falla, borra los archivos ya guardados.
+func (s *fotoServiceImpl) SubirFoto(ctx context.Context, foto *models.FotoDestacada, imagen *utils.ImagenProcesada) error {
+	base, err := claveFoto()
+	if err != nil {
+		return err
+	}
+
+	var guardadas []string
+	descartar := func() {
+		for _, clave := range guardadas {
+			if err := s.storage.Delete(context.Background(), clave); err != nil {
+				utils.LogError("No se pudo borrar un archivo de una foto descartada", err, logrus.Fields{"clave": clave})
+			}
+		}
+	}
+	guardar := func(clave string, contenido []byte) (string, error) {
+		url, err := s.storage.Put(ctx, clave, contenido, imagen.TipoMIME)
+		if err == nil {
+			guardadas = append(guardadas, clave)
+		}
+		return url, err
+	}
+
+	clave := base + "." + imagen.Extension
+	if foto.URL, err = guardar(clave, imagen.Original.Contenido); err != nil {
+		descartar()
+		return err
+	}
+	foto.Clave = sql.NullString{String: clave, Valid: true}
+	foto.TipoMIME = imagen.TipoMIME
+	foto.Ancho, foto.Alto = imagen.Original.Ancho, imagen.Original.Alto
+	foto.TamanoBytes = int64(len(imagen.Original.Contenido))
+
+	foto.Variantes = []models.FotoVariante{}
+	for _, v := range imagen.Variantes {
+		variante := models.FotoVariante{
+			Nombre: v.Nombre,
+			Clave:  base + "_" + v.Nombre + "." + imagen.Extension,
+			Ancho:  v.Ancho,
+			Alto:   v.Alto,
+		}
+		if variante.URL, err = guardar(variante.Clave, v.Contenido); err != nil {
+			descartar()
+			return err
+		}
+		foto.Variantes = append(foto.Variantes, variante)
+	}
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err = txManager.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if domainErr = resolverTorneoFoto(tx, foto); domainErr != nil {
+			return domainErr
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO fotos_destacadas (titulo, descripcion, url, partido_id, torneo_id, clave, tipo_mime,
+			                              ancho, alto, tamano_bytes, subida_por, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
+			RETURNING id, created_at, updated_at`,
+			foto.Titulo, foto.Descripcion, foto.URL, foto.PartidoID, foto.TorneoID, foto.Clave, foto.TipoMIME,
+			foto.Ancho, foto.Alto, foto.TamanoBytes, foto.SubidaPor,
+		).Scan(&foto.ID, &foto.CreatedAt, &foto.UpdatedAt)
+		if esViolacionForanea(err) {
+			domainErr = ErrFotoVinculo
+			return domainErr
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, v := range foto.Variantes {
+			_, err := tx.Exec(`
+				INSERT INTO foto_variantes (foto_id, nombre, clave, url, ancho, alto)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				foto.ID, v.Nombre, v.Clave, v.URL, v.Ancho, v.Alto)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if domainErr != nil {
+		err = domainErr
+	}
+	if err != nil {
+		descartar()
+		return err
+	}
+	return nil
+}
+
+// UpdateFoto cambia el título, la descripción y a qué torneo o partido pertenece la foto
+func (s *fotoServiceImpl) UpdateFoto(id int, foto *models.FotoDestacada) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		if domainErr = resolverTorneoFoto(tx, foto); domainErr != nil {
+			return domainErr
+		}
+
+		result, err := tx.Exec(`
+			UPDATE fotos_destacadas
+			SET titulo = $1, descripcion = $2, partido_id = $3, torneo_id = $4, updated_at = NOW()
+			WHERE id = $5`,
+			foto.Titulo, foto.Descripcion, foto.PartidoID, foto.TorneoID, id)
+		if esViolacionForanea(err) {
+			domainErr = ErrFotoVinculo
+			return domainErr
+		}
+		if err != nil {
+			return err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			domainErr = ErrFotoNotFound
+			return domainErr
+		}
+		return nil
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+// DeleteFoto elimina la foto y después sus archivos. Un archivo que no se puede
+// borrar queda huérfano en el almacenamiento pero no impide la baja.
+func (s *fotoServiceImpl) DeleteFoto(ctx context.Context, id int) error {
+	foto, err := s.GetFotoByID(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := database.DB.Exec(`DELETE FROM fotos_destacadas WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrFotoNotFound
+	}
+
+	claves := []string{}
+	if foto.Clave.Valid {
+		claves = append(claves, foto.Clave.String)
+	}
+	for _, v := range foto.Variantes {
+		claves = append(claves, v.Clave)
+	}
+	for _, clave := range claves {
+		if err := s.storage.Delete(ctx, clave); err != nil {
+			utils.LogError("No se pudo borrar un archivo de una foto eliminada", err, logrus.Fields{"foto_id": id, "clave": clave})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrGrupoNotFound indica que el grupo solicitado no existe
+	ErrGrupoNotFound = errors.New("grupo no encontrado")
+	// ErrInvalidGrupo indica que los datos del grupo no son válidos
+	ErrInvalidGrupo = errors.New("datos de grupo inválidos")
+	// ErrFixtureExists indica que el grupo ya tiene partidos generados
+	ErrFixtureExists = errors.New("el grupo ya tiene su fixture generado")
+	// ErrGrupoDuplicado indica que la categoría del torneo ya tiene un grupo con ese nombre
+	ErrGrupoDuplicado = errors.New("ya existe un grupo con ese nombre en la categoría del torneo")
+)
+
+// DefaultCriteriosDesempate es el orden de desempate usado si el grupo no define uno
+var DefaultCriteriosDesempate = []string{
+	models.DesempateEnfrentamientoDirecto,
+	models.DesempateDiferenciaSets,
+	models.DesempateDiferenciaGames,
+}
+
+// ResultadoGrupo resume un partido con resultado aprobado para calcular posiciones
+type ResultadoGrupo struct {
+	Jugador1ID int
+	Jugador2ID int
+	GanadorID  int
+	SetsJ1     int
+	SetsJ2     int
+	GamesJ1    int
+	GamesJ2    int
+}
+
+type GrupoService interface {
+	GetGrupos(torneoID int, categoriaID int) ([]models.Grupo, error)
+	GetGrupoByID(id int) (*models.Grupo, error)
+	CreateGrupo(grupo *models.Grupo, jugadorIDs []int) error
+	GenerateFixture(grupoID int) ([]models.Partido, error)
+	GetStandings(grupoID int) ([]models.PosicionGrupo, error)
+}
+
+type grupoServiceImpl struct{}
+
+func NewGrupoService() GrupoService {
+	return &grupoServiceImpl{}
+}
+
+// RoundRobinSchedule arma las jornadas de un todos contra todos con el método del
+// círculo: el primer jugador queda fijo y el resto rota una posición por jornada.
+// Con una cantidad impar de jugadores, cada jornada uno de ellos queda libre.
+func RoundRobinSchedule(jugadorIDs []int) [][][2]int {
+	players := append([]int(nil), jugadorIDs...)
+	if len(players)%2 == 1 {
+		players = append(players, 0) // 0 = jornada libre
+	}
+
+	n := len(players)
+	if n < 2 {
+		return nil
+	}
+
+	jornadas := make([][][2]int, 0, n-1)
+	for r := 0; r < n-1; r++ {
+		var cruces [][2]int
+		for i := 0; i < n/2; i++ {
+			a, b := players[i], players[n-1-i]
+			if a == 0 || b == 0 {
+				continue
+			}
+			// Alternar el lado del jugador fijo para repartir la condición de jugador 1
+			if i == 0 && r%2 == 1 {
+				a, b = b, a
+			}
+			cruces = append(cruces, [2]int{a, b})
+		}
+		jornadas = append(jornadas, cruces)
+
+		last := players[n-1]
+		copy(players[2:], players[1:n-1])
+		players[1] = last
+	}
+
+	return jornadas
+}
+
+// ValidateCriteriosDesempate verifica que los criterios sean conocidos y no se repitan
+func ValidateCriteriosDesempate(criterios []string) error {
+	seen := make(map[string]bool, len(criterios))
+	for _, c := range criterios {
+		switch c {
+		case models.DesempateEnfrentamientoDirecto, models.DesempateDiferenciaSets, models.DesempateDiferenciaGames:
+		default:
+			return fmt.Errorf("%w: criterio de desempate desconocido %q", ErrInvalidGrupo, c)
+		}
+		if seen[c] {
+			return fmt.Errorf("%w: criterio de desempate repetido %q", ErrInvalidGrupo, c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// ComputeStandings calcula la tabla de posiciones de un grupo. Los jugadores se
+// ordenan por partidos ganados y los empates se resuelven aplicando los criterios
+// en el orden indicado; si persisten, se ordena por ID de jugador.
+func ComputeStandings(jugadorIDs []int, resultados []ResultadoGrupo, criterios []string) []models.PosicionGrupo {
+	filas := make(map[int]*models.PosicionGrupo, len(jugadorIDs))
+	tabla := make([]models.PosicionGrupo, 0, len(jugadorIDs))
+	for _, id := range jugadorIDs {
+		tabla = append(tabla, models.PosicionGrupo{JugadorID: id})
+	}
+	for i := range tabla {
+		filas[tabla[i].JugadorID] = &tabla[i]
+	}
+
+	for _, res := range resultados {
+		j1, ok1 := filas[res.Jugador1ID]
+		j2, ok2 := filas[res.Jugador2ID]
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		j1.Jugados++
+		j2.Jugados++
+		if res.GanadorID == res.Jugador1ID {
+			j1.Ganados++
+			j2.Perdidos++
+		} else {
+			j2.Ganados++
+			j1.Perdidos++
+		}
+
+		j1.SetsFavor += res.SetsJ1
+		j1.SetsContra += res.SetsJ2
+		j2.SetsFavor += res.SetsJ2
+		j2.SetsContra += res.SetsJ1
+		j1.GamesFavor += res.GamesJ1
+		j1.GamesContra += res.GamesJ2
+		j2.GamesFavor += res.GamesJ2
+		j2.GamesContra += res.GamesJ1
+	}
+
+	sort.SliceStable(tabla, func(a, b int) bool {
+		if tabla[a].Ganados != tabla[b].Ganados {
+			return tabla[a].Ganados > tabla[b].Ganados
+		}
+		return tabla[a].JugadorID < tabla[b].JugadorID
+	})
+
+	// Resolver cada bloque de jugadores empatados en partidos ganados
+	for start := 0; start < len(tabla); {
+		end := start + 1
+		for end < len(tabla) && tabla[end].Ganados == tabla[start].Ganados {
+			end++
+		}
+		breakTies(tabla[start:end], resultados, criterios)
+		start = end
+	}
+
+	for i := range tabla {
+		tabla[i].Posicion = i + 1
+	}
+
+	return tabla
+}
+
+// breakTies ordena un bloque de jugadores empatados aplicando el primer criterio y,
+// para los que sigan empatados, los criterios restantes
+func breakTies(bloque []models.PosicionGrupo, resultados []ResultadoGrupo, criterios []string) {
+	if len(bloque) < 2 || len(criterios) == 0 {
+		return
+	}
+
+	valores := make(map[int]int, len(bloque))
+	switch criterios[0] {
+	case models.DesempateEnfrentamientoDirecto:
+		// Partidos ganados solo entre los jugadores empatados
+		enBloque := make(map[int]bool, len(bloque))
+		for _, fila := range bloque {
+			enBloque[fila.JugadorID] = true
+		}
+		for _, res := range resultados {
+			if enBloque[res.Jugador1ID] && enBloque[res.Jugador2ID] {
+				valores[res.GanadorID]++
+			}
+		}
+	case models.DesempateDiferenciaSets:
+		for _, fila := range bloque {
+			valores[fila.JugadorID] = fila.SetsFavor - fila.SetsContra
+		}
+	case models.DesempateDiferenciaGames:
+		for _, fila := range bloque {
+			valores[fila.JugadorID] = fila.GamesFavor - fila.GamesContra
+		}
+	}
+
+	sort.SliceStable(bloque, func(a, b int) bool {
+		return valores[bloque[a].JugadorID] > valores[bloque[b].JugadorID]
+	})
+
+	for start := 0; start < len(bloque); {
+		end := start + 1
+		for end < len(bloque) && valores[bloque[end].JugadorID] == valores[bloque[start].JugadorID] {
+			end++
+		}
+		breakTies(bloque[start:end], resultados, criterios[1:])
+		start = end
+	}
+}
+
+func (s *grupoServiceImpl) GetGrupos(torneoID int, categoriaID int) ([]models.Grupo, error) {
+	query := `
+		SELECT id, torneo_id, categoria_id, nombre, criterios_desempate, created_at, updated_at
+		FROM grupos
+		WHERE torneo_id = $1 AND categoria_id = $2
+		ORDER BY nombre`
+
+	rows, err := database.DB.Query(query, torneoID, categoriaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grupos []models.Grupo
+	for rows.Next() {
+		var g models.Grupo
+		err := rows.Scan(
+			&g.ID, &g.TorneoID, &g.CategoriaID, &g.Nombre, pq.Array(&g.CriteriosDesempate),
+			&g.CreatedAt, &g.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		grupos = append(grupos, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range grupos {
+		grupos[i].Jugadores, err = s.getJugadores(grupos[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return grupos, nil
+}
+
+func (s *grupoServiceImpl) GetGrupoByID(id int) (*models.Grupo, error) {
+	query := `
+		SELECT id, torneo_id, categoria_id, nombre, criterios_desempate, created_at, updated_at
+		FROM grupos
+		WHERE id = $1`
+
+	var grupo models.Grupo
+	err := database.DB.QueryRow(query, id).Scan(
+		&grupo.ID, &grupo.TorneoID, &grupo.CategoriaID, &grupo.Nombre, pq.Array(&grupo.CriteriosDesempate),
+		&grupo.CreatedAt, &grupo.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGrupoNotFound
+		}
+		return nil, err
+	}
+
+	grupo.Jugadores, err = s.getJugadores(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grupo, nil
+}
+
+// getJugadores obtiene los integrantes de un grupo
+func (s *grupoServiceImpl) getJugadores(grupoID int) ([]models.GrupoJugador, error) {
+	query := `
+		SELECT gj.grupo_id, gj.jugador_id, j.nombre || ' ' || j.apellido
+		FROM grupo_jugadores gj
+		JOIN jugadores j ON gj.jugador_id = j.id
+		WHERE gj.grupo_id = $1
+		ORDER BY gj.created_at, gj.jugador_id`
+
+	rows, err := database.DB.Query(query, grupoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jugadores []models.GrupoJugador
+	for rows.Next() {
+		var gj models.GrupoJugador
+		if err := rows.Scan(&gj.GrupoID, &gj.JugadorID, &gj.JugadorNombre); err != nil {
+			return nil, err
+		}
+		jugadores = append(jugadores, gj)
+	}
+
+	return jugadores, rows.Err()
+}
+
+func (s *grupoServiceImpl) CreateGrupo(grupo *models.Grupo, jugadorIDs []int) error {
+	if grupo.Nombre == "" {
+		return fmt.Errorf("%w: el nombre es requerido", ErrInvalidGrupo)
+	}
+	if len(jugadorIDs) < 2 {
+		return fmt.Errorf("%w: se requieren al menos 2 jugadores", ErrInvalidGrupo)
+	}
+	seen := make(map[int]bool, len(jugadorIDs))
+	for _, id := range jugadorIDs {
+		if seen[id] {
+			return fmt.Errorf("%w: el jugador %d está repetido", ErrInvalidGrupo, id)
+		}
+		seen[id] = true
+	}
+
+	if len(grupo.CriteriosDesempate) == 0 {
+		grupo.CriteriosDesempate = DefaultCriteriosDesempate
+	}
+	if err := ValidateCriteriosDesempate(grupo.CriteriosDesempate); err != nil {
+		return err
+	}
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO grupos (torneo_id, categoria_id, nombre, criterios_desempate, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, NOW(), NOW())
+			RETURNING id, created_at, updated_at`
+
+		err := tx.QueryRow(query,
+			grupo.TorneoID, grupo.CategoriaID, grupo.Nombre, pq.Array(grupo.CriteriosDesempate),
+		).Scan(&grupo.ID, &grupo.CreatedAt, &grupo.UpdatedAt)
+		if esViolacionUnica(err, "grupos_torneo_id_categoria_id_nombre_key") {
+			domainErr = ErrGrupoDuplicado
+		} else if esViolacionForanea(err) {
+			domainErr = fmt.Errorf("%w: el torneo o la categoría no existe", ErrInvalidGrupo)
+		}
+		if err != nil {
+			return err
+		}
+
+		grupo.Jugadores = make([]models.GrupoJugador, 0, len(jugadorIDs))
+		for _, jugadorID := range jugadorIDs {
+			_, err := tx.Exec(
+				`INSERT INTO grupo_jugadores (grupo_id, jugador_id, created_at) VALUES ($1, $2, NOW())`,
+				grupo.ID, jugadorID,
+			)
+			if esViolacionForanea(err) {
+				domainErr = fmt.Errorf("%w: el jugador %d no existe", ErrInvalidGrupo, jugadorID)
+			}
+			if err != nil {
+				return err
+			}
+			grupo.Jugadores = append(grupo.Jugadores, models.GrupoJugador{GrupoID: grupo.ID, JugadorID: jugadorID})
+		}
+
+		return nil
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+
+	return err
+}
+
+func (s *grupoServiceImpl) GenerateFixture(grupoID int) ([]models.Partido, error) {
+	grupo, err := s.GetGrupoByID(grupoID)
+	if err != nil {
+		return nil, err
+	}
+
+	jugadorIDs := make([]int, 0, len(grupo.Jugadores))
+	for _, gj := range grupo.Jugadores {
+		jugadorIDs = append(jugadorIDs, gj.JugadorID)
+	}
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err = txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		var existing int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM partidos WHERE grupo_id = $1`, grupoID).Scan(&existing); err != nil {
+			return err
+		}
+		if existing > 0 {
+			domainErr = ErrFixtureExists
+			return domainErr
+		}
+
+		insertQuery := `
+			INSERT INTO partidos (torneo_id, categoria_id, jugador1_id, jugador2_id, fase, estado,
+			                     grupo_id, jornada, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())`
+
+		for i, jornada := range RoundRobinSchedule(jugadorIDs) {
+			for _, cruce := range jornada {
+				_, err := tx.Exec(insertQuery,
+					grupo.TorneoID, grupo.CategoriaID, cruce[0], cruce[1], models.FaseGrupos,
+					models.EstadoPendiente, grupoID, i+1,
+				)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return queryPartidos(partidoSelectQuery+`
+		WHERE p.grupo_id = $1
+		ORDER BY p.jornada, p.id`, grupoID)
+}
+
+func (s *grupoServiceImpl) GetStandings(grupoID int) ([]models.PosicionGrupo, error) {
+	grupo, err := s.GetGrupoByID(grupoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Solo cuentan los resultados aprobados; los games salen de sets_partido
+	query := `
+		SELECT p.jugador1_id, p.jugador2_id, p.ganador_id,
+		       COALESCE(p.resultado_sets_j1, 0), COALESCE(p.resultado_sets_j2, 0),
+		       COALESCE(SUM(s.score_jugador1), 0), COALESCE(SUM(s.score_jugador2), 0)
+		FROM partidos p
+		LEFT JOIN sets_partido s ON s.partido_id = p.id
+		WHERE p.grupo_id = $1 AND p.resultado_aprobado = true AND p.ganador_id IS NOT NULL
+		GROUP BY p.id`
+
+	rows, err := database.DB.Query(query, grupoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resultados []ResultadoGrupo
+	for rows.Next() {
+		var res ResultadoGrupo
+		err := rows.Scan(
+			&res.Jugador1ID, &res.Jugador2ID, &res.GanadorID,
+			&res.SetsJ1, &res.SetsJ2, &res.GamesJ1, &res.GamesJ2,
+		)
+		if err != nil {
+			return nil, err
+		}
+		resultados = append(resultados, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	jugadorIDs := make([]int, 0, len(grupo.Jugadores))
+	nombres := make(map[int]string, len(grupo.Jugadores))
+	for _, gj := range grupo.Jugadores {
+		jugadorIDs = append(jugadorIDs, gj.JugadorID)
+		nombres[gj.JugadorID] = gj.JugadorNombre
+	}
+
+	criterios := grupo.CriteriosDesempate
+	if len(criterios) == 0 {
+		criterios = DefaultCriteriosDesempate
+	}
+
+	tabla := ComputeStandings(jugadorIDs, resultados, criterios)
+	for i := range tabla {
+		tabla[i].JugadorNombre = nombres[tabla[i].JugadorID]
+	}
+
+	return tabla, nil
+}
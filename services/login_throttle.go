@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"copa-litoral-backend/config"
+	"copa-litoral-backend/utils"
+)
+
+// LoginBloqueadoError indica que el login está bloqueado temporalmente por
+// demasiados intentos fallidos
+type LoginBloqueadoError struct {
+	Espera time.Duration
+}
+
+func (e *LoginBloqueadoError) Error() string {
+	return fmt.Sprintf("demasiados intentos fallidos; reintentá en %d segundos", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds redondea la espera hacia arriba para el header Retry-After
+func (e *LoginBloqueadoError) RetryAfterSeconds() int {
+	return int((e.Espera + time.Second - 1) / time.Second)
+}
+
+// intentosFallidos es el registro de fallos de un nombre de usuario o de una IP
+type intentosFallidos struct {
+	fallos         int
+	bloqueadoHasta time.Time
+	ultimoFallo    time.Time
+}
+
+// LoginThrottler cuenta los logins fallidos por nombre de usuario y por IP. Superado
+// el umbral, cada fallo bloquea la clave por un tiempo que se duplica hasta el máximo.
+// Los nombres de usuario inexistentes se tratan igual que los existentes.
+type LoginThrottler struct {
+	mu            sync.Mutex
+	intentos      map[string]*intentosFallidos
+	maxPorUsuario int
+	maxPorIP      int
+	bloqueoBase   time.Duration
+	bloqueoMax    time.Duration
+}
+
+func NewLoginThrottler(cfg *config.Config) *LoginThrottler {
+	t := &LoginThrottler{
+		intentos:      make(map[string]*intentosFallidos),
+		maxPorUsuario: cfg.LoginMaxIntentos,
+		maxPorIP:      cfg.LoginMaxIntentosIP,
+		bloqueoBase:   time.Duration(cfg.LoginBloqueoBase) * time.Second,
+		bloqueoMax:    time.Duration(cfg.LoginBloqueoMax) * time.Minute,
+	}
+
+	go t.cleanupRoutine()
+
+	return t
+}
+
+func claveUsuario(username string) string {
+	return "usuario:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func claveIP(ip string) string {
+	return "ip:" + ip
+}
+
+// Check devuelve un *LoginBloqueadoError si el usuario o la IP están bloqueados
+func (t *LoginThrottler) Check(username, ip string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ahora := time.Now()
+	var espera time.Duration
+	for _, clave := range []string{claveUsuario(username), claveIP(ip)} {
+		if registro := t.vigente(clave, ahora); registro != nil && registro.bloqueadoHasta.After(ahora) {
+			if restante := registro.bloqueadoHasta.Sub(ahora); restante > espera {
+				espera = restante
+			}
+		}
+	}
+
+	if espera > 0 {
+		return &LoginBloqueadoError{Espera: espera}
+	}
+	return nil
+}
+
+// RegistrarFallo suma un fallo al usuario y a la IP y aplica el bloqueo que corresponda
+func (t *LoginThrottler) RegistrarFallo(username, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ahora := time.Now()
+	t.fallar(claveUsuario(username), "usuario", t.maxPorUsuario, ahora)
+	t.fallar(claveIP(ip), "ip", t.maxPorIP, ahora)
+}
+
+// RegistrarExito limpia los fallos del usuario; los de la IP se mantienen para no
+// premiar a quien prueba muchas cuentas desde la misma dirección
+func (t *LoginThrottler) RegistrarExito(username string) {
+	t.Unlock(username)
+}
+
+// Unlock levanta el bloqueo de un nombre de usuario
+func (t *LoginThrottler) Unlock(username string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.intentos, claveUsuario(username))
+}
+
+func (t *LoginThrottler) fallar(clave, alcance string, max int, ahora time.Time) {
+	registro := t.vigente(clave, ahora)
+	if registro == nil {
+		registro = &intentosFallidos{}
+		t.intentos[clave] = registro
+	}
+	registro.fallos++
+	registro.ultimoFallo = ahora
+
+	if registro.fallos < max {
+		return
+	}
+
+	// Backoff exponencial: base, 2x base, 4x base... hasta el máximo
+	espera := t.bloqueoBase
+	for i := max; i < registro.fallos && espera < t.bloqueoMax; i++ {
+		espera *= 2
+	}
+	if espera > t.bloqueoMax {
+		espera = t.bloqueoMax
+	}
+	registro.bloqueadoHasta = ahora.Add(espera)
+	utils.RecordAuthLockout(alcance)
+}
+
+// vigente devuelve el registro de la clave, descartándolo si pasó el bloqueo
+// máximo desde el último fallo
+func (t *LoginThrottler) vigente(clave string, ahora time.Time) *intentosFallidos {
+	registro, ok := t.intentos[clave]
+	if !ok {
+		return nil
+	}
+	if ahora.Sub(registro.ultimoFallo) > t.bloqueoMax && !registro.bloqueadoHasta.After(ahora) {
+		delete(t.intentos, clave)
+		return nil
+	}
+	return registro
+}
+
+// cleanupRoutine descarta periódicamente los registros vencidos
+func (t *LoginThrottler) cleanupRoutine() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		t.mu.Lock()
+		ahora := time.Now()
+		for clave := range t.intentos {
+			t.vigente(clave, ahora)
+		}
+		t.mu.Unlock()
+	}
+}
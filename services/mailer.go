@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"sync"
+
+	"copa-litoral-backend/config"
+)
+
+// Mailer envía correos de texto plano
+type Mailer interface {
+	SendEmail(to, subject, body string) error
+}
+
+// NewMailer elige la implementación según la configuración: SMTP si hay un
+// servidor configurado, en memoria en caso contrario
+func NewMailer(cfg *config.Config) Mailer {
+	if cfg.EmailSMTPHost == "" {
+		return NewMemoryMailer()
+	}
+	return NewSMTPMailer(cfg)
+}
+
+// SMTPMailer envía los correos a través de un servidor SMTP
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg *config.Config) *SMTPMailer {
+	mailer := &SMTPMailer{
+		addr: cfg.EmailSMTPHost + ":" + cfg.EmailSMTPPort,
+		from: cfg.EmailFrom,
+	}
+	if cfg.EmailUsername != "" {
+		mailer.auth = smtp.PlainAuth("", cfg.EmailUsername, cfg.EmailPassword, cfg.EmailSMTPHost)
+	}
+	return mailer
+}
+
+func (m *SMTPMailer) SendEmail(to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("destinatario inválido")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + m.from + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg.String()))
+}
+
+// SentEmail es un correo retenido por MemoryMailer
+type SentEmail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MemoryMailer guarda los correos en memoria en lugar de enviarlos; sirve para
+// desarrollo y para probar los flujos sin servidor SMTP
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []SentEmail
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{sent: make([]SentEmail, 0)}
+}
+
+func (m *MemoryMailer) SendEmail(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = append(m.sent, SentEmail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// GetSentEmails devuelve una copia de los correos retenidos
+func (m *MemoryMailer) GetSentEmails() []SentEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]SentEmail(nil), m.sent...)
+}
+
+// ClearSentEmails descarta los correos retenidos
+func (m *MemoryMailer) ClearSentEmails() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = make([]SentEmail, 0)
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNoticiaNotFound indica que la noticia no existe o todavía no se publicó
+	ErrNoticiaNotFound = errors.New("noticia no encontrada")
+	// ErrNoticiaEstado indica un estado fuera de borrador, publicada y archivada
+	ErrNoticiaEstado = errors.New("estado de noticia inválido")
+	// ErrNoticiaSlugEnUso indica que el slug indicado pertenece a otra noticia
+	ErrNoticiaSlugEnUso = errors.New("el slug ya está en uso")
+	// ErrNoticiaSlugInvalido indica un título o slug del que no se puede armar una URL
+	ErrNoticiaSlugInvalido = errors.New("el slug debe tener letras o números")
+	// ErrNoticiaVinculo indica que alguno de los torneos, partidos o jugadores vinculados no existe
+	ErrNoticiaVinculo = errors.New("alguno de los torneos, partidos o jugadores vinculados no existe")
+)
+
+// FiltroNoticias limita los listados de noticias; los campos en cero no filtran.
+// Publicadas deja solo las noticias visibles en la web.
+type FiltroNoticias struct {
+	Publicadas bool
+	Estado     string
+	TorneoID   int
+	PartidoID  int
+	JugadorID  int
+}
+
+type NoticiaService interface {
+	GetNoticias(params utils.PaginationParams, filtro FiltroNoticias) ([]models.Noticia, utils.PaginationInfo, error)
+	GetNoticiaByID(id int) (*models.Noticia, error)
+	GetNoticiaPublicadaBySlug(slug string) (*models.Noticia, error)
+	CreateNoticia(noticia *models.Noticia) error
+	UpdateNoticia(id int, noticia *models.Noticia) error
+	DeleteNoticia(id int) error
+}
+
+type noticiaServiceImpl struct{}
+
+func NewNoticiaService() NoticiaService {
+	return &noticiaServiceImpl{}
+}
+
+const noticiaSelect = `
+	SELECT n.id, n.titulo, n.slug, n.contenido, n.estado, n.fecha_publicacion, n.autor_id,
+	       n.imagen_destacada_url, n.created_at, n.updated_at,
+	       ARRAY(SELECT torneo_id FROM noticia_torneos WHERE noticia_id = n.id ORDER BY torneo_id),
+	       ARRAY(SELECT partido_id FROM noticia_partidos WHERE noticia_id = n.id ORDER BY partido_id),
+	       ARRAY(SELECT jugador_id FROM noticia_jugadores WHERE noticia_id = n.id ORDER BY jugador_id)
+	FROM noticias n`
+
+// noticiaPublicada es la condición de las noticias visibles en la web: publicadas
+// y con la fecha de publicación cumplida
+const noticiaPublicada = `n.estado = 'publicada' AND n.fecha_publicacion <= NOW()`
+
+// noticiaColumnas son las columnas por las que se puede ordenar el listado
+var noticiaColumnas = map[string]bool{
+	"id": true, "titulo": true, "fecha_publicacion": true, "created_at": true, "updated_at": true,
+}
+
+func scanNoticia(row rowScanner) (*models.Noticia, error) {
+	var n models.Noticia
+	var torneos, partidos, jugadores pq.Int64Array
+	err := row.Scan(&n.ID, &n.Titulo, &n.Slug, &n.Contenido, &n.Estado, &n.FechaPublicacion, &n.AutorID,
+		&n.ImagenDestacadaURL, &n.CreatedAt, &n.UpdatedAt, &torneos, &partidos, &jugadores)
+	if err != nil {
+		return nil, err
+	}
+	n.TorneoIDs = enteros(torneos)
+	n.PartidoIDs = enteros(partidos)
+	n.JugadorIDs = enteros(jugadores)
+	return &n, nil
+}
+
+func enteros(valores pq.Int64Array) []int {
+	resultado := make([]int, len(valores))
+	for i, v := range valores {
+		resultado[i] = int(v)
+	}
+	return resultado
+}
+
+// GetNoticias lista las noticias paginadas, por defecto las más recientes primero;
+// search busca en el título y el contenido
+func (s *noticiaServiceImpl) GetNoticias(params utils.PaginationParams, filtro FiltroNoticias) ([]models.Noticia, utils.PaginationInfo, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	agregar := func(condicion string, valor interface{}) {
+		args = append(args, valor)
+		conditions = append(conditions, fmt.Sprintf(condicion, len(args)))
+	}
+
+	if filtro.Publicadas {
+		conditions = append(conditions, noticiaPublicada)
+	}
+	if filtro.Estado != "" {
+		agregar("n.estado = $%d", filtro.Estado)
+	}
+	if filtro.TorneoID != 0 {
+		agregar("EXISTS (SELECT 1 FROM noticia_torneos v WHERE v.noticia_id = n.id AND v.torneo_id = $%d)", filtro.TorneoID)
+	}
+	if filtro.PartidoID != 0 {
+		agregar("EXISTS (SELECT 1 FROM noticia_partidos v WHERE v.noticia_id = n.id AND v.partido_id = $%d)", filtro.PartidoID)
+	}
+	if filtro.JugadorID != 0 {
+		agregar("EXISTS (SELECT 1 FROM noticia_jugadores v WHERE v.noticia_id = n.id AND v.jugador_id = $%d)", filtro.JugadorID)
+	}
+	if params.Search != "" {
+		args = append(args, "%"+params.Search+"%")
+		conditions = append(conditions, fmt.Sprintf("(n.titulo ILIKE $%d OR n.contenido ILIKE $%d)", len(args), len(args)))
+	}
+	clause := ""
+	if len(conditions) > 0 {
+		clause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM noticias n"+clause, args...).Scan(&total); err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+
+	sort := params.Sort
+	if !noticiaColumnas[sort] {
+		sort = "fecha_publicacion"
+	}
+	query := fmt.Sprintf("%s%s ORDER BY n.%s %s NULLS LAST, n.id DESC LIMIT $%d OFFSET $%d",
+		noticiaSelect, clause, sort, strings.ToUpper(params.Order), len(args)+1, len(args)+2)
+
+	rows, err := database.DB.Query(query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+	defer rows.Close()
+
+	noticias := []models.Noticia{}
+	for rows.Next() {
+		n, err := scanNoticia(rows)
+		if err != nil {
+			return nil, utils.PaginationInfo{}, err
+		}
+		noticias = append(noticias, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+
+	return noticias, params.CalculatePaginationInfo(total), nil
+}
+
+func (s *noticiaServiceImpl) GetNoticiaByID(id int) (*models.Noticia, error) {
+	n, err := scanNoticia(database.DB.QueryRow(noticiaSelect+" WHERE n.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNoticiaNotFound
+	}
+	return n, err
+}
+
+// GetNoticiaPublicadaBySlug busca una noticia visible en la web; los borradores,
+// las archivadas y las programadas responden como inexistentes
+func (s *noticiaServiceImpl) GetNoticiaPublicadaBySlug(slug string) (*models.Noticia, error) {
+	n, err := scanNoticia(database.DB.QueryRow(noticiaSelect+" WHERE n.slug = $1 AND "+noticiaPublicada, slug))
+	if err == sql.ErrNoRows {
+		return nil, ErrNoticiaNotFound
+	}
+	return n, err
+}
+
+// prepararNoticia valida el estado y, al publicar sin fecha, publica en el momento
+func prepararNoticia(noticia *models.Noticia) error {
+	if noticia.Estado == "" {
+		noticia.Estado = models.NoticiaBorrador
+	}
+	switch noticia.Estado {
+	case models.NoticiaBorrador, models.NoticiaArchivada:
+	case models.NoticiaPublicada:
+		if !noticia.FechaPublicacion.Valid {
+			noticia.FechaPublicacion = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	default:
+		return ErrNoticiaEstado
+	}
+	return nil
+}
+
+// slugDisponible arma el slug a partir de base y, si ya lo usa otra noticia, le
+// agrega el primer sufijo numérico libre (-2, -3...)
+func slugDisponible(tx *sql.Tx, base string, noticiaID int) (string, error) {
+	slug := utils.Slugify(base)
+	if slug == "" {
+		return "", ErrNoticiaSlugInvalido
+	}
+
+	rows, err := tx.Query(`
+		SELECT slug FROM noticias
+		WHERE (slug = $1 OR slug LIKE $1 || '-%') AND id <> $2`, slug, noticiaID)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	usados := map[string]bool{}
+	for rows.Next() {
+		var usado string
+		if err := rows.Scan(&usado); err != nil {
+			return "", err
+		}
+		usados[usado] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	candidato := slug
+	for i := 2; usados[candidato]; i++ {
+		candidato = fmt.Sprintf("%s-%d", slug, i)
+	}
+	return candidato, nil
+}
+
+// guardarVinculos reemplaza los torneos, partidos y jugadores vinculados a la noticia
+func guardarVinculos(tx *sql.Tx, noticia *models.Noticia) error {
+	vinculos := []struct {
+		tabla   string
+		columna string
+		ids     []int
+	}{
+		{"noticia_torneos", "torneo_id", noticia.TorneoIDs},
+		{"noticia_partidos", "partido_id", noticia.PartidoIDs},
+		{"noticia_jugadores", "jugador_id", noticia.JugadorIDs},
+	}
+
+	for _, v := range vinculos {
+		if _, err := tx.Exec(`DELETE FROM `+v.tabla+` WHERE noticia_id = $1`, noticia.ID); err != nil {
+			return err
+		}
+		ids := make([]int64, len(v.ids))
+		for i, id := range v.ids {
+			ids[i] = int64(id)
+		}
+		_, err := tx.Exec(`
+			INSERT INTO `+v.tabla+` (noticia_id, `+v.columna+`)
+			SELECT $1, UNNEST($2::INTEGER[])
+			ON CONFLICT DO NOTHING`, noticia.ID, pq.Array(ids))
+		if esViolacionForanea(err) {
+			return ErrNoticiaVinculo
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guardarNoticia ejecuta fn en una transacción y separa los errores de dominio de
+// los de la base de datos
+func guardarNoticia(fn func(tx *sql.Tx) error) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		err := fn(tx)
+		switch {
+		case esViolacionUnica(err, "noticias_slug_key"):
+			domainErr = ErrNoticiaSlugEnUso
+		case errors.Is(err, ErrNoticiaNotFound), errors.Is(err, ErrNoticiaSlugInvalido),
+			errors.Is(err, ErrNoticiaVinculo):
+			domainErr = err
+		}
+		return err
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+// CreateNoticia guarda la noticia; sin slug lo genera a partir del título
+func (s *noticiaServiceImpl) CreateNoticia(noticia *models.Noticia) error {
+	if err := prepararNoticia(noticia); err != nil {
+		return err
+	}
+
+	err := guardarNoticia(func(tx *sql.Tx) error {
+		base := noticia.Slug
+		if base == "" {
+			base = noticia.Titulo
+		}
+		slug, err := slugDisponible(tx, base, 0)
+		if err != nil {
+			return err
+		}
+		if noticia.Slug != "" && slug != utils.Slugify(noticia.Slug) {
+			return ErrNoticiaSlugEnUso
+		}
+		noticia.Slug = slug
+
+		err = tx.QueryRow(`
+			INSERT INTO noticias (titulo, slug, contenido, estado, fecha_publicacion, autor_id,
+			                      imagen_destacada_url, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+			RETURNING id, created_at, updated_at`,
+			noticia.Titulo, noticia.Slug, noticia.Contenido, noticia.Estado, noticia.FechaPublicacion,
+			noticia.AutorID, noticia.ImagenDestacadaURL,
+		).Scan(&noticia.ID, &noticia.CreatedAt, &noticia.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return guardarVinculos(tx, noticia)
+	})
+	if err != nil {
+		return err
+	}
+
+	guardada, err := s.GetNoticiaByID(noticia.ID)
+	if err != nil {
+		return err
+	}
+	*noticia = *guardada
+	return nil
+}
+
+// UpdateNoticia reemplaza la noticia. El slug de una noticia que ya se publicó no
+// cambia con el título, para no romper los enlaces; solo si se indica uno nuevo.
+// El autor original se conserva.
+func (s *noticiaServiceImpl) UpdateNoticia(id int, noticia *models.Noticia) error {
+	if err := prepararNoticia(noticia); err != nil {
+		return err
+	}
+
+	err := guardarNoticia(func(tx *sql.Tx) error {
+		var slugActual, estadoActual string
+		var publicadaAntes sql.NullTime
+		err := tx.QueryRow(`SELECT slug, estado, fecha_publicacion FROM noticias WHERE id = $1 FOR UPDATE`, id).
+			Scan(&slugActual, &estadoActual, &publicadaAntes)
+		if err == sql.ErrNoRows {
+			return ErrNoticiaNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		slug := slugActual
+		switch {
+		case noticia.Slug != "" && utils.Slugify(noticia.Slug) != slugActual:
+			if slug, err = slugDisponible(tx, noticia.Slug, id); err != nil {
+				return err
+			}
+			if slug != utils.Slugify(noticia.Slug) {
+				return ErrNoticiaSlugEnUso
+			}
+		case noticia.Slug == "" && estadoActual == models.NoticiaBorrador && !publicadaAntes.Valid:
+			if slug, err = slugDisponible(tx, noticia.Titulo, id); err != nil {
+				return err
+			}
+		}
+		noticia.ID = id
+		noticia.Slug = slug
+
+		_, err = tx.Exec(`
+			UPDATE noticias
+			SET titulo = $1, slug = $2, contenido = $3, estado = $4, fecha_publicacion = $5,
+			    imagen_destacada_url = $6, updated_at = NOW()
+			WHERE id = $7`,
+			noticia.Titulo, noticia.Slug, noticia.Contenido, noticia.Estado, noticia.FechaPublicacion,
+			noticia.ImagenDestacadaURL, id,
+		)
+		if err != nil {
+			return err
+		}
+		return guardarVinculos(tx, noticia)
+	})
+	if err != nil {
+		return err
+	}
+
+	guardada, err := s.GetNoticiaByID(id)
+	if err != nil {
+		return err
+	}
+	*noticia = *guardada
+	return nil
+}
+
+func (s *noticiaServiceImpl) DeleteNoticia(id int) error {
+	result, err := database.DB.Exec(`DELETE FROM noticias WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNoticiaNotFound
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+)
+
+// ErrTransicionInvalida indica que el partido no puede pasar al estado solicitado
+var ErrTransicionInvalida = errors.New("transición de estado inválida")
+
+// transicionesPartido define a qué estados puede pasar un partido desde cada
+// estado. El ciclo normal es pendiente → agendado → en juego → reportado →
+// finalizado (resultado aprobado); si los reportes de los jugadores no coinciden
+// el partido queda en disputa hasta que la resuelva un administrador. Walkover,
+// finalizado y cancelado son finales.
+var transicionesPartido = map[models.EstadoPartido][]models.EstadoPartido{
+	models.EstadoPorDefinir: {models.EstadoPendiente, models.EstadoCancelado},
+	models.EstadoPendiente: {
+		models.EstadoAgendado, models.EstadoWalkover, models.EstadoFinalizado, models.EstadoCancelado,
+	},
+	models.EstadoAgendado: {
+		models.EstadoPendiente, models.EstadoEnJuego, models.EstadoReportado, models.EstadoEnDisputa,
+		models.EstadoWalkover, models.EstadoFinalizado, models.EstadoCancelado,
+	},
+	models.EstadoEnJuego: {
+		models.EstadoReportado, models.EstadoEnDisputa, models.EstadoFinalizado, models.EstadoCancelado,
+	},
+	models.EstadoReportado:  {models.EstadoReportado, models.EstadoEnDisputa, models.EstadoFinalizado},
+	models.EstadoEnDisputa:  {models.EstadoFinalizado},
+	models.EstadoFinalizado: {},
+	models.EstadoWalkover:   {},
+	models.EstadoCancelado:  {},
+}
+
+// estadosConResultado solo se alcanzan registrando o aprobando un resultado
+var estadosConResultado = map[models.EstadoPartido]bool{
+	models.EstadoReportado:  true,
+	models.EstadoEnDisputa:  true,
+	models.EstadoFinalizado: true,
+	models.EstadoWalkover:   true,
+}
+
+// CanTransition indica si la máquina de estados admite pasar de un estado a otro
+func CanTransition(desde, hacia models.EstadoPartido) bool {
+	for _, estado := range transicionesPartido[desde] {
+		if estado == hacia {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition devuelve ErrTransicionInvalida si el cambio de estado no está permitido
+func checkTransition(desde, hacia models.EstadoPartido) error {
+	if !CanTransition(desde, hacia) {
+		return fmt.Errorf("%w: de %s a %s", ErrTransicionInvalida, desde, hacia)
+	}
+	return nil
+}
+
+// recordEvento registra un cambio de estado en el historial del partido. Un actorID
+// 0 indica un cambio hecho por el sistema.
+func recordEvento(tx *sql.Tx, partidoID int, desde, hacia models.EstadoPartido, actorID int, detalle string) error {
+	_, err := tx.Exec(`
+		INSERT INTO partido_eventos (partido_id, estado_anterior, estado_nuevo, usuario_id, detalle, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, ''), NOW())`,
+		partidoID, desde, hacia, actorID, detalle,
+	)
+	return err
+}
+
+// changeEstado valida y aplica un cambio de estado dentro de una transacción,
+// dejándolo registrado en el historial
+func changeEstado(tx *sql.Tx, partidoID int, desde, hacia models.EstadoPartido, actorID int, detalle string) error {
+	if err := checkTransition(desde, hacia); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`UPDATE partidos SET estado = $1, updated_at = NOW() WHERE id = $2`, hacia, partidoID)
+	if err != nil {
+		return err
+	}
+
+	return recordEvento(tx, partidoID, desde, hacia, actorID, detalle)
+}
+
+// ChangeEstado aplica un cambio de estado manual, como iniciar o cancelar un
+// partido. Los estados con resultado se alcanzan por sus propios flujos.
+func (s *partidoServiceImpl) ChangeEstado(partidoID int, estado models.EstadoPartido, actorID int, detalle string) error {
+	if _, ok := transicionesPartido[estado]; !ok || estadosConResultado[estado] {
+		return fmt.Errorf("%w: no se puede pasar manualmente a %s", ErrTransicionInvalida, estado)
+	}
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		partido, err := lockPartido(tx, partidoID)
+		if err != nil {
+			if errors.Is(err, ErrPartidoNotFound) {
+				domainErr = err
+			}
+			return err
+		}
+
+		if estado == models.EstadoPendiente && (partido.Jugador1ID == 0 || partido.Jugador2ID == 0) {
+			domainErr = ErrRivalesPorDefinir
+			return domainErr
+		}
+		err = changeEstado(tx, partidoID, partido.Estado, estado, actorID, detalle)
+		if errors.Is(err, ErrTransicionInvalida) {
+			domainErr = err
+		}
+		return err
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+
+	return err
+}
+
+// GetHistorial devuelve los cambios de estado de un partido en orden cronológico
+func (s *partidoServiceImpl) GetHistorial(partidoID int) ([]models.PartidoEvento, error) {
+	if _, err := s.GetPartidoByID(partidoID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT e.id, e.partido_id, e.estado_anterior, e.estado_nuevo, e.usuario_id,
+		       COALESCE(u.nombre_usuario, '') as usuario_nombre, e.detalle, e.created_at
+		FROM partido_eventos e
+		LEFT JOIN usuarios u ON e.usuario_id = u.id
+		WHERE e.partido_id = $1
+		ORDER BY e.created_at, e.id`
+
+	rows, err := database.DB.Query(query, partidoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	eventos := []models.PartidoEvento{}
+	for rows.Next() {
+		var e models.PartidoEvento
+		err := rows.Scan(
+			&e.ID, &e.PartidoID, &e.EstadoAnterior, &e.EstadoNuevo, &e.UsuarioID,
+			&e.UsuarioNombre, &e.Detalle, &e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		eventos = append(eventos, e)
+	}
+
+	return eventos, rows.Err()
+}
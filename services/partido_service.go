@@ -1,24 +1,48 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"time"
+	"fmt"
+	"strings"
 
 	"copa-litoral-backend/database"
 	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+)
+
+var (
+	// ErrPartidoNotFound indica que el partido solicitado no existe
+	ErrPartidoNotFound = errors.New("partido no encontrado")
+	// ErrResultNotReported indica que el partido aún no tiene un resultado para aprobar
+	ErrResultNotReported = errors.New("el partido no tiene un resultado reportado")
+	// ErrResultAlreadyApproved indica que el resultado del partido ya fue aprobado
+	ErrResultAlreadyApproved = errors.New("el resultado del partido ya fue aprobado")
+	// ErrNextMatchAlreadyPlayed indica que el partido siguiente de la llave ya tiene resultado
+	ErrNextMatchAlreadyPlayed = errors.New("el partido siguiente de la llave ya tiene resultado")
+	// ErrRivalesPorDefinir indica que el partido todavía no tiene ambos jugadores asignados
+	ErrRivalesPorDefinir = errors.New("el partido todavía no tiene ambos rivales definidos")
+	// ErrJugadorAjeno indica que el jugador no participa del partido
+	ErrJugadorAjeno = errors.New("el jugador no es parte de este partido")
 )
 
 type PartidoService interface {
 	GetAllPartidos(categoriaID int) ([]models.Partido, error)
 	GetPartidoByID(id int) (*models.Partido, error)
-	CreatePartido(partido *models.Partido) error
-	UpdatePartido(id int, partido *models.Partido) error
+	CreatePartido(partido *models.Partido, actorID int) error
+	UpdatePartido(id int, partido *models.Partido, actorID int) error
 	DeletePartido(id int) error
-	ProposeMatchTime(partidoID int, jugadorID int, fecha time.Time, hora time.Time) error
-	AcceptMatchTime(partidoID int, jugadorID int) error
-	ReportMatchResult(partidoID int, setsGanadosJ1 int, setsGanadosJ2 int, ganadorID int, sets []models.SetPartido) error
-	ApproveResult(partidoID int) error
+	ReportMatchResult(partidoID int, jugadorID int, setsGanadosJ1 int, setsGanadosJ2 int, ganadorID int, sets []models.SetPartido, actorID int) (models.EstadoReporte, error)
+	GetReportes(partidoID int) ([]models.ReporteResultado, error)
+	GetDisputasAbiertas() ([]models.DisputaResultado, error)
+	ResolveDispute(partidoID int, setsGanadosJ1 int, setsGanadosJ2 int, ganadorID int, sets []models.SetPartido, nota string, actorID int) error
+	ApproveResult(partidoID int, actorID int) error
+	RecordWalkover(partidoID int, beneficiarioID int, motivo string, actorID int) error
+	RecordRetirement(partidoID int, beneficiarioID int, motivo string, sets []models.SetPartido, actorID int) error
+	RecordDefault(partidoID int, beneficiarioID int, motivo string, sets []models.SetPartido, actorID int) error
+	ChangeEstado(partidoID int, estado models.EstadoPartido, actorID int, detalle string) error
+	GetHistorial(partidoID int) ([]models.PartidoEvento, error)
 }
 
 type partidoServiceImpl struct{}
@@ -27,46 +51,42 @@ func NewPartidoService() PartidoService {
 	return &partidoServiceImpl{}
 }
 
-func (s *partidoServiceImpl) GetAllPartidos(categoriaID int) ([]models.Partido, error) {
-	var query string
-	var args []interface{}
+// partidoSelectQuery selecciona las columnas de un partido junto a los nombres
+// de sus jugadores y categoría. Los jugadores aún por definir en una llave se
+// devuelven con ID 0.
+const partidoSelectQuery = `
+	SELECT p.id, p.torneo_id, p.categoria_id, COALESCE(p.jugador1_id, 0), COALESCE(p.jugador2_id, 0),
+	       p.fase, p.fecha_agendada, p.hora_agendada, p.agenda_escalada, p.estado,
+	       p.resultado_sets_j1, p.resultado_sets_j2, p.ganador_id, p.perdedor_id,
+	       p.resultado_aprobado, p.tipo_resultado, p.motivo_resultado, p.ronda, p.posicion_llave, p.siguiente_partido_id,
+	       p.siguiente_slot, p.grupo_id, p.jornada, p.created_at, p.updated_at,
+	       COALESCE(j1.nombre || ' ' || j1.apellido, '') as jugador1_nombre,
+	       COALESCE(j2.nombre || ' ' || j2.apellido, '') as jugador2_nombre,
+	       COALESCE(c.nombre, '') as categoria_nombre
+	FROM partidos p
+	LEFT JOIN jugadores j1 ON p.jugador1_id = j1.id
+	LEFT JOIN jugadores j2 ON p.jugador2_id = j2.id
+	LEFT JOIN categorias c ON p.categoria_id = c.id`
+
+// rowScanner abstrae *sql.Row y *sql.Rows para reutilizar el escaneo
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	if categoriaID > 0 {
-		query = `
-			SELECT p.id, p.torneo_id, p.categoria_id, p.jugador1_id, p.jugador2_id, 
-			       p.fase, p.fecha_agendada, p.hora_agendada, p.propuesta_fecha_j1, 
-			       p.propuesta_hora_j1, p.propuesta_fecha_j2, p.propuesta_hora_j2,
-			       p.propuesta_aceptada_j1, p.propuesta_aceptada_j2, p.estado,
-			       p.resultado_sets_j1, p.resultado_sets_j2, p.ganador_id, p.perdedor_id,
-			       p.resultado_aprobado, p.created_at, p.updated_at,
-			       j1.nombre || ' ' || j1.apellido as jugador1_nombre,
-			       j2.nombre || ' ' || j2.apellido as jugador2_nombre,
-			       c.nombre as categoria_nombre
-			FROM partidos p
-			LEFT JOIN jugadores j1 ON p.jugador1_id = j1.id
-			LEFT JOIN jugadores j2 ON p.jugador2_id = j2.id
-			LEFT JOIN categorias c ON p.categoria_id = c.id
-			WHERE p.categoria_id = $1
-			ORDER BY p.created_at DESC`
-		args = append(args, categoriaID)
-	} else {
-		query = `
-			SELECT p.id, p.torneo_id, p.categoria_id, p.jugador1_id, p.jugador2_id, 
-			       p.fase, p.fecha_agendada, p.hora_agendada, p.propuesta_fecha_j1, 
-			       p.propuesta_hora_j1, p.propuesta_fecha_j2, p.propuesta_hora_j2,
-			       p.propuesta_aceptada_j1, p.propuesta_aceptada_j2, p.estado,
-			       p.resultado_sets_j1, p.resultado_sets_j2, p.ganador_id, p.perdedor_id,
-			       p.resultado_aprobado, p.created_at, p.updated_at,
-			       j1.nombre || ' ' || j1.apellido as jugador1_nombre,
-			       j2.nombre || ' ' || j2.apellido as jugador2_nombre,
-			       c.nombre as categoria_nombre
-			FROM partidos p
-			LEFT JOIN jugadores j1 ON p.jugador1_id = j1.id
-			LEFT JOIN jugadores j2 ON p.jugador2_id = j2.id
-			LEFT JOIN categorias c ON p.categoria_id = c.id
-			ORDER BY p.created_at DESC`
-	}
+// scanPartido escanea una fila obtenida con partidoSelectQuery
+func scanPartido(row rowScanner, p *models.Partido) error {
+	return row.Scan(
+		&p.ID, &p.TorneoID, &p.CategoriaID, &p.Jugador1ID, &p.Jugador2ID,
+		&p.Fase, &p.FechaAgendada, &p.HoraAgendada, &p.AgendaEscalada, &p.Estado,
+		&p.ResultadoSetsJ1, &p.ResultadoSetsJ2, &p.GanadorID, &p.PerdedorID,
+		&p.ResultadoAprobado, &p.TipoResultado, &p.MotivoResultado, &p.Ronda, &p.PosicionLlave, &p.SiguientePartidoID,
+		&p.SiguienteSlot, &p.GrupoID, &p.Jornada, &p.CreatedAt, &p.UpdatedAt,
+		&p.Jugador1Nombre, &p.Jugador2Nombre, &p.CategoriaNombre,
+	)
+}
 
+// queryPartidos ejecuta una consulta basada en partidoSelectQuery y escanea todas sus filas
+func queryPartidos(query string, args ...interface{}) ([]models.Partido, error) {
 	rows, err := database.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
@@ -76,55 +96,32 @@ func (s *partidoServiceImpl) GetAllPartidos(categoriaID int) ([]models.Partido,
 	var partidos []models.Partido
 	for rows.Next() {
 		var p models.Partido
-		err := rows.Scan(
-			&p.ID, &p.TorneoID, &p.CategoriaID, &p.Jugador1ID, &p.Jugador2ID,
-			&p.Fase, &p.FechaAgendada, &p.HoraAgendada, &p.PropuestaFechaJ1,
-			&p.PropuestaHoraJ1, &p.PropuestaFechaJ2, &p.PropuestaHoraJ2,
-			&p.PropuestaAceptadaJ1, &p.PropuestaAceptadaJ2, &p.Estado,
-			&p.ResultadoSetsJ1, &p.ResultadoSetsJ2, &p.GanadorID, &p.PerdedorID,
-			&p.ResultadoAprobado, &p.CreatedAt, &p.UpdatedAt,
-			&p.Jugador1Nombre, &p.Jugador2Nombre, &p.CategoriaNombre,
-		)
-		if err != nil {
+		if err := scanPartido(rows, &p); err != nil {
 			return nil, err
 		}
 		partidos = append(partidos, p)
 	}
 
-	return partidos, nil
+	return partidos, rows.Err()
 }
 
-func (s *partidoServiceImpl) GetPartidoByID(id int) (*models.Partido, error) {
-	query := `
-		SELECT p.id, p.torneo_id, p.categoria_id, p.jugador1_id, p.jugador2_id, 
-		       p.fase, p.fecha_agendada, p.hora_agendada, p.propuesta_fecha_j1, 
-		       p.propuesta_hora_j1, p.propuesta_fecha_j2, p.propuesta_hora_j2,
-		       p.propuesta_aceptada_j1, p.propuesta_aceptada_j2, p.estado,
-		       p.resultado_sets_j1, p.resultado_sets_j2, p.ganador_id, p.perdedor_id,
-		       p.resultado_aprobado, p.created_at, p.updated_at,
-		       j1.nombre || ' ' || j1.apellido as jugador1_nombre,
-		       j2.nombre || ' ' || j2.apellido as jugador2_nombre,
-		       c.nombre as categoria_nombre
-		FROM partidos p
-		LEFT JOIN jugadores j1 ON p.jugador1_id = j1.id
-		LEFT JOIN jugadores j2 ON p.jugador2_id = j2.id
-		LEFT JOIN categorias c ON p.categoria_id = c.id
-		WHERE p.id = $1`
+func (s *partidoServiceImpl) GetAllPartidos(categoriaID int) ([]models.Partido, error) {
+	if categoriaID > 0 {
+		return queryPartidos(partidoSelectQuery+`
+			WHERE p.categoria_id = $1
+			ORDER BY p.created_at DESC`, categoriaID)
+	}
 
-	var partido models.Partido
-	err := database.DB.QueryRow(query, id).Scan(
-		&partido.ID, &partido.TorneoID, &partido.CategoriaID, &partido.Jugador1ID, &partido.Jugador2ID,
-		&partido.Fase, &partido.FechaAgendada, &partido.HoraAgendada, &partido.PropuestaFechaJ1,
-		&partido.PropuestaHoraJ1, &partido.PropuestaFechaJ2, &partido.PropuestaHoraJ2,
-		&partido.PropuestaAceptadaJ1, &partido.PropuestaAceptadaJ2, &partido.Estado,
-		&partido.ResultadoSetsJ1, &partido.ResultadoSetsJ2, &partido.GanadorID, &partido.PerdedorID,
-		&partido.ResultadoAprobado, &partido.CreatedAt, &partido.UpdatedAt,
-		&partido.Jugador1Nombre, &partido.Jugador2Nombre, &partido.CategoriaNombre,
-	)
+	return queryPartidos(partidoSelectQuery + `
+		ORDER BY p.created_at DESC`)
+}
 
+func (s *partidoServiceImpl) GetPartidoByID(id int) (*models.Partido, error) {
+	var partido models.Partido
+	err := scanPartido(database.DB.QueryRow(partidoSelectQuery+` WHERE p.id = $1`, id), &partido)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("partido no encontrado")
+			return nil, ErrPartidoNotFound
 		}
 		return nil, err
 	}
@@ -132,45 +129,93 @@ func (s *partidoServiceImpl) GetPartidoByID(id int) (*models.Partido, error) {
 	return &partido, nil
 }
 
-func (s *partidoServiceImpl) CreatePartido(partido *models.Partido) error {
+// CreatePartido crea un partido pendiente, o por definir si falta algún rival; los
+// demás estados se alcanzan con la máquina de estados. El alta queda en el historial.
+func (s *partidoServiceImpl) CreatePartido(partido *models.Partido, actorID int) error {
+	estado := models.EstadoPendiente
+	if partido.Jugador1ID == 0 || partido.Jugador2ID == 0 {
+		estado = models.EstadoPorDefinir
+	}
+	if partido.Estado != "" && partido.Estado != estado {
+		return fmt.Errorf("%w: un partido nuevo con esos rivales queda en %s, no en %s", ErrTransicionInvalida, estado, partido.Estado)
+	}
+	partido.Estado = estado
+
 	query := `
 		INSERT INTO partidos (torneo_id, categoria_id, jugador1_id, jugador2_id, fase, 
 		                     fecha_agendada, hora_agendada, estado, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5, $6, $7, $8, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
-	return database.DB.QueryRow(query,
-		partido.TorneoID, partido.CategoriaID, partido.Jugador1ID, partido.Jugador2ID,
-		partido.Fase, partido.FechaAgendada, partido.HoraAgendada, partido.Estado,
-	).Scan(&partido.ID, &partido.CreatedAt, &partido.UpdatedAt)
+	txManager := database.NewTxManager(database.DB)
+	return txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		err := tx.QueryRow(query,
+			partido.TorneoID, partido.CategoriaID, partido.Jugador1ID, partido.Jugador2ID,
+			partido.Fase, partido.FechaAgendada, partido.HoraAgendada, partido.Estado,
+		).Scan(&partido.ID, &partido.CreatedAt, &partido.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		// Sin estado anterior: el evento marca el alta del partido
+		return recordEvento(tx, partido.ID, "", partido.Estado, actorID, "Partido creado")
+	})
 }
 
-func (s *partidoServiceImpl) UpdatePartido(id int, partido *models.Partido) error {
-	query := `
-		UPDATE partidos 
-		SET torneo_id = $1, categoria_id = $2, jugador1_id = $3, jugador2_id = $4,
-		    fase = $5, fecha_agendada = $6, hora_agendada = $7, estado = $8,
-		    updated_at = NOW()
-		WHERE id = $9`
-
-	result, err := database.DB.Exec(query,
-		partido.TorneoID, partido.CategoriaID, partido.Jugador1ID, partido.Jugador2ID,
-		partido.Fase, partido.FechaAgendada, partido.HoraAgendada, partido.Estado, id,
-	)
-	if err != nil {
-		return err
-	}
+// UpdatePartido actualiza los datos de un partido. Un cambio de estado debe
+// respetar la máquina de estados y queda registrado en el historial; si no se
+// informa estado se conserva el actual.
+func (s *partidoServiceImpl) UpdatePartido(id int, partido *models.Partido, actorID int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		actual, err := lockPartido(tx, id)
+		if err != nil {
+			if errors.Is(err, ErrPartidoNotFound) {
+				domainErr = err
+			}
+			return err
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+		if partido.Estado == "" {
+			partido.Estado = actual.Estado
+		}
+		if partido.Estado != actual.Estado {
+			if estadosConResultado[partido.Estado] {
+				domainErr = fmt.Errorf("%w: el estado %s se alcanza registrando un resultado", ErrTransicionInvalida, partido.Estado)
+				return domainErr
+			}
+			if err := checkTransition(actual.Estado, partido.Estado); err != nil {
+				domainErr = err
+				return domainErr
+			}
+		}
 
-	if rowsAffected == 0 {
-		return errors.New("partido no encontrado")
+		query := `
+			UPDATE partidos 
+			SET torneo_id = $1, categoria_id = $2, jugador1_id = NULLIF($3, 0), jugador2_id = NULLIF($4, 0),
+			    fase = $5, fecha_agendada = $6, hora_agendada = $7, estado = $8,
+			    updated_at = NOW()
+			WHERE id = $9`
+
+		_, err = tx.Exec(query,
+			partido.TorneoID, partido.CategoriaID, partido.Jugador1ID, partido.Jugador2ID,
+			partido.Fase, partido.FechaAgendada, partido.HoraAgendada, partido.Estado, id,
+		)
+		if err != nil {
+			return err
+		}
+
+		if partido.Estado != actual.Estado {
+			return recordEvento(tx, id, actual.Estado, partido.Estado, actorID, "Partido actualizado")
+		}
+		return nil
+	})
+	if domainErr != nil {
+		return domainErr
 	}
 
-	return nil
+	return err
 }
 
 func (s *partidoServiceImpl) DeletePartido(id int) error {
@@ -187,176 +232,278 @@ func (s *partidoServiceImpl) DeletePartido(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("partido no encontrado")
+		return ErrPartidoNotFound
 	}
 
 	return nil
 }
 
-func (s *partidoServiceImpl) ProposeMatchTime(partidoID int, jugadorID int, fecha time.Time, hora time.Time) error {
-	// Verificar que el jugador es parte del partido
-	partido, err := s.GetPartidoByID(partidoID)
+// lockPartido obtiene un partido bloqueando su fila hasta el fin de la transacción
+func lockPartido(tx *sql.Tx, partidoID int) (*models.Partido, error) {
+	var partido models.Partido
+	err := scanPartido(tx.QueryRow(partidoSelectQuery+` WHERE p.id = $1 FOR UPDATE OF p`, partidoID), &partido)
 	if err != nil {
-		return err
+		if err == sql.ErrNoRows {
+			return nil, ErrPartidoNotFound
+		}
+		return nil, err
 	}
+	return &partido, nil
+}
 
-	if partido.Jugador1ID != jugadorID && partido.Jugador2ID != jugadorID {
-		return errors.New("el jugador no es parte de este partido")
-	}
+// queryRower abstrae *sql.DB y *sql.Tx para consultas de una sola fila
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
 
-	// Determinar qué jugador está proponiendo
-	var query string
-	if partido.Jugador1ID == jugadorID {
-		query = `
-			UPDATE partidos 
-			SET propuesta_fecha_j1 = $1, propuesta_hora_j1 = $2, updated_at = NOW()
-			WHERE id = $3`
-	} else {
-		query = `
-			UPDATE partidos 
-			SET propuesta_fecha_j2 = $1, propuesta_hora_j2 = $2, updated_at = NOW()
-			WHERE id = $3`
+// formatoDelTorneo obtiene el formato de puntuación configurado en el torneo
+func formatoDelTorneo(q queryRower, torneoID int) (FormatoPartido, error) {
+	var nombre string
+	if err := q.QueryRow(`SELECT formato_partido FROM torneos WHERE id = $1`, torneoID).Scan(&nombre); err != nil {
+		return FormatoPartido{}, err
 	}
 
-	result, err := database.DB.Exec(query, fecha, hora, partidoID)
-	if err != nil {
-		return err
+	formato, ok := GetFormatoPartido(nombre)
+	if !ok {
+		return FormatoPartido{}, ErrInvalidFormato
 	}
+	return formato, nil
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+// replaceSets reemplaza los sets cargados de un partido por los informados
+func replaceSets(tx *sql.Tx, partidoID int, sets []models.SetPartido) error {
+	if _, err := tx.Exec(`DELETE FROM sets_partido WHERE partido_id = $1`, partidoID); err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("partido no encontrado")
+	for _, set := range sets {
+		insertSetQuery := `
+			INSERT INTO sets_partido (partido_id, numero_set, score_jugador1, score_jugador2,
+			                         tie_break_j1, tie_break_j2, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())`
+
+		_, err := tx.Exec(insertSetQuery,
+			partidoID, set.NumeroSet, set.ScoreJugador1, set.ScoreJugador2,
+			set.TieBreakJ1, set.TieBreakJ2,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (s *partidoServiceImpl) AcceptMatchTime(partidoID int, jugadorID int) error {
-	// Verificar que el jugador es parte del partido
-	partido, err := s.GetPartidoByID(partidoID)
-	if err != nil {
-		return err
-	}
+// ApproveResult aprueba el resultado reportado de un partido. Si el partido forma
+// parte de una llave, el ganador pasa al partido siguiente; si es la final, queda
+// registrado como campeón del torneo y categoría.
+func (s *partidoServiceImpl) ApproveResult(partidoID int, actorID int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		partido, err := lockPartido(tx, partidoID)
+		if err != nil {
+			if errors.Is(err, ErrPartidoNotFound) {
+				domainErr = err
+			}
+			return err
+		}
 
-	if partido.Jugador1ID != jugadorID && partido.Jugador2ID != jugadorID {
-		return errors.New("el jugador no es parte de este partido")
-	}
+		if partido.ResultadoAprobado {
+			domainErr = ErrResultAlreadyApproved
+			return domainErr
+		}
+		if partido.Estado == models.EstadoEnDisputa {
+			domainErr = ErrResultadoEnDisputa
+			return domainErr
+		}
+		if !partido.GanadorID.Valid {
+			domainErr = ErrResultNotReported
+			return domainErr
+		}
+		if err := checkTransition(partido.Estado, models.EstadoFinalizado); err != nil {
+			domainErr = err
+			return domainErr
+		}
 
-	// Determinar qué jugador está aceptando
-	var query string
-	if partido.Jugador1ID == jugadorID {
-		query = `
-			UPDATE partidos 
-			SET propuesta_aceptada_j1 = true, updated_at = NOW()
-			WHERE id = $1`
-	} else {
-		query = `
-			UPDATE partidos 
-			SET propuesta_aceptada_j2 = true, updated_at = NOW()
-			WHERE id = $1`
+		_, err = tx.Exec(`
+			UPDATE partidos SET resultado_aprobado = true, estado = $1, updated_at = NOW() WHERE id = $2`,
+			models.EstadoFinalizado, partidoID,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := recordEvento(tx, partidoID, partido.Estado, models.EstadoFinalizado, actorID, "Resultado aprobado"); err != nil {
+			return err
+		}
+
+		domainErr = advanceWinner(tx, partido)
+		return domainErr
+	})
+	if domainErr != nil {
+		return domainErr
 	}
 
-	result, err := database.DB.Exec(query, partidoID)
-	if err != nil {
+	return err
+}
+
+// advanceWinner propaga el ganador de un partido aprobado: lo ubica en el lugar que le
+// corresponde del partido siguiente de la llave o, si se trata de la final, lo registra
+// en campeones. Debe ejecutarse dentro de la transacción que aprueba el resultado.
+func advanceWinner(tx *sql.Tx, partido *models.Partido) error {
+	ganadorID := int(partido.GanadorID.Int32)
+
+	if !partido.SiguientePartidoID.Valid {
+		if partido.Fase != models.FaseFinal {
+			return nil
+		}
+
+		query := `
+			INSERT INTO campeones (torneo_id, categoria_id, jugador_id, anio, created_at, updated_at)
+			SELECT t.id, $2, $3, t.anio, NOW(), NOW()
+			FROM torneos t
+			WHERE t.id = $1
+			ON CONFLICT (torneo_id, categoria_id, anio)
+			DO UPDATE SET jugador_id = EXCLUDED.jugador_id, jugador_nombre = NULL, updated_at = NOW()`
+
+		_, err := tx.Exec(query, partido.TorneoID, partido.CategoriaID, ganadorID)
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	var siguiente models.Partido
+	err := scanPartido(tx.QueryRow(partidoSelectQuery+` WHERE p.id = $1 FOR UPDATE OF p`, partido.SiguientePartidoID.Int32), &siguiente)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("partido no encontrado")
+	if siguiente.GanadorID.Valid {
+		return ErrNextMatchAlreadyPlayed
 	}
 
-	return nil
-}
-
-func (s *partidoServiceImpl) ReportMatchResult(partidoID int, setsGanadosJ1 int, setsGanadosJ2 int, ganadorID int, sets []models.SetPartido) error {
-	// Iniciar transacción
-	tx, err := database.DB.Begin()
-	if err != nil {
-		return err
+	jugador1ID, jugador2ID := siguiente.Jugador1ID, siguiente.Jugador2ID
+	if partido.SiguienteSlot.Int32 == 2 {
+		jugador2ID = ganadorID
+	} else {
+		jugador1ID = ganadorID
 	}
-	defer tx.Rollback()
 
-	// Actualizar el partido con el resultado
-	perdedorID := 0
-	if ganadorID == 0 {
-		return errors.New("ganador_id es requerido")
+	// El partido queda en condiciones de agendarse cuando ambos rivales están definidos
+	estado := siguiente.Estado
+	if estado == models.EstadoPorDefinir && jugador1ID != 0 && jugador2ID != 0 {
+		estado = models.EstadoPendiente
 	}
 
-	// Determinar el perdedor
-	partido, err := s.GetPartidoByID(partidoID)
-	if err != nil {
+	_, err = tx.Exec(`
+		UPDATE partidos
+		SET jugador1_id = NULLIF($1, 0), jugador2_id = NULLIF($2, 0), estado = $3, updated_at = NOW()
+		WHERE id = $4`,
+		jugador1ID, jugador2ID, estado, siguiente.ID,
+	)
+	if err != nil || estado == siguiente.Estado {
 		return err
 	}
 
-	if ganadorID == partido.Jugador1ID {
-		perdedorID = partido.Jugador2ID
-	} else if ganadorID == partido.Jugador2ID {
-		perdedorID = partido.Jugador1ID
-	} else {
-		return errors.New("ganador_id no corresponde a ningún jugador del partido")
-	}
+	return recordEvento(tx, siguiente.ID, siguiente.Estado, estado, 0, "Rivales definidos")
+}
 
-	// Actualizar partido
-	updateQuery := `
-		UPDATE partidos 
-		SET resultado_sets_j1 = $1, resultado_sets_j2 = $2, ganador_id = $3, 
-		    perdedor_id = $4, estado = 'finalizado', updated_at = NOW()
-		WHERE id = $5`
+func (s *partidoServiceImpl) RecordWalkover(partidoID int, beneficiarioID int, motivo string, actorID int) error {
+	return recordOutcome(partidoID, models.TipoResultadoWalkover, beneficiarioID, motivo, nil, actorID)
+}
 
-	_, err = tx.Exec(updateQuery, setsGanadosJ1, setsGanadosJ2, ganadorID, perdedorID, partidoID)
-	if err != nil {
-		return err
-	}
+func (s *partidoServiceImpl) RecordRetirement(partidoID int, beneficiarioID int, motivo string, sets []models.SetPartido, actorID int) error {
+	return recordOutcome(partidoID, models.TipoResultadoAbandono, beneficiarioID, motivo, sets, actorID)
+}
 
-	// Insertar los sets del partido
-	for _, set := range sets {
-		set.PartidoID = partidoID
-		insertSetQuery := `
-			INSERT INTO sets_partido (partido_id, numero_set, score_jugador1, score_jugador2,
-			                         tie_break_j1, tie_break_j2, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())`
+func (s *partidoServiceImpl) RecordDefault(partidoID int, beneficiarioID int, motivo string, sets []models.SetPartido, actorID int) error {
+	return recordOutcome(partidoID, models.TipoResultadoDescalificacion, beneficiarioID, motivo, sets, actorID)
+}
 
-		_, err = tx.Exec(insertSetQuery,
-			set.PartidoID, set.NumeroSet, set.ScoreJugador1, set.ScoreJugador2,
-			set.TieBreakJ1, set.TieBreakJ2,
-		)
+// recordOutcome registra un partido definido sin jugarse completo. El beneficiario
+// gana con los sets necesarios acreditados, el resultado queda aprobado y, si el
+// partido es parte de una llave, el ganador avanza en la misma transacción.
+func recordOutcome(partidoID int, tipo models.TipoResultado, beneficiarioID int, motivo string, sets []models.SetPartido, actorID int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		partido, err := lockPartido(tx, partidoID)
 		if err != nil {
+			if errors.Is(err, ErrPartidoNotFound) {
+				domainErr = err
+			}
 			return err
 		}
-	}
 
-	// Confirmar transacción
-	return tx.Commit()
-}
+		if partido.Jugador1ID == 0 || partido.Jugador2ID == 0 {
+			domainErr = ErrRivalesPorDefinir
+			return domainErr
+		}
+		if partido.ResultadoAprobado {
+			domainErr = ErrResultAlreadyApproved
+			return domainErr
+		}
 
-func (s *partidoServiceImpl) ApproveResult(partidoID int) error {
-	query := `
-		UPDATE partidos 
-		SET resultado_aprobado = true, updated_at = NOW()
-		WHERE id = $1`
+		formato, err := formatoDelTorneo(tx, partido.TorneoID)
+		if err != nil {
+			return err
+		}
 
-	result, err := database.DB.Exec(query, partidoID)
-	if err != nil {
-		return err
-	}
+		var detalles []utils.ErrorDetail
+		if strings.TrimSpace(motivo) == "" {
+			detalles = append(detalles, utils.CreateFieldError("motivo", utils.ErrMissingField,
+				"se requiere el motivo", nil))
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+		setsJ1, setsJ2, scoreErr := ValidatePartialScore(formato, partido.Jugador1ID, partido.Jugador2ID, beneficiarioID, sets)
+		if scoreErr != nil {
+			detalles = append(detalles, scoreErr.Errors...)
+		}
+		if len(detalles) > 0 {
+			domainErr = &ScoreValidationError{Errors: detalles}
+			return domainErr
+		}
 
-	if rowsAffected == 0 {
-		return errors.New("partido no encontrado")
+		perdedorID := partido.Jugador1ID
+		if beneficiarioID == partido.Jugador1ID {
+			perdedorID = partido.Jugador2ID
+		}
+
+		estado := models.EstadoFinalizado
+		if tipo == models.TipoResultadoWalkover {
+			estado = models.EstadoWalkover
+		}
+		if err := checkTransition(partido.Estado, estado); err != nil {
+			domainErr = err
+			return domainErr
+		}
+
+		updateQuery := `
+			UPDATE partidos
+			SET resultado_sets_j1 = $1, resultado_sets_j2 = $2, ganador_id = $3, perdedor_id = $4,
+			    estado = $5, tipo_resultado = $6, motivo_resultado = $7, resultado_aprobado = true,
+			    updated_at = NOW()
+			WHERE id = $8`
+
+		_, err = tx.Exec(updateQuery, setsJ1, setsJ2, beneficiarioID, perdedorID, estado, tipo, motivo, partidoID)
+		if err != nil {
+			return err
+		}
+
+		if err := replaceSets(tx, partidoID, sets); err != nil {
+			return err
+		}
+
+		if err := recordEvento(tx, partidoID, partido.Estado, estado, actorID, motivo); err != nil {
+			return err
+		}
+
+		partido.GanadorID = sql.NullInt32{Int32: int32(beneficiarioID), Valid: true}
+		domainErr = advanceWinner(tx, partido)
+		return domainErr
+	})
+	if domainErr != nil {
+		return domainErr
 	}
 
-	return nil
-} 
\ No newline at end of file
+	return err
+}
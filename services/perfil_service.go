@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+)
+
+var (
+	// ErrPasswordActualIncorrecta indica que no coincide la contraseña actual al cambiarla
+	ErrPasswordActualIncorrecta = errors.New("la contraseña actual es incorrecta")
+	// ErrEmailEnUso indica que el email pertenece a otra cuenta
+	ErrEmailEnUso = errors.New("el email ya está registrado")
+	// ErrSinJugador indica que la cuenta no está vinculada a un jugador
+	ErrSinJugador = errors.New("la cuenta no está vinculada a un jugador")
+)
+
+// CambiosPerfil son los datos que el usuario puede cambiar de su propia cuenta;
+// los campos nil o vacíos no se modifican
+type CambiosPerfil struct {
+	Email                *string
+	ContactoVisibleEnWeb *bool
+	PasswordActual       string
+	PasswordNuevo        string
+}
+
+type PerfilService interface {
+	GetPerfil(usuarioID int) (*models.Perfil, error)
+	UpdatePerfil(usuarioID int, sesionID string, cambios CambiosPerfil) (*models.Perfil, error)
+	GetMisPartidos(usuarioID int, estado string) ([]models.Partido, error)
+	GetMisPropuestasPendientes(usuarioID int) ([]models.PropuestaHorario, error)
+	GetMisResultadosPorConfirmar(usuarioID int) ([]models.Partido, error)
+}
+
+type perfilServiceImpl struct {
+	authService    AuthService
+	jugadorService JugadorService
+}
+
+// NewPerfilService crea el servicio de la cuenta propia; authService envía la
+// verificación cuando el usuario cambia su email
+func NewPerfilService(authService AuthService, jugadorService JugadorService) PerfilService {
+	return &perfilServiceImpl{
+		authService:    authService,
+		jugadorService: jugadorService,
+	}
+}
+
+func (s *perfilServiceImpl) GetPerfil(usuarioID int) (*models.Perfil, error) {
+	u, err := scanUsuario(database.DB.QueryRow(usuarioSelect+" WHERE id = $1", usuarioID))
+	if err == sql.ErrNoRows {
+		return nil, ErrUsuarioNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	perfil := &models.Perfil{Usuario: *u}
+	if u.JugadorID.Valid {
+		jugador, err := s.jugadorService.GetJugadorByID(int(u.JugadorID.Int32))
+		if err != nil {
+			return nil, err
+		}
+		perfil.Jugador = jugador
+	}
+	return perfil, nil
+}
+
+// UpdatePerfil aplica los cambios en una sola transacción. Cambiar la contraseña
+// exige la actual y cierra las demás sesiones del usuario; cambiar el email lo deja
+// sin verificar y envía un nuevo enlace de verificación.
+func (s *perfilServiceImpl) UpdatePerfil(usuarioID int, sesionID string, cambios CambiosPerfil) (*models.Perfil, error) {
+	emailCambiado := false
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		var passwordHash string
+		var email sql.NullString
+		var jugadorID sql.NullInt32
+		err := tx.QueryRow(`SELECT password_hash, email, jugador_id FROM usuarios WHERE id = $1 FOR UPDATE`, usuarioID).
+			Scan(&passwordHash, &email, &jugadorID)
+		if err == sql.ErrNoRows {
+			domainErr = ErrUsuarioNotFound
+			return domainErr
+		}
+		if err != nil {
+			return err
+		}
+
+		if cambios.PasswordNuevo != "" {
+			if utils.CheckPasswordHash(cambios.PasswordActual, passwordHash) != nil {
+				domainErr = ErrPasswordActualIncorrecta
+				return domainErr
+			}
+			hashedPassword, err := utils.HashPassword(cambios.PasswordNuevo)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`UPDATE usuarios SET password_hash = $1, updated_at = NOW() WHERE id = $2`,
+				hashedPassword, usuarioID)
+			if err != nil {
+				return err
+			}
+			// La sesión desde la que se cambió sigue abierta
+			_, err = tx.Exec(`
+				UPDATE refresh_tokens SET revocado_en = NOW()
+				WHERE usuario_id = $1 AND familia <> $2 AND revocado_en IS NULL`, usuarioID, sesionID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if cambios.Email != nil {
+			nuevo := normalizarEmail(*cambios.Email)
+			if nuevo != normalizarEmail(email.String) {
+				_, err := tx.Exec(`
+					UPDATE usuarios SET email = $1, email_verificado_en = NULL, updated_at = NOW()
+					WHERE id = $2`, nuevo, usuarioID)
+				if esViolacionUnica(err, "usuarios_email_key") {
+					domainErr = ErrEmailEnUso
+					return domainErr
+				}
+				if err != nil {
+					return err
+				}
+				emailCambiado = true
+			}
+		}
+
+		if cambios.ContactoVisibleEnWeb != nil {
+			if !jugadorID.Valid {
+				domainErr = ErrSinJugador
+				return domainErr
+			}
+			_, err := tx.Exec(`UPDATE jugadores SET contacto_visible_en_web = $1, updated_at = NOW() WHERE id = $2`,
+				*cambios.ContactoVisibleEnWeb, jugadorID.Int32)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Un fallo en el envío no revierte el cambio; el usuario puede pedir otro enlace
+	if emailCambiado {
+		if err := s.authService.SendVerificationEmail(usuarioID); err != nil {
+			utils.LogError("Error enviando correo de verificación", err, map[string]interface{}{
+				"usuario_id": usuarioID,
+			})
+		}
+	}
+
+	return s.GetPerfil(usuarioID)
+}
+
+// jugadorDelUsuario devuelve el jugador vinculado a la cuenta según la base de
+// datos, que puede haber cambiado desde que se emitió el token
+func jugadorDelUsuario(usuarioID int) (int, error) {
+	var jugadorID sql.NullInt32
+	err := database.DB.QueryRow(`SELECT jugador_id FROM usuarios WHERE id = $1`, usuarioID).Scan(&jugadorID)
+	if err == sql.ErrNoRows {
+		return 0, ErrUsuarioNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !jugadorID.Valid {
+		return 0, ErrSinJugador
+	}
+	return int(jugadorID.Int32), nil
+}
+
+// GetMisPartidos devuelve los partidos del jugador de la cuenta, opcionalmente
+// filtrados por estado, con los próximos primero
+func (s *perfilServiceImpl) GetMisPartidos(usuarioID int, estado string) ([]models.Partido, error) {
+	jugadorID, err := jugadorDelUsuario(usuarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	partidos, err := queryPartidos(partidoSelectQuery+`
+		WHERE (p.jugador1_id = $1 OR p.jugador2_id = $1) AND ($2 = '' OR p.estado = $2)
+		ORDER BY p.fecha_agendada NULLS LAST, p.hora_agendada NULLS LAST, p.id`, jugadorID, estado)
+	if partidos == nil && err == nil {
+		partidos = []models.Partido{}
+	}
+	return partidos, err
+}
+
+// GetMisPropuestasPendientes devuelve las propuestas de horario sin responder de
+// los partidos del jugador, tanto las enviadas como las que esperan su respuesta
+func (s *perfilServiceImpl) GetMisPropuestasPendientes(usuarioID int) ([]models.PropuestaHorario, error) {
+	jugadorID, err := jugadorDelUsuario(usuarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	return queryPropuestas(propuestaSelectQuery+`
+		JOIN partidos p ON pr.partido_id = p.id
+		WHERE (p.jugador1_id = $1 OR p.jugador2_id = $1) AND pr.estado = $2 AND pr.expira_en > NOW()
+		ORDER BY pr.expira_en, pr.id`, jugadorID, models.PropuestaPendiente)
+}
+
+// GetMisResultadosPorConfirmar devuelve los partidos en los que el rival reportó
+// un resultado y el jugador todavía no informó el suyo
+func (s *perfilServiceImpl) GetMisResultadosPorConfirmar(usuarioID int) ([]models.Partido, error) {
+	jugadorID, err := jugadorDelUsuario(usuarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	partidos, err := queryPartidos(partidoSelectQuery+`
+		WHERE (p.jugador1_id = $1 OR p.jugador2_id = $1)
+		  AND EXISTS (SELECT 1 FROM reportes_resultado r
+		              WHERE r.partido_id = p.id AND r.jugador_id <> $1 AND r.estado = $2)
+		  AND NOT EXISTS (SELECT 1 FROM reportes_resultado r
+		                  WHERE r.partido_id = p.id AND r.jugador_id = $1 AND r.estado = $2)
+		ORDER BY p.updated_at, p.id`, jugadorID, models.ReportePendiente)
+	if partidos == nil && err == nil {
+		partidos = []models.Partido{}
+	}
+	return partidos, err
+}
@@ -0,0 +1,504 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+
+	"github.com/lib/pq"
+)
+
+// MaxOpcionesPropuesta limita los horarios candidatos de una propuesta
+const MaxOpcionesPropuesta = 5
+
+var (
+	// ErrPropuestaNotFound indica que la propuesta solicitada no existe
+	ErrPropuestaNotFound = errors.New("propuesta no encontrada")
+	// ErrInvalidPropuesta indica que los horarios propuestos no son válidos
+	ErrInvalidPropuesta = errors.New("propuesta de horario inválida")
+	// ErrPropuestaCerrada indica que la propuesta ya fue respondida o reemplazada
+	ErrPropuestaCerrada = errors.New("la propuesta ya no está pendiente")
+	// ErrPropuestaExpirada indica que venció el plazo para responder la propuesta
+	ErrPropuestaExpirada = errors.New("la propuesta está vencida")
+	// ErrPartidoNoAgendable indica que el partido no está esperando horario
+	ErrPartidoNoAgendable = errors.New("el partido no está pendiente de agendar")
+)
+
+type PropuestaService interface {
+	GetPropuestas(partidoID int) ([]models.PropuestaHorario, error)
+	CreatePropuesta(partidoID int, jugadorID int, horarios []time.Time) (*models.PropuestaHorario, error)
+	CounterPropuesta(propuestaID int, jugadorID int, horarios []time.Time) (*models.PropuestaHorario, error)
+	AcceptPropuesta(propuestaID int, jugadorID int, opcionID int, actorID int) error
+	RejectPropuesta(propuestaID int, jugadorID int, motivo string) error
+	ExpirePropuestas() (int, error)
+	GetPartidosEscalados() ([]models.Partido, error)
+	AgendarPartido(partidoID int, fechaHora time.Time, actorID int) error
+}
+
+type propuestaServiceImpl struct {
+	vigencia time.Duration
+}
+
+// NewPropuestaService crea el servicio de propuestas; vigencia es el plazo que
+// tiene el rival para responder antes de que el partido pase al administrador
+func NewPropuestaService(vigencia time.Duration) PropuestaService {
+	return &propuestaServiceImpl{
+		vigencia: vigencia,
+	}
+}
+
+const propuestaSelectQuery = `
+	SELECT pr.id, pr.partido_id, pr.propuesto_por,
+	       COALESCE(j.nombre || ' ' || j.apellido, '') as propuesto_por_nombre,
+	       pr.estado, pr.respondida_por, pr.opcion_aceptada_id, pr.motivo, pr.expira_en,
+	       pr.created_at, pr.updated_at
+	FROM propuestas_horario pr
+	LEFT JOIN jugadores j ON pr.propuesto_por = j.id`
+
+func scanPropuesta(row rowScanner, pr *models.PropuestaHorario) error {
+	return row.Scan(
+		&pr.ID, &pr.PartidoID, &pr.PropuestoPor, &pr.PropuestoPorNombre,
+		&pr.Estado, &pr.RespondidaPor, &pr.OpcionAceptadaID, &pr.Motivo, &pr.ExpiraEn,
+		&pr.CreatedAt, &pr.UpdatedAt,
+	)
+}
+
+func (s *propuestaServiceImpl) GetPropuestas(partidoID int) ([]models.PropuestaHorario, error) {
+	return queryPropuestas(propuestaSelectQuery+` WHERE pr.partido_id = $1 ORDER BY pr.created_at DESC, pr.id DESC`, partidoID)
+}
+
+// queryPropuestas ejecuta una consulta basada en propuestaSelectQuery y carga los
+// horarios de cada propuesta
+func queryPropuestas(query string, args ...interface{}) ([]models.PropuestaHorario, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	propuestas := []models.PropuestaHorario{}
+	indices := make(map[int]int)
+	var ids []int64
+	for rows.Next() {
+		var pr models.PropuestaHorario
+		if err := scanPropuesta(rows, &pr); err != nil {
+			return nil, err
+		}
+		pr.Opciones = []models.OpcionHorario{}
+		indices[pr.ID] = len(propuestas)
+		ids = append(ids, int64(pr.ID))
+		propuestas = append(propuestas, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return propuestas, nil
+	}
+
+	opcionRows, err := database.DB.Query(`
+		SELECT id, propuesta_id, fecha_hora
+		FROM propuesta_opciones
+		WHERE propuesta_id = ANY($1)
+		ORDER BY fecha_hora`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer opcionRows.Close()
+
+	for opcionRows.Next() {
+		var o models.OpcionHorario
+		if err := opcionRows.Scan(&o.ID, &o.PropuestaID, &o.FechaHora); err != nil {
+			return nil, err
+		}
+		pr := &propuestas[indices[o.PropuestaID]]
+		pr.Opciones = append(pr.Opciones, o)
+	}
+
+	return propuestas, opcionRows.Err()
+}
+
+// ValidateHorarios exige entre 1 y MaxOpcionesPropuesta horarios futuros y distintos
+func ValidateHorarios(horarios []time.Time) error {
+	if len(horarios) == 0 || len(horarios) > MaxOpcionesPropuesta {
+		return ErrInvalidPropuesta
+	}
+
+	vistos := make(map[time.Time]bool, len(horarios))
+	ahora := time.Now()
+	for _, h := range horarios {
+		if !h.After(ahora) || vistos[h] {
+			return ErrInvalidPropuesta
+		}
+		vistos[h] = true
+	}
+	return nil
+}
+
+// CreatePropuesta registra los horarios que un jugador ofrece a su rival. Una
+// propuesta pendiente del mismo jugador queda reemplazada y una del rival queda
+// respondida con esta contrapropuesta.
+func (s *propuestaServiceImpl) CreatePropuesta(partidoID int, jugadorID int, horarios []time.Time) (*models.PropuestaHorario, error) {
+	if err := ValidateHorarios(horarios); err != nil {
+		return nil, err
+	}
+
+	var propuesta *models.PropuestaHorario
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		partido, err := lockPartido(tx, partidoID)
+		if err != nil {
+			if errors.Is(err, ErrPartidoNotFound) {
+				domainErr = err
+			}
+			return err
+		}
+
+		if !partido.TieneJugador(jugadorID) {
+			domainErr = ErrJugadorAjeno
+			return domainErr
+		}
+		if partido.Estado != models.EstadoPendiente {
+			domainErr = ErrPartidoNoAgendable
+			return domainErr
+		}
+
+		propuesta, err = s.insertPropuesta(tx, partidoID, jugadorID, horarios)
+		return err
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+
+	return propuesta, err
+}
+
+// CounterPropuesta responde una propuesta pendiente con otros horarios
+func (s *propuestaServiceImpl) CounterPropuesta(propuestaID int, jugadorID int, horarios []time.Time) (*models.PropuestaHorario, error) {
+	if err := ValidateHorarios(horarios); err != nil {
+		return nil, err
+	}
+
+	var propuesta *models.PropuestaHorario
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		original, partido, err := lockPropuestaParaResponder(tx, propuestaID, jugadorID)
+		if err != nil {
+			if isPropuestaDomainError(err) {
+				domainErr = err
+			}
+			return err
+		}
+
+		propuesta, err = s.insertPropuesta(tx, partido.ID, jugadorID, horarios)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE propuestas_horario SET respondida_por = $1 WHERE id = $2`, jugadorID, original.ID)
+		return err
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+
+	return propuesta, err
+}
+
+// insertPropuesta cierra las propuestas pendientes del partido e inserta la nueva
+func (s *propuestaServiceImpl) insertPropuesta(tx *sql.Tx, partidoID int, jugadorID int, horarios []time.Time) (*models.PropuestaHorario, error) {
+	_, err := tx.Exec(`
+		UPDATE propuestas_horario
+		SET estado = CASE WHEN propuesto_por = $2 THEN $3 ELSE $4 END, updated_at = NOW()
+		WHERE partido_id = $1 AND estado = $5`,
+		partidoID, jugadorID, models.PropuestaReemplazada, models.PropuestaContrapropuesta, models.PropuestaPendiente,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	propuesta := &models.PropuestaHorario{
+		PartidoID:    partidoID,
+		PropuestoPor: jugadorID,
+		Estado:       models.PropuestaPendiente,
+		ExpiraEn:     time.Now().Add(s.vigencia),
+	}
+	err = tx.QueryRow(`
+		INSERT INTO propuestas_horario (partido_id, propuesto_por, estado, expira_en, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id, created_at, updated_at`,
+		propuesta.PartidoID, propuesta.PropuestoPor, propuesta.Estado, propuesta.ExpiraEn,
+	).Scan(&propuesta.ID, &propuesta.CreatedAt, &propuesta.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range horarios {
+		opcion := models.OpcionHorario{PropuestaID: propuesta.ID, FechaHora: h}
+		err := tx.QueryRow(`
+			INSERT INTO propuesta_opciones (propuesta_id, fecha_hora) VALUES ($1, $2) RETURNING id`,
+			opcion.PropuestaID, opcion.FechaHora,
+		).Scan(&opcion.ID)
+		if err != nil {
+			return nil, err
+		}
+		propuesta.Opciones = append(propuesta.Opciones, opcion)
+	}
+
+	return propuesta, nil
+}
+
+// lockPropuestaParaResponder obtiene una propuesta pendiente y vigente junto a su
+// partido, verificando que quien responde sea el rival de quien la hizo
+func lockPropuestaParaResponder(tx *sql.Tx, propuestaID int, jugadorID int) (*models.PropuestaHorario, *models.Partido, error) {
+	var propuesta models.PropuestaHorario
+	err := scanPropuesta(tx.QueryRow(propuestaSelectQuery+` WHERE pr.id = $1 FOR UPDATE OF pr`, propuestaID), &propuesta)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrPropuestaNotFound
+		}
+		return nil, nil, err
+	}
+
+	partido, err := lockPartido(tx, propuesta.PartidoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !partido.TieneJugador(jugadorID) || propuesta.PropuestoPor == jugadorID {
+		return nil, nil, ErrJugadorAjeno
+	}
+	if propuesta.Estado != models.PropuestaPendiente {
+		return nil, nil, ErrPropuestaCerrada
+	}
+	if time.Now().After(propuesta.ExpiraEn) {
+		return nil, nil, ErrPropuestaExpirada
+	}
+	if partido.Estado != models.EstadoPendiente {
+		return nil, nil, ErrPartidoNoAgendable
+	}
+
+	return &propuesta, partido, nil
+}
+
+func isPropuestaDomainError(err error) bool {
+	for _, domainErr := range []error{
+		ErrPropuestaNotFound, ErrPartidoNotFound, ErrJugadorAjeno,
+		ErrPropuestaCerrada, ErrPropuestaExpirada, ErrPartidoNoAgendable,
+	} {
+		if errors.Is(err, domainErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptPropuesta acepta uno de los horarios ofrecidos y agenda el partido
+func (s *propuestaServiceImpl) AcceptPropuesta(propuestaID int, jugadorID int, opcionID int, actorID int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		propuesta, partido, err := lockPropuestaParaResponder(tx, propuestaID, jugadorID)
+		if err != nil {
+			if isPropuestaDomainError(err) {
+				domainErr = err
+			}
+			return err
+		}
+
+		var fechaHora time.Time
+		err = tx.QueryRow(`SELECT fecha_hora FROM propuesta_opciones WHERE id = $1 AND propuesta_id = $2`,
+			opcionID, propuesta.ID).Scan(&fechaHora)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				domainErr = ErrInvalidPropuesta
+				return domainErr
+			}
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE propuestas_horario
+			SET estado = $1, respondida_por = $2, opcion_aceptada_id = $3, updated_at = NOW()
+			WHERE id = $4`,
+			models.PropuestaAceptada, jugadorID, opcionID, propuesta.ID,
+		)
+		if err != nil {
+			return err
+		}
+
+		return agendar(tx, partido, fechaHora, actorID, "Horario acordado entre los jugadores")
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+
+	return err
+}
+
+// RejectPropuesta rechaza todos los horarios de una propuesta
+func (s *propuestaServiceImpl) RejectPropuesta(propuestaID int, jugadorID int, motivo string) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		propuesta, _, err := lockPropuestaParaResponder(tx, propuestaID, jugadorID)
+		if err != nil {
+			if isPropuestaDomainError(err) {
+				domainErr = err
+			}
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE propuestas_horario
+			SET estado = $1, respondida_por = $2, motivo = NULLIF($3, ''), updated_at = NOW()
+			WHERE id = $4`,
+			models.PropuestaRechazada, jugadorID, motivo, propuesta.ID,
+		)
+		return err
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+
+	return err
+}
+
+// ExpirePropuestas vence las propuestas sin respuesta dentro del plazo y marca sus
+// partidos para que los agende el administrador. Devuelve los partidos escalados.
+func (s *propuestaServiceImpl) ExpirePropuestas() (int, error) {
+	var escalados int
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		rows, err := tx.Query(`
+			UPDATE propuestas_horario
+			SET estado = $1, updated_at = NOW()
+			WHERE estado = $2 AND expira_en < NOW()
+			RETURNING partido_id`,
+			models.PropuestaExpirada, models.PropuestaPendiente,
+		)
+		if err != nil {
+			return err
+		}
+
+		var partidoIDs []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			partidoIDs = append(partidoIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(partidoIDs) == 0 {
+			return nil
+		}
+
+		result, err := tx.Exec(`
+			UPDATE partidos SET agenda_escalada = true, updated_at = NOW()
+			WHERE id = ANY($1) AND estado = $2 AND agenda_escalada = false`,
+			pq.Array(partidoIDs), models.EstadoPendiente,
+		)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		escalados = int(affected)
+		return err
+	})
+
+	return escalados, err
+}
+
+func (s *propuestaServiceImpl) GetPartidosEscalados() ([]models.Partido, error) {
+	return queryPartidos(partidoSelectQuery+`
+		WHERE p.agenda_escalada = true AND p.estado = $1
+		ORDER BY p.updated_at`, models.EstadoPendiente)
+}
+
+// AgendarPartido fija el horario de un partido por decisión del administrador,
+// cancelando las propuestas que seguían abiertas
+func (s *propuestaServiceImpl) AgendarPartido(partidoID int, fechaHora time.Time, actorID int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		partido, err := lockPartido(tx, partidoID)
+		if err != nil {
+			if errors.Is(err, ErrPartidoNotFound) {
+				domainErr = err
+			}
+			return err
+		}
+		if partido.Estado != models.EstadoPendiente {
+			domainErr = ErrPartidoNoAgendable
+			return domainErr
+		}
+
+		_, err = tx.Exec(`
+			UPDATE propuestas_horario SET estado = $1, updated_at = NOW()
+			WHERE partido_id = $2 AND estado = $3`,
+			models.PropuestaCancelada, partidoID, models.PropuestaPendiente,
+		)
+		if err != nil {
+			return err
+		}
+
+		return agendar(tx, partido, fechaHora, actorID, "Horario fijado por el administrador")
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+
+	return err
+}
+
+// agendar fija fecha y hora del partido y lo pasa a agendado
+func agendar(tx *sql.Tx, partido *models.Partido, fechaHora time.Time, actorID int, detalle string) error {
+	_, err := tx.Exec(`
+		UPDATE partidos
+		SET fecha_agendada = $1::timestamp::date, hora_agendada = $1::timestamp::time,
+		    agenda_escalada = false, updated_at = NOW()
+		WHERE id = $2`,
+		fechaHora, partido.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return changeEstado(tx, partido.ID, partido.Estado, models.EstadoAgendado, actorID, detalle)
+}
+
+// SchedulePropuestaExpiry revisa periódicamente las propuestas vencidas
+func SchedulePropuestaExpiry(service PropuestaService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			escalados, err := service.ExpirePropuestas()
+			if err != nil {
+				utils.LogError("Error al vencer propuestas de horario", err, nil)
+				continue
+			}
+			if escalados > 0 {
+				utils.LogInfo("Partidos escalados al administrador por falta de acuerdo", map[string]interface{}{
+					"partidos": escalados,
+				})
+			}
+		}
+	}()
+
+	utils.LogInfo("Revisión de propuestas de horario iniciada", map[string]interface{}{
+		"interval": interval.String(),
+	})
+}
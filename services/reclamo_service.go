@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+)
+
+const (
+	// vigenciaCodigoReclamo es el tiempo que tiene el jugador para usar el código
+	vigenciaCodigoReclamo = 24 * time.Hour
+	// maxIntentosCodigo es la cantidad de códigos incorrectos que invalidan el vigente
+	maxIntentosCodigo = 5
+	// minDigitosTelefono es la cantidad mínima de dígitos finales que deben coincidir
+	minDigitosTelefono = 8
+)
+
+var (
+	// ErrReclamoNotFound indica que el reclamo no existe o no pertenece al usuario
+	ErrReclamoNotFound = errors.New("reclamo no encontrado")
+	// ErrReclamoSinCoincidencia indica que ningún jugador coincide con el nombre y el teléfono
+	ErrReclamoSinCoincidencia = errors.New("no hay un jugador con ese nombre y teléfono")
+	// ErrReclamoCerrado indica que el reclamo ya fue aprobado o rechazado
+	ErrReclamoCerrado = errors.New("el reclamo ya no está pendiente")
+	// ErrReclamoDuplicado indica que el usuario ya tiene un reclamo pendiente
+	ErrReclamoDuplicado = errors.New("el usuario ya tiene un reclamo pendiente")
+	// ErrUsuarioConJugador indica que el usuario ya está vinculado a un jugador
+	ErrUsuarioConJugador = errors.New("el usuario ya está vinculado a un jugador")
+	// ErrCodigoReclamoInvalido indica un código incorrecto, vencido o no emitido
+	ErrCodigoReclamoInvalido = errors.New("código inválido o vencido")
+)
+
+// CodigoReclamo es el código que el administrador envía al teléfono del jugador.
+// Solo se muestra al emitirlo.
+type CodigoReclamo struct {
+	Codigo         string    `json:"codigo"`
+	ExpiraEn       time.Time `json:"expira_en"`
+	Telefono       string    `json:"telefono"`
+	EnlaceWhatsApp string    `json:"enlace_whatsapp"`
+}
+
+type ReclamoService interface {
+	CreateReclamo(usuarioID int, nombre, apellido, telefono string) (*models.ReclamoJugador, error)
+	GetReclamosUsuario(usuarioID int) ([]models.ReclamoJugador, error)
+	GetReclamosPendientes() ([]models.ReclamoJugador, error)
+	ApproveReclamo(id, actorID int) (*models.ReclamoJugador, error)
+	RejectReclamo(id, actorID int, nota string) (*models.ReclamoJugador, error)
+	IssueCodigo(id int) (*CodigoReclamo, error)
+	ConfirmReclamo(id, usuarioID int, codigo string) (*models.ReclamoJugador, error)
+}
+
+type reclamoServiceImpl struct{}
+
+func NewReclamoService() ReclamoService {
+	return &reclamoServiceImpl{}
+}
+
+const reclamoSelect = `
+	SELECT r.id, r.usuario_id, u.nombre_usuario, r.jugador_id, j.nombre || ' ' || j.apellido,
+	       r.estado, CASE WHEN r.codigo_hash IS NULL THEN NULL ELSE r.codigo_expira_en END,
+	       r.resuelto_por, r.nota, r.created_at, r.updated_at
+	FROM reclamos_jugador r
+	JOIN usuarios u ON u.id = r.usuario_id
+	JOIN jugadores j ON j.id = r.jugador_id`
+
+func scanReclamo(row rowScanner) (*models.ReclamoJugador, error) {
+	var r models.ReclamoJugador
+	err := row.Scan(&r.ID, &r.UsuarioID, &r.NombreUsuario, &r.JugadorID, &r.JugadorNombre,
+		&r.Estado, &r.CodigoExpiraEn, &r.ResueltoPor, &r.Nota, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// CreateReclamo busca el jugador que coincide con el nombre y el teléfono
+// informados y deja el reclamo pendiente de confirmación
+func (s *reclamoServiceImpl) CreateReclamo(usuarioID int, nombre, apellido, telefono string) (*models.ReclamoJugador, error) {
+	digitos := soloDigitos(telefono)
+	if len(digitos) < minDigitosTelefono {
+		return nil, ErrReclamoSinCoincidencia
+	}
+
+	var reclamoID int
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		var jugadorActual sql.NullInt32
+		err := tx.QueryRow(`SELECT jugador_id FROM usuarios WHERE id = $1 FOR UPDATE`, usuarioID).Scan(&jugadorActual)
+		if err != nil {
+			return err
+		}
+		if jugadorActual.Valid {
+			domainErr = ErrUsuarioConJugador
+			return domainErr
+		}
+
+		jugadorID, err := buscarJugadorReclamable(tx, nombre, apellido, digitos)
+		if err != nil {
+			if errors.Is(err, ErrReclamoSinCoincidencia) || errors.Is(err, ErrJugadorYaVinculado) {
+				domainErr = err
+			}
+			return err
+		}
+
+		err = tx.QueryRow(`
+			INSERT INTO reclamos_jugador (usuario_id, jugador_id, estado, created_at, updated_at)
+			VALUES ($1, $2, $3, NOW(), NOW())
+			RETURNING id`, usuarioID, jugadorID, models.ReclamoPendiente,
+		).Scan(&reclamoID)
+		if esViolacionUnica(err, "idx_reclamos_jugador_pendiente") {
+			domainErr = ErrReclamoDuplicado
+		}
+		return err
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getReclamo(reclamoID)
+}
+
+func (s *reclamoServiceImpl) GetReclamosUsuario(usuarioID int) ([]models.ReclamoJugador, error) {
+	return listarReclamos(reclamoSelect+` WHERE r.usuario_id = $1 ORDER BY r.created_at DESC`, usuarioID)
+}
+
+func (s *reclamoServiceImpl) GetReclamosPendientes() ([]models.ReclamoJugador, error) {
+	return listarReclamos(reclamoSelect+` WHERE r.estado = $1 ORDER BY r.created_at`, models.ReclamoPendiente)
+}
+
+// ApproveReclamo vincula el usuario al jugador reclamado por decisión de un administrador
+func (s *reclamoServiceImpl) ApproveReclamo(id, actorID int) (*models.ReclamoJugador, error) {
+	return s.resolver(id, func(tx *sql.Tx, r *models.ReclamoJugador) error {
+		return aprobarReclamo(tx, r, actorID)
+	})
+}
+
+// RejectReclamo cierra el reclamo sin vincular al usuario
+func (s *reclamoServiceImpl) RejectReclamo(id, actorID int, nota string) (*models.ReclamoJugador, error) {
+	return s.resolver(id, func(tx *sql.Tx, r *models.ReclamoJugador) error {
+		_, err := tx.Exec(`
+			UPDATE reclamos_jugador
+			SET estado = $1, resuelto_por = NULLIF($2, 0), nota = NULLIF($3, ''), codigo_hash = NULL, updated_at = NOW()
+			WHERE id = $4`, models.ReclamoRechazado, actorID, nota, r.ID)
+		return err
+	})
+}
+
+// IssueCodigo genera un código de confirmación nuevo para que el administrador lo
+// envíe al teléfono registrado del jugador. Reemplaza al código anterior.
+func (s *reclamoServiceImpl) IssueCodigo(id int) (*CodigoReclamo, error) {
+	codigo, err := utils.GenerateOneTimeCode(6)
+	if err != nil {
+		return nil, err
+	}
+
+	var resultado *CodigoReclamo
+	_, err = s.resolver(id, func(tx *sql.Tx, r *models.ReclamoJugador) error {
+		var telefono sql.NullString
+		if err := tx.QueryRow(`SELECT telefono_wsp FROM jugadores WHERE id = $1`, r.JugadorID).Scan(&telefono); err != nil {
+			return err
+		}
+
+		expiraEn := time.Now().Add(vigenciaCodigoReclamo)
+		_, err := tx.Exec(`
+			UPDATE reclamos_jugador
+			SET codigo_hash = $1, codigo_expira_en = $2, intentos_codigo = 0, updated_at = NOW()
+			WHERE id = $3`, utils.HashToken(codigo), expiraEn, r.ID)
+		if err != nil {
+			return err
+		}
+
+		mensaje := fmt.Sprintf("Tu código para vincular tu cuenta de Copa Litoral es %s", codigo)
+		resultado = &CodigoReclamo{
+			Codigo:         codigo,
+			ExpiraEn:       expiraEn,
+			Telefono:       telefono.String,
+			EnlaceWhatsApp: "https://wa.me/" + soloDigitos(telefono.String) + "?text=" + url.QueryEscape(mensaje),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resultado, nil
+}
+
+// ConfirmReclamo aprueba el reclamo con el código recibido en el teléfono del
+// jugador. Los intentos fallidos se cuentan y, al llegar al máximo, el código se anula.
+func (s *reclamoServiceImpl) ConfirmReclamo(id, usuarioID int, codigo string) (*models.ReclamoJugador, error) {
+	var codigoErr error
+	reclamo, err := s.resolver(id, func(tx *sql.Tx, r *models.ReclamoJugador) error {
+		if r.UsuarioID != usuarioID {
+			return ErrReclamoNotFound
+		}
+
+		var codigoHash sql.NullString
+		var intentos int
+		err := tx.QueryRow(`SELECT codigo_hash, intentos_codigo FROM reclamos_jugador WHERE id = $1`, r.ID).
+			Scan(&codigoHash, &intentos)
+		if err != nil {
+			return err
+		}
+
+		vencido := !r.CodigoExpiraEn.Valid || time.Now().After(r.CodigoExpiraEn.Time)
+		if !codigoHash.Valid || vencido || codigoHash.String != utils.HashToken(codigo) {
+			// El intento fallido se confirma igual para que cuente
+			codigoErr = ErrCodigoReclamoInvalido
+			_, err := tx.Exec(`
+				UPDATE reclamos_jugador
+				SET intentos_codigo = intentos_codigo + 1,
+				    codigo_hash = CASE WHEN intentos_codigo + 1 >= $1 THEN NULL ELSE codigo_hash END,
+				    updated_at = NOW()
+				WHERE id = $2`, maxIntentosCodigo, r.ID)
+			return err
+		}
+
+		return aprobarReclamo(tx, r, usuarioID)
+	})
+	if codigoErr != nil {
+		return nil, codigoErr
+	}
+	return reclamo, err
+}
+
+func (s *reclamoServiceImpl) getReclamo(id int) (*models.ReclamoJugador, error) {
+	r, err := scanReclamo(database.DB.QueryRow(reclamoSelect+` WHERE r.id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrReclamoNotFound
+	}
+	return r, err
+}
+
+// resolver bloquea un reclamo pendiente y aplica el cambio en una transacción
+func (s *reclamoServiceImpl) resolver(id int, cambio func(tx *sql.Tx, r *models.ReclamoJugador) error) (*models.ReclamoJugador, error) {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		r, err := scanReclamo(tx.QueryRow(reclamoSelect+` WHERE r.id = $1 FOR UPDATE OF r`, id))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				domainErr = ErrReclamoNotFound
+			}
+			return err
+		}
+		if r.Estado != models.ReclamoPendiente {
+			domainErr = ErrReclamoCerrado
+			return domainErr
+		}
+
+		err = cambio(tx, r)
+		if errors.Is(err, ErrReclamoNotFound) || errors.Is(err, ErrJugadorYaVinculado) ||
+			errors.Is(err, ErrJugadorNoEncontrado) || errors.Is(err, ErrUsuarioConJugador) {
+			domainErr = err
+		}
+		return err
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getReclamo(id)
+}
+
+// aprobarReclamo vincula al usuario con el jugador, cierra el reclamo y rechaza
+// los demás reclamos pendientes sobre el mismo jugador
+func aprobarReclamo(tx *sql.Tx, r *models.ReclamoJugador, actorID int) error {
+	var jugadorActual sql.NullInt32
+	if err := tx.QueryRow(`SELECT jugador_id FROM usuarios WHERE id = $1 FOR UPDATE`, r.UsuarioID).Scan(&jugadorActual); err != nil {
+		return err
+	}
+	if jugadorActual.Valid {
+		return ErrUsuarioConJugador
+	}
+
+	if err := vincularJugador(tx, r.UsuarioID, r.JugadorID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`
+		UPDATE reclamos_jugador
+		SET estado = $1, resuelto_por = NULLIF($2, 0), codigo_hash = NULL, updated_at = NOW()
+		WHERE id = $3`, models.ReclamoAprobado, actorID, r.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE reclamos_jugador
+		SET estado = $1, nota = 'El jugador fue vinculado a otro usuario', codigo_hash = NULL, updated_at = NOW()
+		WHERE jugador_id = $2 AND estado = $3`, models.ReclamoRechazado, r.JugadorID, models.ReclamoPendiente)
+	if err != nil {
+		return err
+	}
+
+	return recordAuditoria(tx, r.UsuarioID, actorID, models.AuditoriaReclamoJugador, "", valorJugador(r.JugadorID))
+}
+
+// buscarJugadorReclamable devuelve el jugador sin cuenta cuyo nombre y teléfono
+// coinciden con los informados
+func buscarJugadorReclamable(tx *sql.Tx, nombre, apellido, digitos string) (int, error) {
+	sufijo := digitos[len(digitos)-minDigitosTelefono:]
+	rows, err := tx.Query(`
+		SELECT j.id, j.nombre, j.apellido, j.telefono_wsp, u.id IS NOT NULL
+		FROM jugadores j
+		LEFT JOIN usuarios u ON u.jugador_id = j.id
+		WHERE regexp_replace(j.telefono_wsp, '\D', '', 'g') LIKE '%' || $1`, sufijo)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var jNombre, jApellido string
+		var telefono sql.NullString
+		var vinculado bool
+		if err := rows.Scan(&id, &jNombre, &jApellido, &telefono, &vinculado); err != nil {
+			return 0, err
+		}
+		if !NombresCoinciden(jNombre, nombre) || !NombresCoinciden(jApellido, apellido) ||
+			!TelefonosCoinciden(telefono.String, digitos) {
+			continue
+		}
+		if vinculado {
+			return 0, ErrJugadorYaVinculado
+		}
+		return id, nil
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, ErrReclamoSinCoincidencia
+}
+
+func listarReclamos(query string, args ...interface{}) ([]models.ReclamoJugador, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reclamos := []models.ReclamoJugador{}
+	for rows.Next() {
+		r, err := scanReclamo(rows)
+		if err != nil {
+			return nil, err
+		}
+		reclamos = append(reclamos, *r)
+	}
+
+	return reclamos, rows.Err()
+}
+
+// NombresCoinciden compara nombres sin distinguir mayúsculas, tildes ni espacios repetidos
+func NombresCoinciden(a, b string) bool {
+	return normalizarNombre(a) == normalizarNombre(b) && normalizarNombre(a) != ""
+}
+
+// TelefonosCoinciden compara teléfonos por sus últimos dígitos, para que coincidan
+// con o sin código de país o de área (ej: +54 9 341 555-1234 y 3415551234)
+func TelefonosCoinciden(a, b string) bool {
+	a, b = soloDigitos(a), soloDigitos(b)
+	if len(a) < minDigitosTelefono || len(b) < minDigitosTelefono {
+		return false
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return strings.HasSuffix(b, a)
+}
+
+var sinTildes = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u")
+
+func normalizarNombre(s string) string {
+	return strings.Join(strings.Fields(sinTildes.Replace(strings.ToLower(s))), " ")
+}
+
+func soloDigitos(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
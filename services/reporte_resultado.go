@@ -0,0 +1,471 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrResultadoEnDisputa indica que los jugadores reportaron resultados distintos
+	ErrResultadoEnDisputa = errors.New("el resultado del partido está en disputa")
+	// ErrSinDisputa indica que el partido no tiene una disputa abierta para resolver
+	ErrSinDisputa = errors.New("el partido no tiene una disputa abierta")
+)
+
+// ReportMatchResult registra la versión del resultado que informa uno de los
+// jugadores, validada contra el formato de puntuación del torneo. Si coincide con
+// la del rival el partido queda reportado hasta que se apruebe el resultado; si no,
+// se abre una disputa. Un nuevo reporte del mismo jugador reemplaza al anterior.
+// jugadorID 0 indica que el resultado lo carga un administrador y queda reportado
+// sin esperar a los jugadores. Devuelve el estado en que quedó el reporte.
+func (s *partidoServiceImpl) ReportMatchResult(partidoID int, jugadorID int, setsGanadosJ1 int, setsGanadosJ2 int, ganadorID int, sets []models.SetPartido, actorID int) (models.EstadoReporte, error) {
+	var estado models.EstadoReporte
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		partido, err := lockPartido(tx, partidoID)
+		if err != nil {
+			if errors.Is(err, ErrPartidoNotFound) {
+				domainErr = err
+			}
+			return err
+		}
+
+		if jugadorID != 0 && !partido.TieneJugador(jugadorID) {
+			domainErr = ErrJugadorAjeno
+			return domainErr
+		}
+		if partido.Jugador1ID == 0 || partido.Jugador2ID == 0 {
+			domainErr = ErrRivalesPorDefinir
+			return domainErr
+		}
+		if partido.Estado == models.EstadoEnDisputa {
+			domainErr = ErrResultadoEnDisputa
+			return domainErr
+		}
+		if err := checkTransition(partido.Estado, models.EstadoReportado); err != nil {
+			domainErr = err
+			return domainErr
+		}
+
+		score, err := validarResultado(tx, partido, setsGanadosJ1, setsGanadosJ2, ganadorID, sets)
+		if err != nil {
+			var scoreErr *ScoreValidationError
+			if errors.As(err, &scoreErr) {
+				domainErr = err
+			}
+			return err
+		}
+
+		if jugadorID == 0 {
+			estado = models.ReporteConfirmado
+			_, err := tx.Exec(`
+				UPDATE reportes_resultado SET estado = $1, updated_at = NOW()
+				WHERE partido_id = $2 AND estado IN ($3, $4)`,
+				models.ReporteReemplazado, partidoID, models.ReportePendiente, models.ReporteConfirmado,
+			)
+			if err != nil {
+				return err
+			}
+			return guardarResultado(tx, partido, score, models.EstadoReportado, actorID, "Resultado cargado por el administrador")
+		}
+
+		estado, err = registrarReporte(tx, partido, jugadorID, score, actorID)
+		return err
+	})
+	if domainErr != nil {
+		return "", domainErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return estado, nil
+}
+
+// ResolveDispute cierra la disputa de un partido con el resultado final que fija
+// el administrador. El resultado queda aprobado y el ganador avanza en la llave.
+func (s *partidoServiceImpl) ResolveDispute(partidoID int, setsGanadosJ1 int, setsGanadosJ2 int, ganadorID int, sets []models.SetPartido, nota string, actorID int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		partido, err := lockPartido(tx, partidoID)
+		if err != nil {
+			if errors.Is(err, ErrPartidoNotFound) {
+				domainErr = err
+			}
+			return err
+		}
+
+		if partido.Estado != models.EstadoEnDisputa {
+			domainErr = ErrSinDisputa
+			return domainErr
+		}
+
+		score, err := validarResultado(tx, partido, setsGanadosJ1, setsGanadosJ2, ganadorID, sets)
+		var scoreErr *ScoreValidationError
+		if err != nil && !errors.As(err, &scoreErr) {
+			return err
+		}
+		if strings.TrimSpace(nota) == "" {
+			if scoreErr == nil {
+				scoreErr = &ScoreValidationError{}
+			}
+			scoreErr.Errors = append(scoreErr.Errors, utils.CreateFieldError("nota", utils.ErrMissingField,
+				"se requiere una nota que explique la resolución", nil))
+		}
+		if scoreErr != nil {
+			domainErr = scoreErr
+			return domainErr
+		}
+
+		if err := guardarResultado(tx, partido, score, models.EstadoFinalizado, actorID, "Disputa resuelta: "+nota); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`UPDATE partidos SET resultado_aprobado = true WHERE id = $1`, partidoID); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE disputas_resultado
+			SET estado = $1, resuelta_por = NULLIF($2, 0), nota = $3, resuelta_en = NOW()
+			WHERE partido_id = $4 AND estado = $5`,
+			models.DisputaResuelta, actorID, nota, partidoID, models.DisputaAbierta,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE reportes_resultado SET estado = $1, updated_at = NOW()
+			WHERE partido_id = $2 AND estado = $3`,
+			models.ReporteResuelto, partidoID, models.ReporteEnDisputa,
+		)
+		if err != nil {
+			return err
+		}
+
+		partido.GanadorID = sql.NullInt32{Int32: int32(ganadorID), Valid: true}
+		domainErr = advanceWinner(tx, partido)
+		return domainErr
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+
+	return err
+}
+
+// GetReportes devuelve todos los reportes de resultado de un partido, incluidos
+// los reemplazados, del más reciente al más antiguo
+func (s *partidoServiceImpl) GetReportes(partidoID int) ([]models.ReporteResultado, error) {
+	if _, err := s.GetPartidoByID(partidoID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT r.id, r.partido_id, r.jugador_id, COALESCE(j.nombre || ' ' || j.apellido, '') as jugador_nombre,
+		       r.sets_ganados_j1, r.sets_ganados_j2, r.ganador_id, r.estado, r.created_at, r.updated_at
+		FROM reportes_resultado r
+		LEFT JOIN jugadores j ON r.jugador_id = j.id
+		WHERE r.partido_id = $1
+		ORDER BY r.created_at DESC, r.id DESC`
+
+	rows, err := database.DB.Query(query, partidoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reportes := []models.ReporteResultado{}
+	indices := make(map[int]int)
+	var ids []int64
+	for rows.Next() {
+		var r models.ReporteResultado
+		err := rows.Scan(
+			&r.ID, &r.PartidoID, &r.JugadorID, &r.JugadorNombre,
+			&r.SetsGanadosJ1, &r.SetsGanadosJ2, &r.GanadorID, &r.Estado, &r.CreatedAt, &r.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		r.Sets = []models.SetPartido{}
+		indices[r.ID] = len(reportes)
+		ids = append(ids, int64(r.ID))
+		reportes = append(reportes, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return reportes, nil
+	}
+
+	setRows, err := database.DB.Query(`
+		SELECT id, reporte_id, numero_set, score_jugador1, score_jugador2, tie_break_j1, tie_break_j2
+		FROM reporte_sets
+		WHERE reporte_id = ANY($1)
+		ORDER BY reporte_id, numero_set`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer setRows.Close()
+
+	for setRows.Next() {
+		var reporteID int
+		var set models.SetPartido
+		err := setRows.Scan(&set.ID, &reporteID, &set.NumeroSet, &set.ScoreJugador1, &set.ScoreJugador2,
+			&set.TieBreakJ1, &set.TieBreakJ2)
+		if err != nil {
+			return nil, err
+		}
+		r := &reportes[indices[reporteID]]
+		set.PartidoID = r.PartidoID
+		r.Sets = append(r.Sets, set)
+	}
+
+	return reportes, setRows.Err()
+}
+
+// GetDisputasAbiertas devuelve las disputas que esperan la resolución de un administrador
+func (s *partidoServiceImpl) GetDisputasAbiertas() ([]models.DisputaResultado, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, partido_id, estado, resuelta_por, nota, resuelta_en, created_at
+		FROM disputas_resultado
+		WHERE estado = $1
+		ORDER BY created_at`, models.DisputaAbierta)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	disputas := []models.DisputaResultado{}
+	for rows.Next() {
+		var d models.DisputaResultado
+		if err := rows.Scan(&d.ID, &d.PartidoID, &d.Estado, &d.ResueltaPor, &d.Nota, &d.ResueltaEn, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		disputas = append(disputas, d)
+	}
+
+	return disputas, rows.Err()
+}
+
+// ResultadosCoinciden indica si dos reportes informan el mismo resultado, set por set
+func ResultadosCoinciden(a, b MatchScore) bool {
+	if a.GanadorID != b.GanadorID || a.SetsGanadosJ1 != b.SetsGanadosJ1 ||
+		a.SetsGanadosJ2 != b.SetsGanadosJ2 || len(a.Sets) != len(b.Sets) {
+		return false
+	}
+
+	for i := range a.Sets {
+		x, y := a.Sets[i], b.Sets[i]
+		if x.ScoreJugador1 != y.ScoreJugador1 || x.ScoreJugador2 != y.ScoreJugador2 ||
+			!mismoTieBreak(x.TieBreakJ1, y.TieBreakJ1) || !mismoTieBreak(x.TieBreakJ2, y.TieBreakJ2) {
+			return false
+		}
+	}
+	return true
+}
+
+func mismoTieBreak(a, b sql.NullInt32) bool {
+	return a.Valid == b.Valid && (!a.Valid || a.Int32 == b.Int32)
+}
+
+// validarResultado controla un resultado completo contra el formato del torneo del
+// partido. Los errores de puntuación se devuelven como *ScoreValidationError.
+func validarResultado(tx *sql.Tx, partido *models.Partido, setsGanadosJ1, setsGanadosJ2, ganadorID int, sets []models.SetPartido) (MatchScore, error) {
+	formato, err := formatoDelTorneo(tx, partido.TorneoID)
+	if err != nil {
+		return MatchScore{}, err
+	}
+
+	score := MatchScore{
+		Jugador1ID:    partido.Jugador1ID,
+		Jugador2ID:    partido.Jugador2ID,
+		SetsGanadosJ1: setsGanadosJ1,
+		SetsGanadosJ2: setsGanadosJ2,
+		GanadorID:     ganadorID,
+		Sets:          sets,
+	}
+	if validationErr := ValidateMatchScore(formato, score); validationErr != nil {
+		return MatchScore{}, validationErr
+	}
+	return score, nil
+}
+
+// guardarResultado escribe el resultado en el partido, reemplaza sus sets y lo
+// lleva al estado indicado
+func guardarResultado(tx *sql.Tx, partido *models.Partido, score MatchScore, estado models.EstadoPartido, actorID int, detalle string) error {
+	perdedorID := partido.Jugador1ID
+	if score.GanadorID == partido.Jugador1ID {
+		perdedorID = partido.Jugador2ID
+	}
+
+	_, err := tx.Exec(`
+		UPDATE partidos
+		SET resultado_sets_j1 = $1, resultado_sets_j2 = $2, ganador_id = $3, perdedor_id = $4,
+		    tipo_resultado = $5, motivo_resultado = NULL, updated_at = NOW()
+		WHERE id = $6`,
+		score.SetsGanadosJ1, score.SetsGanadosJ2, score.GanadorID, perdedorID,
+		models.TipoResultadoNormal, partido.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceSets(tx, partido.ID, score.Sets); err != nil {
+		return err
+	}
+
+	return changeEstado(tx, partido.ID, partido.Estado, estado, actorID, detalle)
+}
+
+// registrarReporte guarda el reporte del jugador y lo compara con el reporte
+// vigente del rival. Si coinciden el resultado pasa al partido; si no, se abre
+// una disputa y el partido queda sin resultado hasta que se resuelva.
+func registrarReporte(tx *sql.Tx, partido *models.Partido, jugadorID int, score MatchScore, actorID int) (models.EstadoReporte, error) {
+	_, err := tx.Exec(`
+		UPDATE reportes_resultado SET estado = $1, updated_at = NOW()
+		WHERE partido_id = $2 AND jugador_id = $3 AND estado IN ($4, $5)`,
+		models.ReporteReemplazado, partido.ID, jugadorID, models.ReportePendiente, models.ReporteConfirmado,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	reporteID, err := insertReporte(tx, partido.ID, jugadorID, score)
+	if err != nil {
+		return "", err
+	}
+
+	rivalID, rival, err := reporteVigenteDelRival(tx, partido.ID, jugadorID)
+	if err == sql.ErrNoRows {
+		return models.ReportePendiente, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if ResultadosCoinciden(score, rival) {
+		if err := marcarReportes(tx, models.ReporteConfirmado, reporteID, rivalID); err != nil {
+			return "", err
+		}
+		err := guardarResultado(tx, partido, score, models.EstadoReportado, actorID, "Resultado confirmado por ambos jugadores")
+		return models.ReporteConfirmado, err
+	}
+
+	if err := marcarReportes(tx, models.ReporteEnDisputa, reporteID, rivalID); err != nil {
+		return "", err
+	}
+
+	// Un resultado confirmado antes de la corrección deja de ser válido
+	_, err = tx.Exec(`
+		UPDATE partidos
+		SET resultado_sets_j1 = NULL, resultado_sets_j2 = NULL, ganador_id = NULL, perdedor_id = NULL,
+		    updated_at = NOW()
+		WHERE id = $1`, partido.ID)
+	if err != nil {
+		return "", err
+	}
+	if err := replaceSets(tx, partido.ID, nil); err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO disputas_resultado (partido_id, estado, created_at)
+		VALUES ($1, $2, NOW())`, partido.ID, models.DisputaAbierta)
+	if err != nil {
+		return "", err
+	}
+
+	err = changeEstado(tx, partido.ID, partido.Estado, models.EstadoEnDisputa, actorID, "Los reportes de los jugadores no coinciden")
+	return models.ReporteEnDisputa, err
+}
+
+// insertReporte guarda un reporte de resultado junto a sus sets
+func insertReporte(tx *sql.Tx, partidoID int, jugadorID int, score MatchScore) (int, error) {
+	var reporteID int
+	err := tx.QueryRow(`
+		INSERT INTO reportes_resultado (partido_id, jugador_id, sets_ganados_j1, sets_ganados_j2,
+		                                ganador_id, estado, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id`,
+		partidoID, jugadorID, score.SetsGanadosJ1, score.SetsGanadosJ2, score.GanadorID, models.ReportePendiente,
+	).Scan(&reporteID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, set := range score.Sets {
+		_, err := tx.Exec(`
+			INSERT INTO reporte_sets (reporte_id, numero_set, score_jugador1, score_jugador2, tie_break_j1, tie_break_j2)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			reporteID, set.NumeroSet, set.ScoreJugador1, set.ScoreJugador2, set.TieBreakJ1, set.TieBreakJ2,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return reporteID, nil
+}
+
+// reporteVigenteDelRival obtiene el último reporte sin reemplazar del otro jugador;
+// devuelve sql.ErrNoRows si el rival todavía no reportó
+func reporteVigenteDelRival(tx *sql.Tx, partidoID int, jugadorID int) (int, MatchScore, error) {
+	var reporteID int
+	var score MatchScore
+	err := tx.QueryRow(`
+		SELECT id, sets_ganados_j1, sets_ganados_j2, ganador_id
+		FROM reportes_resultado
+		WHERE partido_id = $1 AND jugador_id <> $2 AND estado IN ($3, $4)
+		ORDER BY id DESC
+		LIMIT 1
+		FOR UPDATE`,
+		partidoID, jugadorID, models.ReportePendiente, models.ReporteConfirmado,
+	).Scan(&reporteID, &score.SetsGanadosJ1, &score.SetsGanadosJ2, &score.GanadorID)
+	if err != nil {
+		return 0, MatchScore{}, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT numero_set, score_jugador1, score_jugador2, tie_break_j1, tie_break_j2
+		FROM reporte_sets
+		WHERE reporte_id = $1
+		ORDER BY numero_set`, reporteID)
+	if err != nil {
+		return 0, MatchScore{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var set models.SetPartido
+		if err := rows.Scan(&set.NumeroSet, &set.ScoreJugador1, &set.ScoreJugador2, &set.TieBreakJ1, &set.TieBreakJ2); err != nil {
+			return 0, MatchScore{}, err
+		}
+		score.Sets = append(score.Sets, set)
+	}
+
+	return reporteID, score, rows.Err()
+}
+
+func marcarReportes(tx *sql.Tx, estado models.EstadoReporte, ids ...int) error {
+	reportes := make([]int64, len(ids))
+	for i, id := range ids {
+		reportes[i] = int64(id)
+	}
+
+	_, err := tx.Exec(`UPDATE reportes_resultado SET estado = $1, updated_at = NOW() WHERE id = ANY($2)`,
+		estado, pq.Array(reportes))
+	return err
+}
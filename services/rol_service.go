@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrRolNotFound indica que el rol no existe
+	ErrRolNotFound = errors.New("rol no encontrado")
+	// ErrRolSistema impide editar o asignar los roles base administrador y jugador
+	ErrRolSistema = errors.New("los roles de sistema no se editan ni se asignan; use el rol base del usuario")
+	// ErrRolDuplicado indica que ya existe un rol con ese nombre
+	ErrRolDuplicado = errors.New("ya existe un rol con ese nombre")
+	// ErrPermisoInvalido indica un permiso fuera del catálogo
+	ErrPermisoInvalido = errors.New("permiso inválido")
+	// ErrAsignacionNotFound indica que el usuario no tiene esa asignación de rol
+	ErrAsignacionNotFound = errors.New("asignación de rol no encontrada")
+	// ErrAsignacionDuplicada indica que el usuario ya tiene el rol con ese alcance
+	ErrAsignacionDuplicada = errors.New("el usuario ya tiene ese rol con el mismo alcance")
+	// ErrAlcanceNoEncontrado indica que el torneo o la categoría del alcance no existen
+	ErrAlcanceNoEncontrado = errors.New("el torneo o la categoría del alcance no existen")
+)
+
+type RolService interface {
+	GetRoles() ([]models.Rol, error)
+	CreateRol(rol *models.Rol) error
+	UpdateRol(id int, rol *models.Rol) error
+	DeleteRol(id int) error
+	GetAsignaciones(usuarioID int) ([]models.AsignacionRol, error)
+	AsignarRol(asignacion *models.AsignacionRol, actorID int) error
+	RevocarRol(usuarioID, asignacionID, actorID int) error
+	GetConcesiones(usuarioID int) (models.Concesiones, error)
+	AlcancePartido(id int) (models.Alcance, error)
+	AlcanceGrupo(id int) (models.Alcance, error)
+	AlcanceJugador(id int) (models.Alcance, error)
+}
+
+type rolServiceImpl struct{}
+
+func NewRolService() RolService {
+	return &rolServiceImpl{}
+}
+
+func (s *rolServiceImpl) GetRoles() ([]models.Rol, error) {
+	rows, err := database.DB.Query(`
+		SELECT r.id, r.nombre, COALESCE(r.descripcion, ''), r.sistema, r.created_at, r.updated_at,
+		       COALESCE(array_agg(rp.permiso ORDER BY rp.permiso) FILTER (WHERE rp.permiso IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN rol_permisos rp ON rp.rol_id = r.id
+		GROUP BY r.id
+		ORDER BY r.sistema DESC, r.nombre`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []models.Rol{}
+	for rows.Next() {
+		var r models.Rol
+		var permisos pq.StringArray
+		if err := rows.Scan(&r.ID, &r.Nombre, &r.Descripcion, &r.Sistema, &r.CreatedAt, &r.UpdatedAt, &permisos); err != nil {
+			return nil, err
+		}
+		r.Permisos = []string(permisos)
+		roles = append(roles, r)
+	}
+
+	return roles, rows.Err()
+}
+
+func (s *rolServiceImpl) CreateRol(rol *models.Rol) error {
+	permisos, err := normalizarPermisos(rol.Permisos)
+	if err != nil {
+		return err
+	}
+	rol.Permisos = permisos
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err = txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		err := tx.QueryRow(`
+			INSERT INTO roles (nombre, descripcion, sistema, created_at, updated_at)
+			VALUES ($1, NULLIF($2, ''), FALSE, NOW(), NOW())
+			RETURNING id, created_at, updated_at`, rol.Nombre, rol.Descripcion,
+		).Scan(&rol.ID, &rol.CreatedAt, &rol.UpdatedAt)
+		if esViolacionUnica(err, "roles_nombre_key") {
+			domainErr = ErrRolDuplicado
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		return guardarPermisos(tx, rol.ID, permisos)
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+// UpdateRol reemplaza la descripción y los permisos de un rol. El nombre no cambia
+// porque identifica al rol en la documentación y en los registros.
+func (s *rolServiceImpl) UpdateRol(id int, rol *models.Rol) error {
+	permisos, err := normalizarPermisos(rol.Permisos)
+	if err != nil {
+		return err
+	}
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err = txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		var sistema bool
+		err := tx.QueryRow(`SELECT sistema FROM roles WHERE id = $1 FOR UPDATE`, id).Scan(&sistema)
+		if err == sql.ErrNoRows {
+			domainErr = ErrRolNotFound
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if sistema {
+			domainErr = ErrRolSistema
+			return domainErr
+		}
+
+		_, err = tx.Exec(`UPDATE roles SET descripcion = NULLIF($1, ''), updated_at = NOW() WHERE id = $2`, rol.Descripcion, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM rol_permisos WHERE rol_id = $1`, id); err != nil {
+			return err
+		}
+		return guardarPermisos(tx, id, permisos)
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+// DeleteRol borra un rol junto con sus asignaciones
+func (s *rolServiceImpl) DeleteRol(id int) error {
+	var sistema bool
+	err := database.DB.QueryRow(`SELECT sistema FROM roles WHERE id = $1`, id).Scan(&sistema)
+	if err == sql.ErrNoRows {
+		return ErrRolNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if sistema {
+		return ErrRolSistema
+	}
+
+	_, err = database.DB.Exec(`DELETE FROM roles WHERE id = $1`, id)
+	return err
+}
+
+func (s *rolServiceImpl) GetAsignaciones(usuarioID int) ([]models.AsignacionRol, error) {
+	if err := usuarioExiste(database.DB, usuarioID); err != nil {
+		return nil, err
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT ur.id, ur.usuario_id, ur.rol_id, r.nombre, ur.torneo_id, ur.categoria_id, ur.created_at
+		FROM usuario_roles ur
+		JOIN roles r ON r.id = ur.rol_id
+		WHERE ur.usuario_id = $1
+		ORDER BY r.nombre, ur.id`, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	asignaciones := []models.AsignacionRol{}
+	for rows.Next() {
+		var a models.AsignacionRol
+		if err := rows.Scan(&a.ID, &a.UsuarioID, &a.RolID, &a.RolNombre, &a.TorneoID, &a.CategoriaID, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		asignaciones = append(asignaciones, a)
+	}
+
+	return asignaciones, rows.Err()
+}
+
+// AsignarRol otorga un rol adicional al usuario, opcionalmente limitado a un torneo
+// o a una categoría. Los permisos rigen desde la próxima solicitud.
+func (s *rolServiceImpl) AsignarRol(asignacion *models.AsignacionRol, actorID int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		if err := usuarioExiste(tx, asignacion.UsuarioID); err != nil {
+			domainErr = err
+			return err
+		}
+
+		var sistema bool
+		err := tx.QueryRow(`SELECT nombre, sistema FROM roles WHERE id = $1`, asignacion.RolID).
+			Scan(&asignacion.RolNombre, &sistema)
+		if err == sql.ErrNoRows {
+			domainErr = ErrRolNotFound
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if sistema {
+			domainErr = ErrRolSistema
+			return domainErr
+		}
+
+		err = tx.QueryRow(`
+			INSERT INTO usuario_roles (usuario_id, rol_id, torneo_id, categoria_id, asignado_por, created_at)
+			VALUES ($1, $2, $3, $4, NULLIF($5, 0), NOW())
+			RETURNING id, created_at`,
+			asignacion.UsuarioID, asignacion.RolID, asignacion.TorneoID, asignacion.CategoriaID, actorID,
+		).Scan(&asignacion.ID, &asignacion.CreatedAt)
+		if esViolacionUnica(err, "idx_usuario_roles_unico") {
+			domainErr = ErrAsignacionDuplicada
+			return err
+		}
+		if esViolacionForanea(err) {
+			domainErr = ErrAlcanceNoEncontrado
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordAuditoria(tx, asignacion.UsuarioID, actorID, models.AuditoriaAsignacionRol, "", describirAsignacion(asignacion))
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+func (s *rolServiceImpl) RevocarRol(usuarioID, asignacionID, actorID int) error {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		var a models.AsignacionRol
+		err := tx.QueryRow(`
+			DELETE FROM usuario_roles ur
+			USING roles r
+			WHERE ur.id = $1 AND ur.usuario_id = $2 AND r.id = ur.rol_id
+			RETURNING ur.id, r.nombre, ur.torneo_id, ur.categoria_id`, asignacionID, usuarioID,
+		).Scan(&a.ID, &a.RolNombre, &a.TorneoID, &a.CategoriaID)
+		if err == sql.ErrNoRows {
+			domainErr = ErrAsignacionNotFound
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordAuditoria(tx, usuarioID, actorID, models.AuditoriaRevocacionRol, describirAsignacion(&a), "")
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+	return err
+}
+
+// GetConcesiones devuelve los permisos efectivos del usuario: los de su rol base,
+// sin límite, más los de los roles asignados con su alcance
+func (s *rolServiceImpl) GetConcesiones(usuarioID int) (models.Concesiones, error) {
+	rows, err := database.DB.Query(`
+		SELECT rp.permiso, 0, 0
+		FROM usuarios u
+		JOIN roles r ON r.nombre = u.rol
+		JOIN rol_permisos rp ON rp.rol_id = r.id
+		WHERE u.id = $1 AND u.activo
+		UNION ALL
+		SELECT rp.permiso, COALESCE(ur.torneo_id, 0), COALESCE(ur.categoria_id, 0)
+		FROM usuario_roles ur
+		JOIN usuarios u ON u.id = ur.usuario_id
+		JOIN rol_permisos rp ON rp.rol_id = ur.rol_id
+		WHERE ur.usuario_id = $1 AND u.activo`, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	concesiones := models.Concesiones{}
+	for rows.Next() {
+		var c models.Concesion
+		if err := rows.Scan(&c.Permiso, &c.TorneoID, &c.CategoriaID); err != nil {
+			return nil, err
+		}
+		concesiones = append(concesiones, c)
+	}
+
+	return concesiones, rows.Err()
+}
+
+// AlcancePartido devuelve el torneo y la categoría del partido; un partido
+// inexistente tiene alcance vacío y solo lo atraviesa un permiso sin límite
+func (s *rolServiceImpl) AlcancePartido(id int) (models.Alcance, error) {
+	return alcanceDe(`SELECT COALESCE(torneo_id, 0), COALESCE(categoria_id, 0) FROM partidos WHERE id = $1`, id)
+}
+
+// AlcanceGrupo devuelve el torneo y la categoría del grupo
+func (s *rolServiceImpl) AlcanceGrupo(id int) (models.Alcance, error) {
+	return alcanceDe(`SELECT torneo_id, categoria_id FROM grupos WHERE id = $1`, id)
+}
+
+// AlcanceJugador devuelve la categoría del jugador; sin categoría solo lo atraviesa
+// un permiso sin límite
+func (s *rolServiceImpl) AlcanceJugador(id int) (models.Alcance, error) {
+	return alcanceDe(`SELECT 0, COALESCE(categoria_id, 0) FROM jugadores WHERE id = $1`, id)
+}
+
+func alcanceDe(query string, id int) (models.Alcance, error) {
+	var alcance models.Alcance
+	err := database.DB.QueryRow(query, id).Scan(&alcance.TorneoID, &alcance.CategoriaID)
+	if err == sql.ErrNoRows {
+		return models.Alcance{}, nil
+	}
+	return alcance, err
+}
+
+// normalizarPermisos valida los permisos contra el catálogo y quita repetidos
+func normalizarPermisos(permisos []string) ([]string, error) {
+	vistos := map[string]bool{}
+	normalizados := []string{}
+	for _, permiso := range permisos {
+		if !models.EsPermiso(permiso) {
+			return nil, fmt.Errorf("%w: %s", ErrPermisoInvalido, permiso)
+		}
+		if !vistos[permiso] {
+			vistos[permiso] = true
+			normalizados = append(normalizados, permiso)
+		}
+	}
+	sort.Strings(normalizados)
+	return normalizados, nil
+}
+
+func guardarPermisos(tx *sql.Tx, rolID int, permisos []string) error {
+	for _, permiso := range permisos {
+		if _, err := tx.Exec(`INSERT INTO rol_permisos (rol_id, permiso) VALUES ($1, $2)`, rolID, permiso); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func usuarioExiste(db queryRower, usuarioID int) error {
+	var id int
+	err := db.QueryRow(`SELECT id FROM usuarios WHERE id = $1`, usuarioID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrUsuarioNotFound
+	}
+	return err
+}
+
+// describirAsignacion resume la asignación para la auditoría, ej: "arbitro (categoría 3)"
+func describirAsignacion(a *models.AsignacionRol) string {
+	switch {
+	case a.TorneoID.Valid && a.CategoriaID.Valid:
+		return fmt.Sprintf("%s (torneo %d, categoría %d)", a.RolNombre, a.TorneoID.Int32, a.CategoriaID.Int32)
+	case a.TorneoID.Valid:
+		return fmt.Sprintf("%s (torneo %d)", a.RolNombre, a.TorneoID.Int32)
+	case a.CategoriaID.Valid:
+		return fmt.Sprintf("%s (categoría %d)", a.RolNombre, a.CategoriaID.Int32)
+	default:
+		return a.RolNombre
+	}
+}
+
+// esViolacionForanea indica si err es una violación de clave foránea
+func esViolacionForanea(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
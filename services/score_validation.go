@@ -0,0 +1,305 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+)
+
+// Formatos de partido admitidos por torneo
+const (
+	FormatoMejorDe3              = "mejor_de_3"
+	FormatoMejorDe3SuperTieBreak = "mejor_de_3_super_tiebreak"
+	FormatoProSet8               = "pro_set_8"
+)
+
+// FormatoPartido describe las reglas de puntuación de un partido
+type FormatoPartido struct {
+	Nombre         string
+	SetsParaGanar  int  // 2 en un mejor de 3, 1 en un pro-set
+	GamesPorSet    int  // Games para ganar un set; el tie-break se juega en GamesPorSet iguales
+	PuntosTieBreak int  // Puntos mínimos para ganar el tie-break de un set
+	SuperTieBreak  bool // El set decisivo se juega como super tie-break
+	PuntosSuper    int  // Puntos mínimos para ganar el super tie-break
+}
+
+// FormatosPartido contiene los formatos disponibles indexados por nombre
+var FormatosPartido = map[string]FormatoPartido{
+	FormatoMejorDe3: {
+		Nombre: FormatoMejorDe3, SetsParaGanar: 2, GamesPorSet: 6, PuntosTieBreak: 7,
+	},
+	FormatoMejorDe3SuperTieBreak: {
+		Nombre: FormatoMejorDe3SuperTieBreak, SetsParaGanar: 2, GamesPorSet: 6, PuntosTieBreak: 7,
+		SuperTieBreak: true, PuntosSuper: 10,
+	},
+	FormatoProSet8: {
+		Nombre: FormatoProSet8, SetsParaGanar: 1, GamesPorSet: 8, PuntosTieBreak: 7,
+	},
+}
+
+// ScoreValidationError agrupa los errores de campo de un resultado inválido
+type ScoreValidationError struct {
+	Errors []utils.ErrorDetail
+}
+
+func (e *ScoreValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, detail := range e.Errors {
+		messages = append(messages, detail.Message)
+	}
+	return "resultado inválido: " + strings.Join(messages, "; ")
+}
+
+// GetFormatoPartido devuelve el formato por nombre, usando el mejor de 3 si está vacío
+func GetFormatoPartido(nombre string) (FormatoPartido, bool) {
+	if nombre == "" {
+		nombre = FormatoMejorDe3
+	}
+	formato, ok := FormatosPartido[nombre]
+	return formato, ok
+}
+
+// MatchScore es el resultado de un partido tal como lo reporta un jugador
+type MatchScore struct {
+	Jugador1ID    int
+	Jugador2ID    int
+	SetsGanadosJ1 int
+	SetsGanadosJ2 int
+	GanadorID     int
+	Sets          []models.SetPartido
+}
+
+// ValidateMatchScore verifica que cada set respete el formato y que los sets
+// ganados y el ganador coincidan con los scores. Devuelve nil si el resultado es válido.
+func ValidateMatchScore(formato FormatoPartido, score MatchScore) *ScoreValidationError {
+	var errs []utils.ErrorDetail
+
+	if score.GanadorID != score.Jugador1ID && score.GanadorID != score.Jugador2ID {
+		errs = append(errs, utils.CreateFieldError("ganador_id", utils.ErrInvalidInput,
+			"ganador_id no corresponde a ningún jugador del partido", score.GanadorID))
+	}
+	if len(score.Sets) == 0 {
+		errs = append(errs, utils.CreateFieldError("sets", utils.ErrMissingField,
+			"se requiere el detalle de los sets", nil))
+	}
+
+	ganadosJ1, ganadosJ2, setErrs := countSets(formato, score.Sets, false)
+	errs = append(errs, setErrs...)
+	if len(errs) > 0 {
+		return &ScoreValidationError{Errors: errs}
+	}
+
+	if ganadosJ1 != score.SetsGanadosJ1 {
+		errs = append(errs, utils.CreateFieldError("sets_ganados_j1", utils.ErrInvalidInput,
+			fmt.Sprintf("según los sets, el jugador 1 ganó %d sets", ganadosJ1), score.SetsGanadosJ1))
+	}
+	if ganadosJ2 != score.SetsGanadosJ2 {
+		errs = append(errs, utils.CreateFieldError("sets_ganados_j2", utils.ErrInvalidInput,
+			fmt.Sprintf("según los sets, el jugador 2 ganó %d sets", ganadosJ2), score.SetsGanadosJ2))
+	}
+
+	switch {
+	case ganadosJ1 == formato.SetsParaGanar && score.GanadorID != score.Jugador1ID,
+		ganadosJ2 == formato.SetsParaGanar && score.GanadorID != score.Jugador2ID:
+		errs = append(errs, utils.CreateFieldError("ganador_id", utils.ErrInvalidInput,
+			"ganador_id no coincide con el jugador que ganó los sets", score.GanadorID))
+	case ganadosJ1 < formato.SetsParaGanar && ganadosJ2 < formato.SetsParaGanar:
+		errs = append(errs, utils.CreateValidationError("sets",
+			fmt.Sprintf("el partido no está terminado: se necesitan %d sets para ganar", formato.SetsParaGanar),
+			len(score.Sets), map[string]interface{}{"sets_para_ganar": formato.SetsParaGanar}))
+	}
+
+	if len(errs) > 0 {
+		return &ScoreValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidatePartialScore valida los sets de un partido interrumpido (abandono o
+// descalificación) y devuelve los sets ganados por cada jugador. Al beneficiario
+// se le acreditan los sets necesarios para ganar el partido.
+func ValidatePartialScore(formato FormatoPartido, jugador1ID, jugador2ID, beneficiarioID int, sets []models.SetPartido) (int, int, *ScoreValidationError) {
+	var errs []utils.ErrorDetail
+
+	if beneficiarioID != jugador1ID && beneficiarioID != jugador2ID {
+		errs = append(errs, utils.CreateFieldError("beneficiario_id", utils.ErrInvalidInput,
+			"beneficiario_id no corresponde a ningún jugador del partido", beneficiarioID))
+	}
+
+	ganadosJ1, ganadosJ2, setErrs := countSets(formato, sets, true)
+	errs = append(errs, setErrs...)
+	if len(errs) > 0 {
+		return 0, 0, &ScoreValidationError{Errors: errs}
+	}
+
+	if ganadosJ1 == formato.SetsParaGanar || ganadosJ2 == formato.SetsParaGanar {
+		return 0, 0, &ScoreValidationError{Errors: []utils.ErrorDetail{utils.CreateFieldError("sets", utils.ErrInvalidInput,
+			"los sets informados ya definen el partido; reporte el resultado completo", len(sets))}}
+	}
+
+	if beneficiarioID == jugador1ID {
+		ganadosJ1 = formato.SetsParaGanar
+	} else {
+		ganadosJ2 = formato.SetsParaGanar
+	}
+
+	return ganadosJ1, ganadosJ2, nil
+}
+
+// countSets valida cada set según el formato y cuenta los sets ganados por cada
+// jugador. Con parcial, el último set puede haber quedado sin terminar y no se cuenta.
+func countSets(formato FormatoPartido, sets []models.SetPartido, parcial bool) (int, int, []utils.ErrorDetail) {
+	var errs []utils.ErrorDetail
+
+	maxSets := 2*formato.SetsParaGanar - 1
+	if len(sets) > maxSets {
+		errs = append(errs, utils.CreateValidationError("sets",
+			fmt.Sprintf("el formato %s admite como máximo %d sets", formato.Nombre, maxSets),
+			len(sets), map[string]interface{}{"max": maxSets}))
+		return 0, 0, errs
+	}
+
+	ganadosJ1, ganadosJ2 := 0, 0
+	for i := range sets {
+		set := &sets[i]
+		field := fmt.Sprintf("sets[%d]", i)
+
+		if set.NumeroSet == 0 {
+			set.NumeroSet = i + 1
+		}
+		if set.NumeroSet != i+1 {
+			errs = append(errs, utils.CreateFieldError(field+".numero_set", utils.ErrInvalidInput,
+				fmt.Sprintf("se esperaba el set número %d", i+1), set.NumeroSet))
+		}
+
+		if ganadosJ1 == formato.SetsParaGanar || ganadosJ2 == formato.SetsParaGanar {
+			errs = append(errs, utils.CreateFieldError(field, utils.ErrInvalidInput,
+				"el partido ya estaba definido antes de este set", set.NumeroSet))
+			continue
+		}
+
+		// El set decisivo con ambos jugadores a un set de ganar puede ser super tie-break
+		decisivo := formato.SetsParaGanar > 1 &&
+			ganadosJ1 == formato.SetsParaGanar-1 && ganadosJ2 == formato.SetsParaGanar-1
+		validate := validateSet
+		if decisivo && formato.SuperTieBreak {
+			validate = validateSuperTieBreak
+		}
+
+		setErrs := validate(formato, *set, field, false)
+		if len(setErrs) > 0 && parcial && i == len(sets)-1 {
+			// El último set de un partido interrumpido puede estar en curso
+			errs = append(errs, validate(formato, *set, field, true)...)
+			continue
+		}
+		errs = append(errs, setErrs...)
+
+		if len(setErrs) == 0 {
+			if set.ScoreJugador1 > set.ScoreJugador2 {
+				ganadosJ1++
+			} else {
+				ganadosJ2++
+			}
+		}
+	}
+
+	return ganadosJ1, ganadosJ2, errs
+}
+
+// validateSet valida un set a games, con tie-break al llegar a GamesPorSet iguales.
+// Un set en curso (por abandono) solo debe tener un score alcanzable sin terminar.
+func validateSet(formato FormatoPartido, set models.SetPartido, field string, incompleto bool) []utils.ErrorDetail {
+	var errs []utils.ErrorDetail
+	g := formato.GamesPorSet
+	ganador, perdedor := set.ScoreJugador1, set.ScoreJugador2
+	if perdedor > ganador {
+		ganador, perdedor = perdedor, ganador
+	}
+
+	if set.ScoreJugador1 < 0 || set.ScoreJugador2 < 0 {
+		return append(errs, utils.CreateFieldError(field, utils.ErrValueOutOfRange,
+			"los games no pueden ser negativos", nil))
+	}
+
+	if incompleto {
+		if ganador > g || (ganador == g && perdedor < g-1) {
+			errs = append(errs, utils.CreateFieldError(field, utils.ErrValueOutOfRange,
+				fmt.Sprintf("un set en curso no puede terminar %d-%d", set.ScoreJugador1, set.ScoreJugador2), nil))
+		}
+		return errs
+	}
+
+	tieBreak := ganador == g+1 && perdedor == g
+	valido := (ganador == g && perdedor <= g-2) || (ganador == g+1 && perdedor == g-1) || tieBreak
+	if !valido {
+		return append(errs, utils.CreateValidationError(field,
+			fmt.Sprintf("score de set inválido %d-%d: se gana con %d games y 2 de diferencia, o %d-%d por tie-break",
+				set.ScoreJugador1, set.ScoreJugador2, g, g+1, g),
+			fmt.Sprintf("%d-%d", set.ScoreJugador1, set.ScoreJugador2),
+			map[string]interface{}{"games_por_set": g}))
+	}
+
+	if !tieBreak {
+		if set.TieBreakJ1.Valid || set.TieBreakJ2.Valid {
+			errs = append(errs, utils.CreateFieldError(field+".tie_break", utils.ErrInvalidInput,
+				"solo un set que termina por tie-break puede informar sus puntos", nil))
+		}
+		return errs
+	}
+
+	if !set.TieBreakJ1.Valid || !set.TieBreakJ2.Valid {
+		return append(errs, utils.CreateFieldError(field+".tie_break", utils.ErrMissingField,
+			fmt.Sprintf("un set %d-%d requiere los puntos del tie-break", g+1, g), nil))
+	}
+
+	tbErrs := validateTieBreakPoints(int(set.TieBreakJ1.Int32), int(set.TieBreakJ2.Int32), formato.PuntosTieBreak, field+".tie_break")
+	errs = append(errs, tbErrs...)
+	if len(tbErrs) == 0 && (set.TieBreakJ1.Int32 > set.TieBreakJ2.Int32) != (set.ScoreJugador1 > set.ScoreJugador2) {
+		errs = append(errs, utils.CreateFieldError(field+".tie_break", utils.ErrInvalidInput,
+			"el ganador del tie-break debe ser el ganador del set", nil))
+	}
+
+	return errs
+}
+
+// validateSuperTieBreak valida un set decisivo jugado como super tie-break; los
+// puntos se informan en score_jugador1/score_jugador2
+func validateSuperTieBreak(formato FormatoPartido, set models.SetPartido, field string, incompleto bool) []utils.ErrorDetail {
+	var errs []utils.ErrorDetail
+	if set.TieBreakJ1.Valid || set.TieBreakJ2.Valid {
+		errs = append(errs, utils.CreateFieldError(field+".tie_break", utils.ErrInvalidInput,
+			"el super tie-break se informa en los scores del set", nil))
+	}
+
+	if incompleto {
+		if set.ScoreJugador1 < 0 || set.ScoreJugador2 < 0 {
+			errs = append(errs, utils.CreateFieldError(field, utils.ErrValueOutOfRange,
+				"los puntos no pueden ser negativos", nil))
+		}
+		return errs
+	}
+
+	return append(errs, validateTieBreakPoints(set.ScoreJugador1, set.ScoreJugador2, formato.PuntosSuper, field)...)
+}
+
+// validateTieBreakPoints verifica que un tie-break se haya ganado con al menos
+// minimo puntos y 2 de diferencia, terminando en cuanto se alcanzó esa diferencia
+func validateTieBreakPoints(p1, p2, minimo int, field string) []utils.ErrorDetail {
+	ganador, perdedor := p1, p2
+	if perdedor > ganador {
+		ganador, perdedor = perdedor, ganador
+	}
+
+	valido := p1 >= 0 && p2 >= 0 &&
+		((ganador == minimo && perdedor <= minimo-2) || (ganador > minimo && ganador-perdedor == 2))
+	if valido {
+		return nil
+	}
+
+	return []utils.ErrorDetail{utils.CreateValidationError(field,
+		fmt.Sprintf("tie-break inválido %d-%d: se gana con %d puntos y 2 de diferencia", p1, p2, minimo),
+		fmt.Sprintf("%d-%d", p1, p2),
+		map[string]interface{}{"puntos_minimos": minimo})}
+}
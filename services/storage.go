@@ -0,0 +1,187 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"copa-litoral-backend/config"
+	"copa-litoral-backend/utils"
+)
+
+// Storage guarda los archivos subidos. La clave es una ruta relativa con barras,
+// ej: "fotos/2026/05/ab12.jpg"; Put devuelve la URL pública del archivo.
+type Storage interface {
+	Put(ctx context.Context, clave string, contenido []byte, tipoMIME string) (string, error)
+	Delete(ctx context.Context, clave string) error
+}
+
+// NewStorage elige la implementación según la configuración: S3 si STORAGE_BACKEND
+// es "s3", el sistema de archivos local en caso contrario
+func NewStorage(cfg *config.Config) Storage {
+	if cfg.StorageBackend == "s3" {
+		return NewS3Storage(cfg)
+	}
+	return NewLocalStorage(cfg.UploadDir, cfg.UploadURL)
+}
+
+// LocalStorage guarda los archivos en un directorio local que la API sirve en urlBase
+type LocalStorage struct {
+	dir     string
+	urlBase string
+}
+
+func NewLocalStorage(dir, urlBase string) *LocalStorage {
+	return &LocalStorage{dir: dir, urlBase: strings.TrimRight(urlBase, "/")}
+}
+
+// ruta resuelve la clave dentro del directorio; rechaza las que intentan salir de él
+func (s *LocalStorage) ruta(clave string) (string, error) {
+	limpia := filepath.Clean("/" + clave)
+	if limpia == "/" || limpia != "/"+clave {
+		return "", fmt.Errorf("clave de almacenamiento inválida: %q", clave)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(limpia)), nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, clave string, contenido []byte, tipoMIME string) (string, error) {
+	ruta, err := s.ruta(clave)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(ruta), 0o755); err != nil {
+		return "", err
+	}
+
+	// Se escribe a un temporal y se renombra para no servir nunca un archivo a medias
+	tmp, err := os.CreateTemp(filepath.Dir(ruta), ".subida-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(contenido); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), ruta); err != nil {
+		return "", err
+	}
+
+	return s.urlBase + "/" + clave, nil
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, clave string) error {
+	ruta, err := s.ruta(clave)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(ruta); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// URLBase es el prefijo bajo el que se sirven los archivos
+func (s *LocalStorage) URLBase() string {
+	return s.urlBase
+}
+
+// Handler sirve los archivos guardados, sin listar directorios. Las claves son
+// aleatorias y no se reutilizan, así que los archivos se pueden cachear sin límite.
+func (s *LocalStorage) Handler() http.Handler {
+	archivos := http.StripPrefix(s.urlBase, http.FileServer(http.Dir(s.dir)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		archivos.ServeHTTP(w, r)
+	})
+}
+
+// S3Storage guarda los archivos en un bucket de un servicio compatible con S3
+// (AWS S3, MinIO, Cloudflare R2...). Usa URLs de estilo ruta (endpoint/bucket/clave),
+// que admiten todos. El bucket debe permitir la lectura pública de los objetos o
+// servirse a través de S3_PUBLIC_URL.
+type S3Storage struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	urlBase   string
+	client    *http.Client
+}
+
+func NewS3Storage(cfg *config.Config) *S3Storage {
+	endpoint := strings.TrimRight(cfg.S3Endpoint, "/")
+	urlBase := strings.TrimRight(cfg.S3PublicURL, "/")
+	if urlBase == "" {
+		urlBase = endpoint + "/" + cfg.S3Bucket
+	}
+	return &S3Storage{
+		endpoint:  endpoint,
+		region:    cfg.S3Region,
+		bucket:    cfg.S3Bucket,
+		accessKey: cfg.S3AccessKey,
+		secretKey: cfg.S3SecretKey,
+		urlBase:   urlBase,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *S3Storage) Put(ctx context.Context, clave string, contenido []byte, tipoMIME string) (string, error) {
+	hash := sha256.Sum256(contenido)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.endpoint+"/"+s.bucket+"/"+clave, bytes.NewReader(contenido))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", tipoMIME)
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	if err := s.enviar(req, hex.EncodeToString(hash[:])); err != nil {
+		return "", err
+	}
+	return s.urlBase + "/" + clave, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, clave string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.endpoint+"/"+s.bucket+"/"+clave, nil)
+	if err != nil {
+		return err
+	}
+	return s.enviar(req, utils.HashVacio)
+}
+
+// enviar firma la solicitud y la envía; cualquier respuesta que no sea 2xx es un error
+func (s *S3Storage) enviar(req *http.Request, hashCuerpo string) error {
+	utils.FirmarS3(req, s.accessKey, s.secretKey, s.region, hashCuerpo, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detalle, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("almacenamiento S3: %s %s respondió %d: %s", req.Method, req.URL.Path, resp.StatusCode, detalle)
+	}
+	return nil
+}
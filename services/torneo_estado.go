@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+)
+
+var (
+	// ErrTorneoTransicion indica que el torneo no puede pasar al estado solicitado
+	ErrTorneoTransicion = errors.New("transición de estado de torneo inválida")
+	// ErrTorneoActualExiste indica que otro torneo ya está entre la inscripción y el final
+	ErrTorneoActualExiste = errors.New("ya hay otro torneo en curso; debe finalizarse antes de abrir la inscripción")
+	ErrTorneoSinPartidos  = errors.New("el torneo no tiene partidos; genere las llaves o grupos antes de comenzar")
+	// ErrTorneoPartidosPendientes indica que quedan partidos sin resolver al finalizar
+	ErrTorneoPartidosPendientes = errors.New("el torneo tiene partidos sin resolver")
+	ErrTorneoInscripcionCierre  = errors.New("el cierre de la inscripción debe ser una fecha futura")
+	ErrTorneoSinActual          = errors.New("no hay un torneo actual")
+)
+
+// transicionesTorneo define a qué estados puede pasar un torneo desde cada estado.
+// El ciclo es planificado → inscripción abierta → inscripción cerrada → en curso →
+// finalizado → archivado; la inscripción cerrada se puede reabrir mientras no
+// empiece el juego. Archivado es final.
+var transicionesTorneo = map[models.EstadoTorneo][]models.EstadoTorneo{
+	models.TorneoPlanificado:        {models.TorneoInscripcionAbierta},
+	models.TorneoInscripcionAbierta: {models.TorneoInscripcionCerrada},
+	models.TorneoInscripcionCerrada: {models.TorneoInscripcionAbierta, models.TorneoEnCurso},
+	models.TorneoEnCurso:            {models.TorneoFinalizado},
+	models.TorneoFinalizado:         {models.TorneoArchivado},
+	models.TorneoArchivado:          {},
+}
+
+// CanTransitionTorneo indica si el ciclo de vida admite pasar de un estado a otro
+func CanTransitionTorneo(desde, hacia models.EstadoTorneo) bool {
+	for _, estado := range transicionesTorneo[desde] {
+		if estado == hacia {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeEstado valida y aplica un cambio de estado del torneo. inscripcionCierre
+// solo se usa al abrir la inscripción y es opcional.
+func (s *torneoServiceImpl) ChangeEstado(id int, estado models.EstadoTorneo, inscripcionCierre sql.NullTime) error {
+	if _, ok := transicionesTorneo[estado]; !ok {
+		return fmt.Errorf("%w: estado desconocido %s", ErrTorneoTransicion, estado)
+	}
+	if inscripcionCierre.Valid && !inscripcionCierre.Time.After(time.Now()) {
+		return ErrTorneoInscripcionCierre
+	}
+
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		var actual models.EstadoTorneo
+		err := tx.QueryRow(`SELECT estado FROM torneos WHERE id = $1 FOR UPDATE`, id).Scan(&actual)
+		if err == sql.ErrNoRows {
+			domainErr = ErrTorneoNotFound
+			return domainErr
+		}
+		if err != nil {
+			return err
+		}
+		if !CanTransitionTorneo(actual, estado) {
+			domainErr = fmt.Errorf("%w: de %s a %s", ErrTorneoTransicion, actual, estado)
+			return domainErr
+		}
+
+		if err := validarTransicionTorneo(tx, id, estado); err != nil {
+			if errors.Is(err, ErrTorneoActualExiste) || errors.Is(err, ErrTorneoSinPartidos) ||
+				errors.Is(err, ErrTorneoPartidosPendientes) {
+				domainErr = err
+			}
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE torneos
+			SET estado = $1,
+			    inscripcion_cierre = CASE
+			        WHEN $1 = 'inscripcion_abierta' THEN $3
+			        WHEN $1 = 'inscripcion_cerrada' THEN NOW()
+			        ELSE inscripcion_cierre END,
+			    fecha_inicio = CASE WHEN $1 = 'en_curso' THEN COALESCE(fecha_inicio, CURRENT_DATE) ELSE fecha_inicio END,
+			    fecha_fin = CASE WHEN $1 = 'finalizado' THEN COALESCE(fecha_fin, CURRENT_DATE) ELSE fecha_fin END,
+			    updated_at = NOW()
+			WHERE id = $2`,
+			estado, id, inscripcionCierre,
+		)
+		// El índice único cubre la carrera entre dos aperturas simultáneas
+		if esViolacionUnica(err, "idx_torneos_actual") {
+			domainErr = ErrTorneoActualExiste
+		}
+		return err
+	})
+	if domainErr != nil {
+		return domainErr
+	}
+
+	return err
+}
+
+// validarTransicionTorneo aplica las reglas de cada paso del ciclo de vida
+func validarTransicionTorneo(tx *sql.Tx, id int, estado models.EstadoTorneo) error {
+	switch estado {
+	case models.TorneoInscripcionAbierta:
+		var otros int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM torneos
+			WHERE id <> $1 AND estado IN ('inscripcion_abierta', 'inscripcion_cerrada', 'en_curso')`,
+			id,
+		).Scan(&otros)
+		if err != nil {
+			return err
+		}
+		if otros > 0 {
+			return ErrTorneoActualExiste
+		}
+
+	case models.TorneoEnCurso:
+		var partidos int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM partidos WHERE torneo_id = $1`, id).Scan(&partidos); err != nil {
+			return err
+		}
+		if partidos == 0 {
+			return ErrTorneoSinPartidos
+		}
+
+	case models.TorneoFinalizado:
+		var pendientes int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM partidos
+			WHERE torneo_id = $1 AND estado NOT IN ($2, $3, $4)`,
+			id, models.EstadoFinalizado, models.EstadoWalkover, models.EstadoCancelado,
+		).Scan(&pendientes)
+		if err != nil {
+			return err
+		}
+		if pendientes > 0 {
+			return fmt.Errorf("%w: quedan %d", ErrTorneoPartidosPendientes, pendientes)
+		}
+	}
+	return nil
+}
@@ -8,12 +8,19 @@ import (
 	"copa-litoral-backend/models"
 )
 
+var (
+	ErrInvalidFormato = errors.New("formato de partido inválido")
+	ErrTorneoNotFound = errors.New("torneo no encontrado")
+)
+
 type TorneoService interface {
 	GetAllTorneos() ([]models.Torneo, error)
 	GetTorneoByID(id int) (*models.Torneo, error)
+	GetTorneoActual() (*models.Torneo, error)
 	CreateTorneo(torneo *models.Torneo) error
 	UpdateTorneo(id int, torneo *models.Torneo) error
 	DeleteTorneo(id int) error
+	ChangeEstado(id int, estado models.EstadoTorneo, inscripcionCierre sql.NullTime) error
 }
 
 type torneoServiceImpl struct{}
@@ -22,14 +29,22 @@ func NewTorneoService() TorneoService {
 	return &torneoServiceImpl{}
 }
 
-func (s *torneoServiceImpl) GetAllTorneos() ([]models.Torneo, error) {
-	query := `
-		SELECT id, nombre, anio, fecha_inicio, fecha_fin, foto_url, frase_destacada, 
-		       activo, created_at, updated_at
-		FROM torneos
-		ORDER BY anio DESC, nombre`
+const torneoSelectQuery = `
+	SELECT id, nombre, anio, fecha_inicio, fecha_fin, foto_url, frase_destacada,
+	       estado, inscripcion_cierre, formato_partido, created_at, updated_at
+	FROM torneos`
 
-	rows, err := database.DB.Query(query)
+func scanTorneo(row rowScanner, t *models.Torneo) error {
+	err := row.Scan(
+		&t.ID, &t.Nombre, &t.Anio, &t.FechaInicio, &t.FechaFin, &t.FotoURL, &t.FraseDestacada,
+		&t.Estado, &t.InscripcionCierre, &t.FormatoPartido, &t.CreatedAt, &t.UpdatedAt,
+	)
+	t.Activo = t.Estado.EnJuego()
+	return err
+}
+
+func (s *torneoServiceImpl) GetAllTorneos() ([]models.Torneo, error) {
+	rows, err := database.DB.Query(torneoSelectQuery + ` ORDER BY anio DESC, nombre`)
 	if err != nil {
 		return nil, err
 	}
@@ -38,11 +53,7 @@ func (s *torneoServiceImpl) GetAllTorneos() ([]models.Torneo, error) {
 	var torneos []models.Torneo
 	for rows.Next() {
 		var t models.Torneo
-		err := rows.Scan(
-			&t.ID, &t.Nombre, &t.Anio, &t.FechaInicio, &t.FechaFin, &t.FotoURL,
-			&t.FraseDestacada, &t.Activo, &t.CreatedAt, &t.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanTorneo(rows, &t); err != nil {
 			return nil, err
 		}
 		torneos = append(torneos, t)
@@ -52,21 +63,11 @@ func (s *torneoServiceImpl) GetAllTorneos() ([]models.Torneo, error) {
 }
 
 func (s *torneoServiceImpl) GetTorneoByID(id int) (*models.Torneo, error) {
-	query := `
-		SELECT id, nombre, anio, fecha_inicio, fecha_fin, foto_url, frase_destacada, 
-		       activo, created_at, updated_at
-		FROM torneos
-		WHERE id = $1`
-
 	var torneo models.Torneo
-	err := database.DB.QueryRow(query, id).Scan(
-		&torneo.ID, &torneo.Nombre, &torneo.Anio, &torneo.FechaInicio, &torneo.FechaFin,
-		&torneo.FotoURL, &torneo.FraseDestacada, &torneo.Activo, &torneo.CreatedAt, &torneo.UpdatedAt,
-	)
-
+	err := scanTorneo(database.DB.QueryRow(torneoSelectQuery+` WHERE id = $1`, id), &torneo)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("torneo no encontrado")
+			return nil, ErrTorneoNotFound
 		}
 		return nil, err
 	}
@@ -74,29 +75,79 @@ func (s *torneoServiceImpl) GetTorneoByID(id int) (*models.Torneo, error) {
 	return &torneo, nil
 }
 
+// GetTorneoActual devuelve la edición que se muestra en la web
+func (s *torneoServiceImpl) GetTorneoActual() (*models.Torneo, error) {
+	id, err := torneoActualID()
+	if err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		return nil, ErrTorneoSinActual
+	}
+	return s.GetTorneoByID(id)
+}
+
+// torneoActualID devuelve el torneo actual: el que está entre la apertura de la
+// inscripción y el final o, si no hay ninguno, el último finalizado. 0 si no hay.
+func torneoActualID() (int, error) {
+	var id int
+	err := database.DB.QueryRow(`
+		SELECT id FROM torneos
+		WHERE estado IN ('inscripcion_abierta', 'inscripcion_cerrada', 'en_curso', 'finalizado')
+		ORDER BY (estado = 'finalizado'), anio DESC, fecha_fin DESC NULLS LAST, id DESC
+		LIMIT 1`).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return id, err
+}
+
+// normalizeFormato valida el formato de partido y aplica el mejor de 3 por defecto
+func normalizeFormato(torneo *models.Torneo) error {
+	if torneo.FormatoPartido == "" {
+		torneo.FormatoPartido = FormatoMejorDe3
+	}
+	if _, ok := GetFormatoPartido(torneo.FormatoPartido); !ok {
+		return ErrInvalidFormato
+	}
+	return nil
+}
+
 func (s *torneoServiceImpl) CreateTorneo(torneo *models.Torneo) error {
+	if err := normalizeFormato(torneo); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO torneos (nombre, anio, fecha_inicio, fecha_fin, foto_url, 
-		                    frase_destacada, activo, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		                    frase_destacada, estado, formato_partido, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
+	// Los torneos nacen planificados; el estado cambia con ChangeEstado
+	torneo.Estado = models.TorneoPlanificado
+	torneo.InscripcionCierre = sql.NullTime{}
+	torneo.Activo = false
 	return database.DB.QueryRow(query,
 		torneo.Nombre, torneo.Anio, torneo.FechaInicio, torneo.FechaFin,
-		torneo.FotoURL, torneo.FraseDestacada, torneo.Activo,
+		torneo.FotoURL, torneo.FraseDestacada, torneo.Estado, torneo.FormatoPartido,
 	).Scan(&torneo.ID, &torneo.CreatedAt, &torneo.UpdatedAt)
 }
 
 func (s *torneoServiceImpl) UpdateTorneo(id int, torneo *models.Torneo) error {
+	if err := normalizeFormato(torneo); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE torneos 
 		SET nombre = $1, anio = $2, fecha_inicio = $3, fecha_fin = $4,
-		    foto_url = $5, frase_destacada = $6, activo = $7, updated_at = NOW()
+		    foto_url = $5, frase_destacada = $6, formato_partido = $7, updated_at = NOW()
 		WHERE id = $8`
 
 	result, err := database.DB.Exec(query,
 		torneo.Nombre, torneo.Anio, torneo.FechaInicio, torneo.FechaFin,
-		torneo.FotoURL, torneo.FraseDestacada, torneo.Activo, id,
+		torneo.FotoURL, torneo.FraseDestacada, torneo.FormatoPartido, id,
 	)
 	if err != nil {
 		return err
@@ -108,7 +159,7 @@ func (s *torneoServiceImpl) UpdateTorneo(id int, torneo *models.Torneo) error {
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("torneo no encontrado")
+		return ErrTorneoNotFound
 	}
 
 	return nil
@@ -128,7 +179,7 @@ func (s *torneoServiceImpl) DeleteTorneo(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("torneo no encontrado")
+		return ErrTorneoNotFound
 	}
 
 	return nil
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/utils"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrUsuarioNotFound indica que el usuario no existe
+	ErrUsuarioNotFound = errors.New("usuario no encontrado")
+	// ErrRolInvalido indica un rol distinto de administrador o jugador
+	ErrRolInvalido = errors.New("rol inválido")
+	// ErrAccionSobreSiMismo evita que un administrador se quite el acceso a sí mismo
+	ErrAccionSobreSiMismo = errors.New("un administrador no puede quitarse el rol ni deshabilitar su propia cuenta")
+	// ErrJugadorNoEncontrado indica que el jugador a vincular no existe
+	ErrJugadorNoEncontrado = errors.New("jugador no encontrado")
+	// ErrJugadorYaVinculado indica que el jugador ya está vinculado a otra cuenta
+	ErrJugadorYaVinculado = errors.New("el jugador ya está vinculado a otro usuario")
+)
+
+// usuarioColumnas son las columnas de usuarios por las que se puede ordenar
+var usuarioColumnas = map[string]bool{
+	"id": true, "nombre_usuario": true, "email": true, "created_at": true, "updated_at": true,
+}
+
+type UsuarioService interface {
+	GetUsuarios(params utils.PaginationParams, filter utils.AdvancedFilter) ([]models.Usuario, utils.PaginationInfo, error)
+	GetUsuario(id int) (*models.Usuario, error)
+	ChangeRol(id int, rol string, actorID int) (*models.Usuario, error)
+	SetJugador(id, jugadorID, actorID int) (*models.Usuario, error)
+	SetActivo(id int, activo bool, actorID int) (*models.Usuario, error)
+	GetAuditoria(id int) ([]models.AuditoriaUsuario, error)
+	Unlock(id, actorID int) (*models.Usuario, error)
+	ResetMFA(id, actorID int) (*models.Usuario, error)
+}
+
+type usuarioServiceImpl struct {
+	filters   *utils.FilterManager
+	throttler *LoginThrottler
+}
+
+func NewUsuarioService(throttler *LoginThrottler) UsuarioService {
+	return &usuarioServiceImpl{
+		filters:   utils.NewFilterManager(),
+		throttler: throttler,
+	}
+}
+
+const usuarioSelect = `
+	SELECT id, nombre_usuario, email, email_verificado_en, rol, jugador_id, activo, created_at, updated_at
+	FROM usuarios`
+
+func scanUsuario(row rowScanner) (*models.Usuario, error) {
+	var u models.Usuario
+	err := row.Scan(&u.ID, &u.NombreUsuario, &u.Email, &u.EmailVerificadoEn, &u.Rol, &u.JugadorID,
+		&u.Activo, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetUsuarios lista los usuarios paginados, con los filtros avanzados de la
+// configuración "usuarios" y búsqueda por nombre de usuario o email
+func (s *usuarioServiceImpl) GetUsuarios(params utils.PaginationParams, filter utils.AdvancedFilter) ([]models.Usuario, utils.PaginationInfo, error) {
+	where, args, err := s.filters.BuildSQLConditions(filter)
+	if err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+
+	conditions := []string{}
+	if where != "" {
+		conditions = append(conditions, where)
+	}
+	if params.Search != "" {
+		args = append(args, "%"+params.Search+"%")
+		conditions = append(conditions, fmt.Sprintf("(nombre_usuario ILIKE $%d OR email ILIKE $%d)", len(args), len(args)))
+	}
+	clause := ""
+	if len(conditions) > 0 {
+		clause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM usuarios"+clause, args...).Scan(&total); err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+
+	sort := params.Sort
+	if !usuarioColumnas[sort] {
+		sort = "id"
+	}
+	query := fmt.Sprintf("%s%s ORDER BY %s %s LIMIT $%d OFFSET $%d",
+		usuarioSelect, clause, sort, strings.ToUpper(params.Order), len(args)+1, len(args)+2)
+
+	rows, err := database.DB.Query(query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+	defer rows.Close()
+
+	usuarios := []models.Usuario{}
+	for rows.Next() {
+		u, err := scanUsuario(rows)
+		if err != nil {
+			return nil, utils.PaginationInfo{}, err
+		}
+		usuarios = append(usuarios, *u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, utils.PaginationInfo{}, err
+	}
+
+	return usuarios, params.CalculatePaginationInfo(total), nil
+}
+
+func (s *usuarioServiceImpl) GetUsuario(id int) (*models.Usuario, error) {
+	u, err := scanUsuario(database.DB.QueryRow(usuarioSelect+" WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrUsuarioNotFound
+	}
+	return u, err
+}
+
+// ChangeRol promueve o degrada a un usuario. Sus sesiones se cierran para que
+// el nuevo rol rija desde el próximo login.
+func (s *usuarioServiceImpl) ChangeRol(id int, rol string, actorID int) (*models.Usuario, error) {
+	if rol != models.RolAdministrador && rol != models.RolJugador {
+		return nil, ErrRolInvalido
+	}
+	if id == actorID && rol != models.RolAdministrador {
+		return nil, ErrAccionSobreSiMismo
+	}
+
+	return s.modificar(id, func(tx *sql.Tx, u *models.Usuario) error {
+		if u.Rol == rol {
+			return nil
+		}
+		if _, err := tx.Exec(`UPDATE usuarios SET rol = $1, updated_at = NOW() WHERE id = $2`, rol, id); err != nil {
+			return err
+		}
+		if err := recordAuditoria(tx, id, actorID, models.AuditoriaCambioRol, u.Rol, rol); err != nil {
+			return err
+		}
+		return revocarSesiones(tx, id, "")
+	})
+}
+
+// SetJugador vincula el usuario a un jugador, o lo desvincula si jugadorID es 0
+func (s *usuarioServiceImpl) SetJugador(id, jugadorID, actorID int) (*models.Usuario, error) {
+	return s.modificar(id, func(tx *sql.Tx, u *models.Usuario) error {
+		anterior := int(u.JugadorID.Int32)
+		if anterior == jugadorID {
+			return nil
+		}
+
+		if err := vincularJugador(tx, id, jugadorID); err != nil {
+			return err
+		}
+
+		accion := models.AuditoriaVinculacionJugador
+		if jugadorID == 0 {
+			accion = models.AuditoriaDesvinculacionJugador
+		}
+		if err := recordAuditoria(tx, id, actorID, accion, valorJugador(anterior), valorJugador(jugadorID)); err != nil {
+			return err
+		}
+		// El jugador vinculado viaja en el token de acceso
+		return revocarSesiones(tx, id, "")
+	})
+}
+
+// SetActivo habilita o deshabilita una cuenta; deshabilitarla cierra todas sus sesiones
+func (s *usuarioServiceImpl) SetActivo(id int, activo bool, actorID int) (*models.Usuario, error) {
+	if id == actorID && !activo {
+		return nil, ErrAccionSobreSiMismo
+	}
+
+	return s.modificar(id, func(tx *sql.Tx, u *models.Usuario) error {
+		if u.Activo == activo {
+			return nil
+		}
+		if _, err := tx.Exec(`UPDATE usuarios SET activo = $1, updated_at = NOW() WHERE id = $2`, activo, id); err != nil {
+			return err
+		}
+
+		accion := models.AuditoriaActivacion
+		if !activo {
+			accion = models.AuditoriaDesactivacion
+		}
+		if err := recordAuditoria(tx, id, actorID, accion, strconv.FormatBool(u.Activo), strconv.FormatBool(activo)); err != nil {
+			return err
+		}
+		if !activo {
+			return revocarSesiones(tx, id, "")
+		}
+		return nil
+	})
+}
+
+// Unlock levanta el bloqueo de login por intentos fallidos del usuario. El bloqueo
+// de la IP desde la que se probaron contraseñas se mantiene hasta que venza.
+func (s *usuarioServiceImpl) Unlock(id, actorID int) (*models.Usuario, error) {
+	u, err := s.GetUsuario(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.throttler.Unlock(u.NombreUsuario)
+	if _, err := database.DB.Exec(`
+		INSERT INTO auditoria_usuarios (usuario_id, actor_id, accion, created_at)
+		VALUES ($1, $2, $3, NOW())`, id, actorID, models.AuditoriaDesbloqueo); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// ResetMFA quita la verificación en dos pasos a un usuario que perdió su
+// dispositivo y sus códigos de recuperación, y cierra sus sesiones
+func (s *usuarioServiceImpl) ResetMFA(id, actorID int) (*models.Usuario, error) {
+	return s.modificar(id, func(tx *sql.Tx, u *models.Usuario) error {
+		var habilitado bool
+		if err := tx.QueryRow(`SELECT mfa_habilitado_en IS NOT NULL FROM usuarios WHERE id = $1`, id).Scan(&habilitado); err != nil {
+			return err
+		}
+		if !habilitado {
+			return ErrMFANoHabilitado
+		}
+		if err := desactivarMFA(tx, id, actorID); err != nil {
+			return err
+		}
+		return revocarSesiones(tx, id, "")
+	})
+}
+
+func (s *usuarioServiceImpl) GetAuditoria(id int) ([]models.AuditoriaUsuario, error) {
+	if _, err := s.GetUsuario(id); err != nil {
+		return nil, err
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT a.id, a.usuario_id, a.actor_id, COALESCE(u.nombre_usuario, ''), a.accion,
+		       a.valor_anterior, a.valor_nuevo, a.created_at
+		FROM auditoria_usuarios a
+		LEFT JOIN usuarios u ON u.id = a.actor_id
+		WHERE a.usuario_id = $1
+		ORDER BY a.created_at, a.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	registros := []models.AuditoriaUsuario{}
+	for rows.Next() {
+		var a models.AuditoriaUsuario
+		err := rows.Scan(&a.ID, &a.UsuarioID, &a.ActorID, &a.ActorNombre, &a.Accion,
+			&a.ValorAnterior, &a.ValorNuevo, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		registros = append(registros, a)
+	}
+
+	return registros, rows.Err()
+}
+
+// modificar bloquea el usuario, aplica el cambio en una transacción y devuelve
+// el usuario actualizado
+func (s *usuarioServiceImpl) modificar(id int, cambio func(tx *sql.Tx, u *models.Usuario) error) (*models.Usuario, error) {
+	var domainErr error
+	txManager := database.NewTxManager(database.DB)
+	err := txManager.WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		u, err := scanUsuario(tx.QueryRow(usuarioSelect+" WHERE id = $1 FOR UPDATE", id))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				domainErr = ErrUsuarioNotFound
+			}
+			return err
+		}
+
+		err = cambio(tx, u)
+		if errors.Is(err, ErrJugadorNoEncontrado) || errors.Is(err, ErrJugadorYaVinculado) ||
+			errors.Is(err, ErrMFANoHabilitado) {
+			domainErr = err
+		}
+		return err
+	})
+	if domainErr != nil {
+		return nil, domainErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetUsuario(id)
+}
+
+// vincularJugador asigna el jugador al usuario (0 lo desvincula), verificando
+// que exista y que no pertenezca a otra cuenta
+func vincularJugador(tx *sql.Tx, usuarioID, jugadorID int) error {
+	if jugadorID != 0 {
+		var vinculadoA sql.NullInt32
+		err := tx.QueryRow(`
+			SELECT u.id FROM jugadores j LEFT JOIN usuarios u ON u.jugador_id = j.id
+			WHERE j.id = $1`, jugadorID).Scan(&vinculadoA)
+		if err == sql.ErrNoRows {
+			return ErrJugadorNoEncontrado
+		}
+		if err != nil {
+			return err
+		}
+		if vinculadoA.Valid && int(vinculadoA.Int32) != usuarioID {
+			return ErrJugadorYaVinculado
+		}
+	}
+
+	_, err := tx.Exec(`UPDATE usuarios SET jugador_id = NULLIF($1, 0), updated_at = NOW() WHERE id = $2`, jugadorID, usuarioID)
+	if esViolacionUnica(err, "usuarios_jugador_id_key") {
+		// Otra transacción vinculó el jugador entre la verificación y la actualización
+		return ErrJugadorYaVinculado
+	}
+	return err
+}
+
+// esViolacionUnica indica si err es la violación de la restricción UNIQUE indicada
+func esViolacionUnica(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// recordAuditoria deja registrado un cambio hecho por un administrador sobre una cuenta
+func recordAuditoria(tx *sql.Tx, usuarioID, actorID int, accion models.AccionAuditoria, anterior, nuevo string) error {
+	_, err := tx.Exec(`
+		INSERT INTO auditoria_usuarios (usuario_id, actor_id, accion, valor_anterior, valor_nuevo, created_at)
+		VALUES ($1, NULLIF($2, 0), $3, NULLIF($4, ''), NULLIF($5, ''), NOW())`,
+		usuarioID, actorID, accion, anterior, nuevo,
+	)
+	return err
+}
+
+func valorJugador(jugadorID int) string {
+	if jugadorID == 0 {
+		return ""
+	}
+	return strconv.Itoa(jugadorID)
+}
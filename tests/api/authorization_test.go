@@ -0,0 +1,597 @@
+package api
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"copa-litoral-backend/config"
+	"copa-litoral-backend/database"
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/routes"
+	"copa-litoral-backend/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type acceso int
+
+const (
+	accesoPublico acceso = iota
+	accesoJugador
+	accesoAdmin
+)
+
+// rutasAPI es la superficie v1 con el nivel de acceso que exige cada ruta
+var rutasAPI = []struct {
+	method string
+	path   string
+	acceso acceso
+}{
+	{"GET", "/api/v1/torneos", accesoPublico},
+	{"GET", "/api/v1/torneos/actual", accesoPublico},
+	{"GET", "/api/v1/torneos/1", accesoPublico},
+	{"GET", "/api/v1/torneos/1/categorias/1/llave", accesoPublico},
+	{"GET", "/api/v1/torneos/1/categorias/1/grupos", accesoPublico},
+	{"GET", "/api/v1/categorias", accesoPublico},
+	{"GET", "/api/v1/categorias/1", accesoPublico},
+	{"GET", "/api/v1/jugadores", accesoPublico},
+	{"GET", "/api/v1/jugadores/1", accesoPublico},
+	{"GET", "/api/v1/grupos/1", accesoPublico},
+	{"GET", "/api/v1/grupos/1/posiciones", accesoPublico},
+	{"GET", "/api/v1/partidos", accesoPublico},
+	{"GET", "/api/v1/partidos/1", accesoPublico},
+	{"GET", "/api/v1/partidos/1/historial", accesoPublico},
+	{"GET", "/api/v1/noticias", accesoPublico},
+	{"GET", "/api/v1/noticias/final-copa-litoral", accesoPublico},
+	{"GET", "/api/v1/auspiciadores", accesoPublico},
+	{"GET", "/api/v1/campeones", accesoPublico},
+	{"GET", "/api/v1/campeones/titulos", accesoPublico},
+	{"GET", "/api/v1/fotos", accesoPublico},
+	{"GET", "/api/v1/fotos/1", accesoPublico},
+	{"POST", "/api/v1/auth/refresh", accesoPublico},
+	{"POST", "/api/v1/auth/forgot-password", accesoPublico},
+	{"POST", "/api/v1/auth/reset-password", accesoPublico},
+	{"POST", "/api/v1/auth/verify-email", accesoPublico},
+
+	{"POST", "/api/v1/auth/logout", accesoJugador},
+	{"POST", "/api/v1/auth/logout-all", accesoJugador},
+	{"POST", "/api/v1/auth/resend-verification", accesoJugador},
+	{"GET", "/api/v1/me", accesoJugador},
+	{"PATCH", "/api/v1/me", accesoJugador},
+	{"GET", "/api/v1/me/partidos", accesoJugador},
+	{"GET", "/api/v1/me/propuestas", accesoJugador},
+	{"GET", "/api/v1/me/resultados-pendientes", accesoJugador},
+	{"GET", "/api/v1/me/mfa", accesoJugador},
+	{"POST", "/api/v1/me/mfa/enroll", accesoJugador},
+	{"POST", "/api/v1/me/mfa/activate", accesoJugador},
+	{"POST", "/api/v1/me/mfa/disable", accesoJugador},
+	{"POST", "/api/v1/me/mfa/recovery-codes", accesoJugador},
+	{"GET", "/api/v1/reclamos", accesoJugador},
+	{"POST", "/api/v1/reclamos", accesoJugador},
+	{"POST", "/api/v1/reclamos/1/confirmar", accesoJugador},
+	{"GET", "/api/v1/partidos/1/propuestas", accesoJugador},
+	{"POST", "/api/v1/partidos/1/propuestas", accesoJugador},
+	{"POST", "/api/v1/propuestas/1/aceptar", accesoJugador},
+	{"POST", "/api/v1/propuestas/1/rechazar", accesoJugador},
+	{"POST", "/api/v1/propuestas/1/contrapropuesta", accesoJugador},
+	{"POST", "/api/v1/partidos/1/resultado", accesoJugador},
+	{"GET", "/api/v1/partidos/1/reportes", accesoJugador},
+
+	{"POST", "/api/v1/admin/torneos", accesoAdmin},
+	{"PUT", "/api/v1/admin/torneos/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/torneos/1", accesoAdmin},
+	{"POST", "/api/v1/admin/torneos/1/estado", accesoAdmin},
+	{"POST", "/api/v1/admin/torneos/1/categorias/1/llave", accesoAdmin},
+	{"POST", "/api/v1/admin/torneos/1/categorias/1/grupos", accesoAdmin},
+	{"POST", "/api/v1/admin/categorias", accesoAdmin},
+	{"PUT", "/api/v1/admin/categorias/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/categorias/1", accesoAdmin},
+	{"POST", "/api/v1/admin/jugadores", accesoAdmin},
+	{"PUT", "/api/v1/admin/jugadores/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/jugadores/1", accesoAdmin},
+	{"POST", "/api/v1/admin/grupos/1/fixture", accesoAdmin},
+	{"POST", "/api/v1/admin/partidos", accesoAdmin},
+	{"GET", "/api/v1/admin/partidos/escalados", accesoAdmin},
+	{"PUT", "/api/v1/admin/partidos/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/partidos/1", accesoAdmin},
+	{"POST", "/api/v1/admin/partidos/1/agendar", accesoAdmin},
+	{"POST", "/api/v1/admin/partidos/1/aprobar", accesoAdmin},
+	{"POST", "/api/v1/admin/partidos/1/estado", accesoAdmin},
+	{"POST", "/api/v1/admin/partidos/1/walkover", accesoAdmin},
+	{"POST", "/api/v1/admin/partidos/1/abandono", accesoAdmin},
+	{"POST", "/api/v1/admin/partidos/1/descalificacion", accesoAdmin},
+	{"POST", "/api/v1/admin/partidos/1/disputa/resolver", accesoAdmin},
+	{"GET", "/api/v1/admin/disputas", accesoAdmin},
+	{"GET", "/api/v1/admin/reclamos", accesoAdmin},
+	{"POST", "/api/v1/admin/reclamos/1/aprobar", accesoAdmin},
+	{"POST", "/api/v1/admin/reclamos/1/rechazar", accesoAdmin},
+	{"POST", "/api/v1/admin/reclamos/1/codigo", accesoAdmin},
+	{"GET", "/api/v1/admin/usuarios", accesoAdmin},
+	{"GET", "/api/v1/admin/usuarios/1", accesoAdmin},
+	{"PUT", "/api/v1/admin/usuarios/1/rol", accesoAdmin},
+	{"PUT", "/api/v1/admin/usuarios/1/jugador", accesoAdmin},
+	{"DELETE", "/api/v1/admin/usuarios/1/jugador", accesoAdmin},
+	{"PUT", "/api/v1/admin/usuarios/1/activo", accesoAdmin},
+	{"GET", "/api/v1/admin/usuarios/1/auditoria", accesoAdmin},
+	{"POST", "/api/v1/admin/usuarios/1/desbloquear", accesoAdmin},
+	{"DELETE", "/api/v1/admin/usuarios/1/mfa", accesoAdmin},
+	{"GET", "/api/v1/admin/usuarios/1/roles", accesoAdmin},
+	{"POST", "/api/v1/admin/usuarios/1/roles", accesoAdmin},
+	{"DELETE", "/api/v1/admin/usuarios/1/roles/1", accesoAdmin},
+	{"GET", "/api/v1/admin/roles", accesoAdmin},
+	{"POST", "/api/v1/admin/roles", accesoAdmin},
+	{"PUT", "/api/v1/admin/roles/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/roles/1", accesoAdmin},
+	{"GET", "/api/v1/admin/permisos", accesoAdmin},
+	{"GET", "/api/v1/admin/noticias", accesoAdmin},
+	{"POST", "/api/v1/admin/noticias", accesoAdmin},
+	{"GET", "/api/v1/admin/noticias/1", accesoAdmin},
+	{"PUT", "/api/v1/admin/noticias/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/noticias/1", accesoAdmin},
+	{"GET", "/api/v1/admin/auspiciadores", accesoAdmin},
+	{"POST", "/api/v1/admin/auspiciadores", accesoAdmin},
+	{"PUT", "/api/v1/admin/auspiciadores/orden", accesoAdmin},
+	{"GET", "/api/v1/admin/auspiciadores/1", accesoAdmin},
+	{"PUT", "/api/v1/admin/auspiciadores/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/auspiciadores/1", accesoAdmin},
+	{"POST", "/api/v1/admin/auspiciadores/1/auspicios", accesoAdmin},
+	{"DELETE", "/api/v1/admin/auspiciadores/1/auspicios/1", accesoAdmin},
+	{"POST", "/api/v1/admin/campeones", accesoAdmin},
+	{"POST", "/api/v1/admin/campeones/derivar", accesoAdmin},
+	{"PUT", "/api/v1/admin/campeones/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/campeones/1", accesoAdmin},
+	{"POST", "/api/v1/admin/fotos", accesoAdmin},
+	{"PUT", "/api/v1/admin/fotos/1", accesoAdmin},
+	{"DELETE", "/api/v1/admin/fotos/1", accesoAdmin},
+	{"GET", "/api/v1/admin/api-keys", accesoAdmin},
+	{"POST", "/api/v1/admin/api-keys", accesoAdmin},
+	{"DELETE", "/api/v1/admin/api-keys/1", accesoAdmin},
+}
+
+// newMatrixRouter arma el router sin base de datos disponible: las rutas que
+// superan la autorización responden con un error del servicio, nunca 401 ni 403.
+// Los tokens se firman con una clave Ed25519 generada para la prueba.
+func newMatrixRouter(t *testing.T) (*mux.Router, *utils.KeyRing) {
+	cfg := &config.Config{
+		JWTKeysDir:         writeEd25519Key(t, "matriz"),
+		CORSAllowedOrigins: "http://localhost:3000",
+		Environment:        "test",
+		LogLevel:           "error",
+		PropuestaVigencia:  48,
+		AccessTokenTTL:     15,
+		RefreshTokenTTL:    24,
+	}
+	utils.InitLogger(cfg)
+	utils.Logger.SetOutput(io.Discard)
+
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 user=test dbname=test sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatalf("Failed to open database handle: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	anterior := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = anterior })
+
+	keys, err := utils.NewKeyRing(cfg)
+	if err != nil {
+		t.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	router := routes.SetupRoutes(db, cfg, keys)
+
+	// Sin base de datos las sesiones se dan por activas salvo las revocadas; el
+	// administrador de prueba (usuario 20) tiene todos los permisos y el organizador
+	// (usuario 30) algunos, limitados a la categoría 3
+	middlewares.SetSessionChecker(sesionesFijas{"revocada": true})
+	middlewares.SetPermissionChecker(concesionesFijas{
+		20: concesionesAdministrador(),
+		30: {
+			{Permiso: models.PermisoPartidosEdit, CategoriaID: 3},
+			{Permiso: models.PermisoJugadoresEdit, CategoriaID: 3},
+		},
+	})
+	t.Cleanup(func() {
+		middlewares.SetSessionChecker(nil)
+		middlewares.SetPermissionChecker(nil)
+	})
+
+	return router, keys
+}
+
+// writeEd25519Key guarda una clave Ed25519 nueva como <kid>.pem en un directorio temporal
+func writeEd25519Key(t *testing.T, kid string) string {
+	t.Helper()
+	_, privada, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privada)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return dir
+}
+
+// sesionesFijas responde la verificación de sesión sin consultar la base de datos
+type sesionesFijas map[string]bool
+
+func (s sesionesFijas) IsSessionActive(usuarioID int, sesionID string) (bool, error) {
+	return !s[sesionID], nil
+}
+
+// concesionesFijas responde los permisos de cada usuario sin consultar la base de datos
+type concesionesFijas map[int]models.Concesiones
+
+func (c concesionesFijas) GetConcesiones(usuarioID int) (models.Concesiones, error) {
+	return c[usuarioID], nil
+}
+
+// concesionesAdministrador son todos los permisos del catálogo sin límite de alcance
+func concesionesAdministrador() models.Concesiones {
+	concesiones := models.Concesiones{}
+	for _, permiso := range models.Permisos {
+		concesiones = append(concesiones, models.Concesion{Permiso: permiso})
+	}
+	return concesiones
+}
+
+// newToken firma un access token de prueba con la sesión indicada
+func newToken(t *testing.T, keys *utils.KeyRing, userID, jugadorID int, rol, sesionID string) string {
+	t.Helper()
+	token, err := utils.GenerateJWT(userID, jugadorID, rol, sesionID, keys, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate %s token: %v", rol, err)
+	}
+	return token
+}
+
+var solicitudes int
+
+// newRequest arma una solicitud con su propia IP para no activar el rate limiting
+func newRequest(method, path, token string) *http.Request {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	solicitudes++
+	req.Header.Set("X-Forwarded-For", fmt.Sprintf("10.0.%d.%d", solicitudes/250, solicitudes%250+1))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestAuthorizationMatrix(t *testing.T) {
+	router, keys := newMatrixRouter(t)
+
+	jugadorToken := newToken(t, keys, 10, 1, "jugador", "sesion-jugador")
+	adminToken := newToken(t, keys, 20, 0, "administrador", "sesion-admin")
+
+	usuarios := []struct {
+		nombre string
+		token  string
+		nivel  acceso
+	}{
+		{"anonimo", "", accesoPublico},
+		{"jugador", jugadorToken, accesoJugador},
+		{"administrador", adminToken, accesoAdmin},
+	}
+
+	for _, ruta := range rutasAPI {
+		for _, usuario := range usuarios {
+			t.Run(fmt.Sprintf("%s %s como %s", ruta.method, ruta.path, usuario.nombre), func(t *testing.T) {
+				req := newRequest(ruta.method, ruta.path, usuario.token)
+
+				var match mux.RouteMatch
+				if !router.Match(req, &match) || match.MatchErr != nil {
+					t.Fatalf("Route is not registered")
+				}
+
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				switch {
+				case usuario.nivel >= ruta.acceso:
+					if w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden {
+						t.Errorf("Expected access to be granted, got %d", w.Code)
+					}
+				case usuario.token == "":
+					if w.Code != http.StatusUnauthorized {
+						t.Errorf("Expected 401 without token, got %d", w.Code)
+					}
+				default:
+					if w.Code != http.StatusForbidden {
+						t.Errorf("Expected 403 for insufficient role, got %d", w.Code)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorizationRejectsInvalidTokens(t *testing.T) {
+	router, keys := newMatrixRouter(t)
+
+	otroSecreto, err := utils.GenerateJWT(20, 0, "administrador", "sesion-admin", utils.NewHMACKeyRing("otro-secreto"), time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	otraClave, err := utils.LoadKeyRing(writeEd25519Key(t, "matriz"), "")
+	if err != nil {
+		t.Fatalf("Failed to load key: %v", err)
+	}
+	mismoKID, err := utils.GenerateJWT(20, 0, "administrador", "sesion-admin", otraClave, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	vencido, err := utils.GenerateJWT(20, 0, "administrador", "sesion-admin", keys, -time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	mfaPendiente, err := utils.GenerateMFAToken(20, "verificacion", keys, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	headers := map[string]string{
+		"token de otro emisor":   "Bearer " + otroSecreto,
+		"clave ajena mismo kid":  "Bearer " + mismoKID,
+		"sin prefijo Bearer":     otroSecreto,
+		"token malformado":       "Bearer abc.def.ghi",
+		"token vencido":          "Bearer " + vencido,
+		"token de MFA pendiente": "Bearer " + mfaPendiente,
+		"sesion revocada":        "Bearer " + newToken(t, keys, 20, 0, "administrador", "revocada"),
+		"token sin sesion":       "Bearer " + newToken(t, keys, 20, 0, "administrador", ""),
+	}
+
+	for nombre, header := range headers {
+		t.Run(nombre, func(t *testing.T) {
+			req := newRequest("GET", "/api/v1/admin/disputas", "")
+			req.Header.Set("Authorization", header)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected 401, got %d", w.Code)
+			}
+		})
+	}
+}
+
+// apiKeysFijas responde la validación de API keys sin consultar la base de datos
+type apiKeysFijas map[string]*models.APIKey
+
+func (k apiKeysFijas) AuthenticateAPIKey(clave string) (*models.APIKey, error) {
+	return k[clave], nil
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	router, _ := newMatrixRouter(t)
+
+	// La clave del administrador (usuario 20) solo lleva partidos:approve; la del
+	// organizador (usuario 30) conserva el límite a la categoría 3
+	middlewares.SetAPIKeyAuthenticator(apiKeysFijas{
+		"cl_tablero":     {ID: 1, CreadaPor: 20, Permisos: []string{models.PermisoPartidosApprove}},
+		"cl_organizador": {ID: 2, CreadaPor: 30, Permisos: []string{models.PermisoPartidosEdit}},
+	}, middlewares.NewRateLimiter(100, 100))
+	t.Cleanup(func() { middlewares.SetAPIKeyAuthenticator(nil, nil) })
+
+	tests := []struct {
+		nombre   string
+		clave    string
+		method   string
+		path     string
+		body     string
+		esperado int
+	}{
+		{"permiso de la clave", "cl_tablero", "GET", "/api/v1/admin/disputas", "", 0},
+		{"permiso del creador fuera de la clave", "cl_tablero", "POST", "/api/v1/admin/torneos", `{}`, http.StatusForbidden},
+		{"alcance del creador", "cl_organizador", "POST", "/api/v1/admin/partidos", `{"torneo_id": 1, "categoria_id": 3}`, 0},
+		{"fuera del alcance del creador", "cl_organizador", "POST", "/api/v1/admin/partidos", `{"torneo_id": 1, "categoria_id": 4}`, http.StatusForbidden},
+		{"cuenta propia", "cl_tablero", "GET", "/api/v1/me", "", http.StatusForbidden},
+		{"administrar API keys", "cl_tablero", "GET", "/api/v1/admin/api-keys", "", http.StatusForbidden},
+		{"clave inexistente", "cl_otra", "GET", "/api/v1/admin/disputas", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nombre, func(t *testing.T) {
+			req := newRequest(tt.method, tt.path, "")
+			req.Header.Set("X-API-Key", tt.clave)
+			if tt.body != "" {
+				req.Body = io.NopCloser(bytes.NewBufferString(tt.body))
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tt.esperado == 0 && (w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden) {
+				t.Errorf("Expected access to be granted, got %d", w.Code)
+			}
+			if tt.esperado != 0 && w.Code != tt.esperado {
+				t.Errorf("Expected %d, got %d", tt.esperado, w.Code)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRateLimit(t *testing.T) {
+	router, _ := newMatrixRouter(t)
+
+	// Cada clave admite dos solicitudes, sin importar desde qué IP lleguen
+	middlewares.SetAPIKeyAuthenticator(apiKeysFijas{
+		"cl_bot":     {ID: 1, CreadaPor: 20, Permisos: []string{models.PermisoPartidosApprove}},
+		"cl_tablero": {ID: 2, CreadaPor: 20, Permisos: []string{models.PermisoPartidosApprove}},
+	}, middlewares.NewRateLimiter(0, 2))
+	t.Cleanup(func() { middlewares.SetAPIKeyAuthenticator(nil, nil) })
+
+	solicitar := func(clave string) int {
+		req := newRequest("GET", "/api/v1/admin/disputas", "")
+		req.Header.Set("X-API-Key", clave)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if codigo := solicitar("cl_bot"); codigo == http.StatusTooManyRequests {
+			t.Errorf("Expected request %d to be allowed, got %d", i+1, codigo)
+		}
+	}
+	if codigo := solicitar("cl_bot"); codigo != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 after the burst, got %d", codigo)
+	}
+	if codigo := solicitar("cl_tablero"); codigo == http.StatusTooManyRequests {
+		t.Errorf("Expected other keys to keep their own limit, got %d", codigo)
+	}
+}
+
+func TestRegisterRejectsRol(t *testing.T) {
+	router, _ := newMatrixRouter(t)
+
+	req := newRequest("POST", "/api/v1/auth/register", "")
+	req.Body = io.NopCloser(bytes.NewBufferString(
+		`{"nombre_usuario": "intruso", "password": "password123", "rol": "administrador"}`,
+	))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 when registering with a rol, got %d", w.Code)
+	}
+}
+
+func TestScopedPermissions(t *testing.T) {
+	router, keys := newMatrixRouter(t)
+	organizador := newToken(t, keys, 30, 0, "jugador", "sesion-organizador")
+
+	tests := []struct {
+		nombre   string
+		method   string
+		path     string
+		body     string
+		denegado bool
+	}{
+		{"partido de su categoría", "POST", "/api/v1/admin/partidos", `{"torneo_id": 1, "categoria_id": 3}`, false},
+		{"partido de otra categoría", "POST", "/api/v1/admin/partidos", `{"torneo_id": 1, "categoria_id": 4}`, true},
+		{"partido sin categoría", "POST", "/api/v1/admin/partidos", `{}`, true},
+		{"jugador de su categoría", "POST", "/api/v1/admin/jugadores", `{"categoria_id": {"Int32": 3, "Valid": true}}`, false},
+		{"jugador de otra categoría", "POST", "/api/v1/admin/jugadores", `{"categoria_id": {"Int32": 4, "Valid": true}}`, true},
+		{"jugador sin categoría", "POST", "/api/v1/admin/jugadores", `{}`, true},
+		{"ruta sin alcance exige permiso global", "GET", "/api/v1/admin/partidos/escalados", ``, true},
+		{"permiso que no tiene", "POST", "/api/v1/admin/torneos", `{}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nombre, func(t *testing.T) {
+			req := newRequest(tt.method, tt.path, organizador)
+			req.Body = io.NopCloser(bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if tt.denegado && w.Code != http.StatusForbidden {
+				t.Errorf("Expected 403, got %d", w.Code)
+			}
+			if !tt.denegado && (w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden) {
+				t.Errorf("Expected access to be granted, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthRateLimit(t *testing.T) {
+	router, _ := newMatrixRouter(t)
+
+	// Las rutas de autenticación admiten una ráfaga de 3 solicitudes por IP; la
+	// pausa evita el límite general de una solicitud cada 10ms
+	codigos := []int{}
+	for i := 0; i < 4; i++ {
+		time.Sleep(20 * time.Millisecond)
+		req := newRequest("POST", "/api/v1/auth/login", "")
+		req.Header.Set("X-Forwarded-For", "10.99.0.1")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		codigos = append(codigos, w.Code)
+	}
+
+	for i, codigo := range codigos[:3] {
+		if codigo == http.StatusTooManyRequests {
+			t.Errorf("Expected request %d to be allowed, got %d", i+1, codigo)
+		}
+	}
+	if codigos[3] != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 after the burst, got %d", codigos[3])
+	}
+
+	// Las consultas públicas no comparten ese límite
+	time.Sleep(20 * time.Millisecond)
+	req := newRequest("GET", "/api/v1/docs", "")
+	req.Header.Set("X-Forwarded-For", "10.99.0.1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code == http.StatusTooManyRequests {
+		t.Errorf("Expected public routes to keep the general limit, got %d", w.Code)
+	}
+}
+
+func TestOperationalEndpoints(t *testing.T) {
+	router, _ := newMatrixRouter(t)
+
+	for _, path := range []string{"/health/live", "/metrics", "/.well-known/jwks.json", "/api/v1/docs", "/api/v1/docs/swagger.json"} {
+		t.Run(path, func(t *testing.T) {
+			req := newRequest("GET", path, "")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected 200, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestJWKSEndpoint(t *testing.T) {
+	router, _ := newMatrixRouter(t)
+
+	req := newRequest("GET", "/.well-known/jwks.json", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+
+	var jwks utils.JSONWebKeySet
+	if err := json.Unmarshal(w.Body.Bytes(), &jwks); err != nil {
+		t.Fatalf("Failed to decode JWKS: %v", err)
+	}
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("Expected 1 key, got %d", len(jwks.Keys))
+	}
+	if key := jwks.Keys[0]; key.Kid != "matriz" || key.Kty != "OKP" || key.Alg != "EdDSA" || key.X == "" {
+		t.Errorf("Unexpected key: %+v", key)
+	}
+}
@@ -14,7 +14,7 @@ import (
 
 func TestHealthEndpoints(t *testing.T) {
 	tests.RunTestWithCleanup(t, func(t *testing.T, cfg *tests.TestConfig) {
-		router := routes.SetupRoutes(cfg.DB, cfg.Config)
+		router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 		t.Run("health check", func(t *testing.T) {
 			req := httptest.NewRequest("GET", "/health", nil)
@@ -52,7 +52,7 @@ func TestHealthEndpoints(t *testing.T) {
 
 func TestAuthEndpoints(t *testing.T) {
 	tests.RunTestWithCleanup(t, func(t *testing.T, cfg *tests.TestConfig) {
-		router := routes.SetupRoutes(cfg.DB, cfg.Config)
+		router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 		t.Run("register user", func(t *testing.T) {
 			registerData := map[string]interface{}{
@@ -134,7 +134,7 @@ func TestAuthEndpoints(t *testing.T) {
 
 func TestJugadoresEndpoints(t *testing.T) {
 	tests.RunTestWithCleanup(t, func(t *testing.T, cfg *tests.TestConfig) {
-		router := routes.SetupRoutes(cfg.DB, cfg.Config)
+		router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 		// Setup test data
 		categoriaID, err := tests.CreateTestCategoria(cfg.DB, "Primera")
@@ -220,7 +220,7 @@ func TestJugadoresEndpoints(t *testing.T) {
 
 func TestAdminEndpoints(t *testing.T) {
 	tests.RunTestWithCleanup(t, func(t *testing.T, cfg *tests.TestConfig) {
-		router := routes.SetupRoutes(cfg.DB, cfg.Config)
+		router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 		// Create admin user for authentication
 		adminUserID, err := tests.CreateTestUser(cfg.DB, "admin", "admin@example.com", "administrador")
@@ -286,7 +286,7 @@ func TestAdminEndpoints(t *testing.T) {
 
 func TestAPIVersioning(t *testing.T) {
 	tests.RunTestWithCleanup(t, func(t *testing.T, cfg *tests.TestConfig) {
-		router := routes.SetupRoutes(cfg.DB, cfg.Config)
+		router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 		t.Run("version in URL path", func(t *testing.T) {
 			req := httptest.NewRequest("GET", "/api/v1/jugadores", nil)
@@ -331,7 +331,7 @@ func TestAPIVersioning(t *testing.T) {
 
 func TestErrorHandling(t *testing.T) {
 	tests.RunTestWithCleanup(t, func(t *testing.T, cfg *tests.TestConfig) {
-		router := routes.SetupRoutes(cfg.DB, cfg.Config)
+		router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 		t.Run("404 for non-existent endpoint", func(t *testing.T) {
 			req := httptest.NewRequest("GET", "/api/v1/nonexistent", nil)
@@ -371,7 +371,7 @@ func TestErrorHandling(t *testing.T) {
 
 func TestCORSHeaders(t *testing.T) {
 	tests.RunTestWithCleanup(t, func(t *testing.T, cfg *tests.TestConfig) {
-		router := routes.SetupRoutes(cfg.DB, cfg.Config)
+		router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 		t.Run("CORS preflight request", func(t *testing.T) {
 			req := httptest.NewRequest("OPTIONS", "/api/v1/jugadores", nil)
@@ -406,7 +406,7 @@ func TestCORSHeaders(t *testing.T) {
 
 func TestRateLimiting(t *testing.T) {
 	tests.RunTestWithCleanup(t, func(t *testing.T, cfg *tests.TestConfig) {
-		router := routes.SetupRoutes(cfg.DB, cfg.Config)
+		router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 		t.Run("rate limiting", func(t *testing.T) {
 			// Make multiple requests quickly
@@ -444,7 +444,7 @@ func BenchmarkGetJugadores(b *testing.B) {
 	cfg := tests.SetupTestDB()
 	defer tests.TeardownTestDB()
 
-	router := routes.SetupRoutes(cfg.DB, cfg.Config)
+	router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 	// Setup test data
 	tests.CleanupTestData(cfg.DB)
@@ -463,7 +463,7 @@ func BenchmarkHealthCheck(b *testing.B) {
 	cfg := tests.SetupTestDB()
 	defer tests.TeardownTestDB()
 
-	router := routes.SetupRoutes(cfg.DB, cfg.Config)
+	router := routes.SetupRoutes(cfg.DB, cfg.Config, cfg.Keys)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
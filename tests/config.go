@@ -9,6 +9,7 @@ import (
 
 	"copa-litoral-backend/config"
 	"copa-litoral-backend/database"
+	"copa-litoral-backend/utils"
 
 	_ "github.com/lib/pq"
 )
@@ -17,6 +18,7 @@ import (
 type TestConfig struct {
 	DB     *sql.DB
 	Config *config.Config
+	Keys   *utils.KeyRing
 }
 
 var testConfig *TestConfig
@@ -54,6 +56,7 @@ func SetupTestDB() *TestConfig {
 	testConfig = &TestConfig{
 		DB:     db,
 		Config: cfg,
+		Keys:   utils.NewHMACKeyRing(cfg.JWTSecret),
 	}
 
 	return testConfig
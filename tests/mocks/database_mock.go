@@ -2,6 +2,8 @@ package mocks
 
 import (
 	"fmt"
+
+	"copa-litoral-backend/services"
 )
 
 // MockDB es un mock de la base de datos para testing
@@ -298,29 +300,32 @@ func (m *MockHTTPClient) Get(url string) (MockHTTPResponse, error) {
 	return MockHTTPResponse{StatusCode: 404, Body: "Not Found"}, nil
 }
 
-// MockEmailService mock del servicio de email
+// MockEmailService mock del servicio de email; retiene los correos como
+// services.MemoryMailer y permite simular fallas de envío
 type MockEmailService struct {
-	sentEmails []MockEmail
-	errors     map[string]error
+	*services.MemoryMailer
+	errors map[string]error
 }
 
+var _ services.Mailer = (*MockEmailService)(nil)
+
 // MockEmail representa un email enviado
-type MockEmail struct {
-	To      string
-	Subject string
-	Body    string
-}
+type MockEmail = services.SentEmail
 
 // NewMockEmailService crea un nuevo mock del servicio de email
 func NewMockEmailService() *MockEmailService {
 	return &MockEmailService{
-		sentEmails: make([]MockEmail, 0),
-		errors:     make(map[string]error),
+		MemoryMailer: services.NewMemoryMailer(),
+		errors:       make(map[string]error),
 	}
 }
 
-// SetError configura un error para un método específico
+// SetError configura un error para un método específico; nil lo quita
 func (m *MockEmailService) SetError(method string, err error) {
+	if err == nil {
+		delete(m.errors, method)
+		return
+	}
 	m.errors[method] = err
 }
 
@@ -330,21 +335,5 @@ func (m *MockEmailService) SendEmail(to, subject, body string) error {
 		return err
 	}
 
-	m.sentEmails = append(m.sentEmails, MockEmail{
-		To:      to,
-		Subject: subject,
-		Body:    body,
-	})
-
-	return nil
-}
-
-// GetSentEmails devuelve los emails enviados
-func (m *MockEmailService) GetSentEmails() []MockEmail {
-	return m.sentEmails
-}
-
-// ClearSentEmails limpia la lista de emails enviados
-func (m *MockEmailService) ClearSentEmails() {
-	m.sentEmails = make([]MockEmail, 0)
+	return m.MemoryMailer.SendEmail(to, subject, body)
 }
@@ -0,0 +1,42 @@
+package unit
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+)
+
+func TestValidateAuspicio(t *testing.T) {
+	dia := func(s string) sql.NullTime {
+		fecha, _ := time.Parse("2006-01-02", s)
+		return sql.NullTime{Time: fecha, Valid: true}
+	}
+
+	tests := []struct {
+		nombre   string
+		auspicio models.Auspicio
+		valido   bool
+	}{
+		{"general sin vigencia", models.Auspicio{Nivel: models.NivelOro}, true},
+		{"vigencia de un día", models.Auspicio{Nivel: models.NivelPlata, VigenteDesde: dia("2025-03-01"), VigenteHasta: dia("2025-03-01")}, true},
+		{"solo inicio", models.Auspicio{Nivel: models.NivelBronce, VigenteDesde: dia("2025-03-01")}, true},
+		{"nivel desconocido", models.Auspicio{Nivel: "platino"}, false},
+		{"vigencia invertida", models.Auspicio{Nivel: models.NivelOro, VigenteDesde: dia("2025-03-02"), VigenteHasta: dia("2025-03-01")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nombre, func(t *testing.T) {
+			err := services.ValidateAuspicio(&tt.auspicio)
+			if tt.valido && err != nil {
+				t.Errorf("Expected valid, got %v", err)
+			}
+			if !tt.valido && !errors.Is(err, services.ErrAuspicioInvalido) {
+				t.Errorf("Expected ErrAuspicioInvalido, got %v", err)
+			}
+		})
+	}
+}
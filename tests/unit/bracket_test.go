@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+)
+
+func TestPlanBracket(t *testing.T) {
+	t.Run("power of two draw", func(t *testing.T) {
+		rounds, err := services.PlanBracket([]int{11, 12, 13, 14, 15, 16, 17, 18})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(rounds) != 3 {
+			t.Fatalf("Expected 3 rounds, got %d", len(rounds))
+		}
+
+		expectedFases := []string{models.FaseCuartos, models.FaseSemifinal, models.FaseFinal}
+		for i, fase := range expectedFases {
+			if rounds[i][0].Fase != fase {
+				t.Errorf("Round %d: expected fase %s, got %s", i+1, fase, rounds[i][0].Fase)
+			}
+		}
+
+		// Cabeza de serie 1 contra 8 y 2 contra 7, en mitades opuestas de la llave
+		first := rounds[0]
+		if first[0].Jugador1ID != 11 || first[0].Jugador2ID != 18 {
+			t.Errorf("Expected 11 vs 18 in first match, got %d vs %d", first[0].Jugador1ID, first[0].Jugador2ID)
+		}
+		if first[2].Jugador1ID != 12 || first[2].Jugador2ID != 17 {
+			t.Errorf("Expected 12 vs 17 in third match, got %d vs %d", first[2].Jugador1ID, first[2].Jugador2ID)
+		}
+
+		for _, match := range first {
+			if match.Bye {
+				t.Errorf("Expected no byes in a full draw, got bye at position %d", match.Posicion)
+			}
+		}
+	})
+
+	t.Run("byes for top seeds", func(t *testing.T) {
+		rounds, err := services.PlanBracket([]int{1, 2, 3, 4, 5, 6})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		byes := 0
+		for _, match := range rounds[0] {
+			if match.Bye {
+				byes++
+			}
+		}
+		if byes != 2 {
+			t.Errorf("Expected 2 byes, got %d", byes)
+		}
+
+		// Los preclasificados 1 y 2 pasan directamente a semifinales
+		semis := rounds[1]
+		if semis[0].Jugador1ID != 1 {
+			t.Errorf("Expected seed 1 placed in first semifinal, got %d", semis[0].Jugador1ID)
+		}
+		if semis[1].Jugador1ID != 2 {
+			t.Errorf("Expected seed 2 placed in second semifinal, got %d", semis[1].Jugador1ID)
+		}
+		if semis[0].Jugador2ID != 0 || semis[1].Jugador2ID != 0 {
+			t.Error("Expected the other semifinal slots to remain undefined")
+		}
+	})
+
+	t.Run("two players go straight to the final", func(t *testing.T) {
+		rounds, err := services.PlanBracket([]int{7, 9})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(rounds) != 1 || rounds[0][0].Fase != models.FaseFinal {
+			t.Errorf("Expected a single Final, got %+v", rounds)
+		}
+	})
+
+	t.Run("invalid seed lists", func(t *testing.T) {
+		invalid := [][]int{
+			{},
+			{1},
+			{1, 2, 2},
+			{1, 0, 3},
+		}
+
+		for _, seeds := range invalid {
+			_, err := services.PlanBracket(seeds)
+			if !errors.Is(err, services.ErrInvalidBracketSeeds) {
+				t.Errorf("Expected ErrInvalidBracketSeeds for %v, got %v", seeds, err)
+			}
+		}
+	})
+}
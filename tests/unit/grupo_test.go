@@ -0,0 +1,137 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+)
+
+func TestRoundRobinSchedule(t *testing.T) {
+	for _, n := range []int{2, 3, 4, 5, 6} {
+		jugadores := make([]int, n)
+		for i := range jugadores {
+			jugadores[i] = i + 1
+		}
+
+		jornadas := services.RoundRobinSchedule(jugadores)
+
+		expectedJornadas := n - 1
+		if n%2 == 1 {
+			expectedJornadas = n
+		}
+		if len(jornadas) != expectedJornadas {
+			t.Errorf("%d players: expected %d jornadas, got %d", n, expectedJornadas, len(jornadas))
+		}
+
+		cruces := make(map[[2]int]int)
+		for _, jornada := range jornadas {
+			jugaron := make(map[int]bool)
+			for _, cruce := range jornada {
+				if jugaron[cruce[0]] || jugaron[cruce[1]] {
+					t.Errorf("%d players: a player appears twice in the same jornada", n)
+				}
+				jugaron[cruce[0]] = true
+				jugaron[cruce[1]] = true
+
+				a, b := cruce[0], cruce[1]
+				if a > b {
+					a, b = b, a
+				}
+				cruces[[2]int{a, b}]++
+			}
+		}
+
+		if len(cruces) != n*(n-1)/2 {
+			t.Errorf("%d players: expected %d distinct matches, got %d", n, n*(n-1)/2, len(cruces))
+		}
+		for cruce, veces := range cruces {
+			if veces != 1 {
+				t.Errorf("%d players: match %v scheduled %d times", n, cruce, veces)
+			}
+		}
+	}
+}
+
+func TestComputeStandings(t *testing.T) {
+	t.Run("ordered by wins", func(t *testing.T) {
+		resultados := []services.ResultadoGrupo{
+			{Jugador1ID: 1, Jugador2ID: 2, GanadorID: 2, SetsJ1: 0, SetsJ2: 2, GamesJ1: 4, GamesJ2: 12},
+			{Jugador1ID: 1, Jugador2ID: 3, GanadorID: 1, SetsJ1: 2, SetsJ2: 1, GamesJ1: 15, GamesJ2: 13},
+			{Jugador1ID: 2, Jugador2ID: 3, GanadorID: 2, SetsJ1: 2, SetsJ2: 0, GamesJ1: 12, GamesJ2: 2},
+		}
+
+		tabla := services.ComputeStandings([]int{1, 2, 3}, resultados, services.DefaultCriteriosDesempate)
+
+		expected := []int{2, 1, 3}
+		for i, id := range expected {
+			if tabla[i].JugadorID != id {
+				t.Errorf("Position %d: expected jugador %d, got %d", i+1, id, tabla[i].JugadorID)
+			}
+		}
+
+		if tabla[0].Jugados != 2 || tabla[0].Ganados != 2 || tabla[0].SetsFavor != 4 || tabla[0].GamesContra != 6 {
+			t.Errorf("Unexpected row for leader: %+v", tabla[0])
+		}
+		if tabla[2].Posicion != 3 || tabla[2].Perdidos != 2 {
+			t.Errorf("Unexpected row for last place: %+v", tabla[2])
+		}
+	})
+
+	// Triple empate a una victoria: 1 le gana a 2, 2 a 3 y 3 a 1
+	circular := []services.ResultadoGrupo{
+		{Jugador1ID: 1, Jugador2ID: 2, GanadorID: 1, SetsJ1: 2, SetsJ2: 0, GamesJ1: 12, GamesJ2: 5},
+		{Jugador1ID: 2, Jugador2ID: 3, GanadorID: 2, SetsJ1: 2, SetsJ2: 1, GamesJ1: 14, GamesJ2: 12},
+		{Jugador1ID: 3, Jugador2ID: 1, GanadorID: 3, SetsJ1: 2, SetsJ2: 1, GamesJ1: 13, GamesJ2: 11},
+	}
+
+	t.Run("set difference breaks a circular tie", func(t *testing.T) {
+		tabla := services.ComputeStandings([]int{1, 2, 3}, circular, services.DefaultCriteriosDesempate)
+
+		// El enfrentamiento directo no desempata; diferencia de sets: 1 => +1, 3 => 0, 2 => -1
+		expected := []int{1, 3, 2}
+		for i, id := range expected {
+			if tabla[i].JugadorID != id {
+				t.Errorf("Position %d: expected jugador %d, got %d", i+1, id, tabla[i].JugadorID)
+			}
+		}
+	})
+
+	t.Run("head to head between two tied players", func(t *testing.T) {
+		resultados := []services.ResultadoGrupo{
+			{Jugador1ID: 1, Jugador2ID: 2, GanadorID: 2, SetsJ1: 1, SetsJ2: 2, GamesJ1: 14, GamesJ2: 13},
+			{Jugador1ID: 1, Jugador2ID: 3, GanadorID: 1, SetsJ1: 2, SetsJ2: 0, GamesJ1: 12, GamesJ2: 0},
+			{Jugador1ID: 2, Jugador2ID: 3, GanadorID: 3, SetsJ1: 1, SetsJ2: 2, GamesJ1: 10, GamesJ2: 12},
+			{Jugador1ID: 1, Jugador2ID: 4, GanadorID: 1, SetsJ1: 2, SetsJ2: 0, GamesJ1: 12, GamesJ2: 0},
+			{Jugador1ID: 2, Jugador2ID: 4, GanadorID: 2, SetsJ1: 2, SetsJ2: 0, GamesJ1: 12, GamesJ2: 1},
+		}
+
+		// 1 y 2 terminan con dos victorias; 1 tiene mejor diferencia pero 2 ganó el cruce
+		tabla := services.ComputeStandings([]int{1, 2, 3, 4}, resultados, services.DefaultCriteriosDesempate)
+		if tabla[0].JugadorID != 2 || tabla[1].JugadorID != 1 {
+			t.Errorf("Expected head to head to rank 2 over 1, got %d then %d", tabla[0].JugadorID, tabla[1].JugadorID)
+		}
+
+		tabla = services.ComputeStandings([]int{1, 2, 3, 4}, resultados, []string{models.DesempateDiferenciaSets})
+		if tabla[0].JugadorID != 1 {
+			t.Errorf("Expected set difference to rank 1 first, got %d", tabla[0].JugadorID)
+		}
+	})
+}
+
+func TestValidateCriteriosDesempate(t *testing.T) {
+	if err := services.ValidateCriteriosDesempate(services.DefaultCriteriosDesempate); err != nil {
+		t.Errorf("Expected default criteria to be valid, got %v", err)
+	}
+
+	invalid := [][]string{
+		{"puntos"},
+		{models.DesempateDiferenciaSets, models.DesempateDiferenciaSets},
+	}
+	for _, criterios := range invalid {
+		if err := services.ValidateCriteriosDesempate(criterios); !errors.Is(err, services.ErrInvalidGrupo) {
+			t.Errorf("Expected ErrInvalidGrupo for %v, got %v", criterios, err)
+		}
+	}
+}
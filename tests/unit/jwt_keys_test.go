@@ -0,0 +1,213 @@
+package unit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"copa-litoral-backend/config"
+	"copa-litoral-backend/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePEM guarda un bloque PEM como <kid>.pem en dir
+func writePEM(t *testing.T, dir, kid, tipo string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: tipo, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatalf("Failed to write key %s: %v", kid, err)
+	}
+}
+
+func writeRSAKey(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	writePEM(t, dir, kid, "PRIVATE KEY", der)
+	return key
+}
+
+func writeEd25519Key(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	writePEM(t, dir, kid, "PRIVATE KEY", der)
+	return key
+}
+
+func tokenKID(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &utils.Claims{})
+	if err != nil {
+		t.Fatalf("Failed to decode token: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestKeyRing(t *testing.T) {
+	t.Run("signs with the newest key and publishes all of them", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAKey(t, dir, "2026-01")
+		writeEd25519Key(t, dir, "2026-07")
+		os.WriteFile(filepath.Join(dir, "LEEME.txt"), []byte("no es una clave"), 0o600)
+
+		keys, err := utils.LoadKeyRing(dir, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if keys.ActiveKID() != "2026-07" {
+			t.Errorf("Expected active kid 2026-07, got %s", keys.ActiveKID())
+		}
+
+		token, err := utils.GenerateJWT(1, 0, "jugador", "sesion-1", keys, time.Minute)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if kid := tokenKID(t, token); kid != "2026-07" {
+			t.Errorf("Expected kid header 2026-07, got %q", kid)
+		}
+		if _, err := utils.ParseJWT(token, keys); err != nil {
+			t.Errorf("Expected token to verify, got %v", err)
+		}
+
+		jwks := keys.JWKS()
+		if len(jwks.Keys) != 2 {
+			t.Fatalf("Expected 2 published keys, got %d", len(jwks.Keys))
+		}
+		rsaJWK, edJWK := jwks.Keys[0], jwks.Keys[1]
+		if rsaJWK.Kid != "2026-01" || rsaJWK.Kty != "RSA" || rsaJWK.Alg != "RS256" || rsaJWK.E != "AQAB" || rsaJWK.N == "" {
+			t.Errorf("Unexpected RSA key: %+v", rsaJWK)
+		}
+		if edJWK.Kid != "2026-07" || edJWK.Kty != "OKP" || edJWK.Crv != "Ed25519" || edJWK.Alg != "EdDSA" || edJWK.X == "" {
+			t.Errorf("Unexpected Ed25519 key: %+v", edJWK)
+		}
+	})
+
+	t.Run("tokens from the previous key stay valid during rotation", func(t *testing.T) {
+		dir := t.TempDir()
+		anterior := writeRSAKey(t, dir, "2026-01")
+
+		antes, err := utils.LoadKeyRing(dir, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		token, _ := utils.GenerateJWT(1, 0, "jugador", "sesion-1", antes, time.Minute)
+
+		// Se agrega la clave nueva y la anterior queda solo con su parte pública
+		writeEd25519Key(t, dir, "2026-07")
+		der, _ := x509.MarshalPKIXPublicKey(&anterior.PublicKey)
+		writePEM(t, dir, "2026-01", "PUBLIC KEY", der)
+
+		despues, err := utils.LoadKeyRing(dir, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := utils.ParseJWT(token, despues); err != nil {
+			t.Errorf("Expected token signed with the retired key to verify, got %v", err)
+		}
+
+		// Una vez borrada la clave retirada, sus tokens dejan de ser válidos
+		os.Remove(filepath.Join(dir, "2026-01.pem"))
+		final, err := utils.LoadKeyRing(dir, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := utils.ParseJWT(token, final); err == nil {
+			t.Error("Expected token from a removed key to be rejected")
+		}
+	})
+
+	t.Run("active kid can be pinned", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRSAKey(t, dir, "2026-01")
+		writeEd25519Key(t, dir, "2026-07")
+
+		keys, err := utils.LoadKeyRing(dir, "2026-01")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if keys.ActiveKID() != "2026-01" {
+			t.Errorf("Expected active kid 2026-01, got %s", keys.ActiveKID())
+		}
+
+		if _, err := utils.LoadKeyRing(dir, "no-existe"); err == nil {
+			t.Error("Expected error for an unknown active kid")
+		}
+	})
+
+	t.Run("a public key cannot be the signing key", func(t *testing.T) {
+		dir := t.TempDir()
+		key := writeRSAKey(t, dir, "2026-01")
+		der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		writePEM(t, dir, "2026-01", "PUBLIC KEY", der)
+
+		if _, err := utils.LoadKeyRing(dir, ""); err == nil {
+			t.Error("Expected error without a private key")
+		}
+	})
+
+	t.Run("invalid key files are rejected", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "rota.pem"), []byte("no es PEM"), 0o600)
+
+		if _, err := utils.LoadKeyRing(dir, ""); err == nil || !strings.Contains(err.Error(), "rota") {
+			t.Errorf("Expected error naming the broken key, got %v", err)
+		}
+	})
+
+	t.Run("algorithm must match the key", func(t *testing.T) {
+		dir := t.TempDir()
+		key := writeRSAKey(t, dir, "2026-01")
+		keys, err := utils.LoadKeyRing(dir, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		// HS256 firmado con la clave pública RSA como secreto
+		publica, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, utils.Claims{
+			UserID:   1,
+			Rol:      "administrador",
+			SesionID: "sesion-1",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		})
+		token.Header["kid"] = "2026-01"
+		firmado, _ := token.SignedString(publica)
+
+		if _, err := utils.ParseJWT(firmado, keys); err == nil {
+			t.Error("Expected HS256 token to be rejected by an RSA key")
+		}
+	})
+
+	t.Run("without a keys directory the secret is used", func(t *testing.T) {
+		keys, err := utils.NewKeyRing(&config.Config{JWTSecret: "secreto"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		token, _ := utils.GenerateJWT(1, 0, "jugador", "sesion-1", keys, time.Minute)
+		if _, err := utils.ParseJWT(token, utils.NewHMACKeyRing("secreto")); err != nil {
+			t.Errorf("Expected token to verify with the secret, got %v", err)
+		}
+		if len(keys.JWKS().Keys) != 0 {
+			t.Error("Expected the secret not to be published")
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"copa-litoral-backend/config"
+	"copa-litoral-backend/services"
+)
+
+func newLoginThrottler() *services.LoginThrottler {
+	return services.NewLoginThrottler(&config.Config{
+		LoginMaxIntentos:   3,
+		LoginMaxIntentosIP: 10,
+		LoginBloqueoBase:   30,
+		LoginBloqueoMax:    2,
+	})
+}
+
+// esperaBloqueo devuelve la espera del bloqueo, o 0 si el login está permitido
+func esperaBloqueo(t *testing.T, throttler *services.LoginThrottler, username, ip string) time.Duration {
+	t.Helper()
+	err := throttler.Check(username, ip)
+	if err == nil {
+		return 0
+	}
+	var bloqueado *services.LoginBloqueadoError
+	if !errors.As(err, &bloqueado) {
+		t.Fatalf("Expected *LoginBloqueadoError, got %v", err)
+	}
+	return bloqueado.Espera
+}
+
+func TestLoginThrottler(t *testing.T) {
+	t.Run("locks username after max failures", func(t *testing.T) {
+		throttler := newLoginThrottler()
+
+		for i := 0; i < 2; i++ {
+			throttler.RegistrarFallo("jugador1", "10.0.0.1")
+			if espera := esperaBloqueo(t, throttler, "jugador1", "10.0.0.2"); espera != 0 {
+				t.Fatalf("Expected no lockout after %d failures, got %v", i+1, espera)
+			}
+		}
+
+		throttler.RegistrarFallo("JUGADOR1 ", "10.0.0.1")
+		espera := esperaBloqueo(t, throttler, "jugador1", "10.0.0.2")
+		if espera <= 0 || espera > 30*time.Second {
+			t.Errorf("Expected a lockout of up to 30s from any IP, got %v", espera)
+		}
+	})
+
+	t.Run("backoff doubles up to the maximum", func(t *testing.T) {
+		throttler := newLoginThrottler()
+
+		esperados := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute}
+		for i := 0; i < 2; i++ {
+			throttler.RegistrarFallo("jugador1", "10.0.0.1")
+		}
+		for _, esperado := range esperados {
+			throttler.RegistrarFallo("jugador1", "10.0.0.1")
+			espera := esperaBloqueo(t, throttler, "jugador1", "10.0.0.1")
+			if espera > esperado || espera < esperado-time.Second {
+				t.Errorf("Expected lockout of %v, got %v", esperado, espera)
+			}
+		}
+	})
+
+	t.Run("retry after rounds up", func(t *testing.T) {
+		err := &services.LoginBloqueadoError{Espera: 1500 * time.Millisecond}
+		if got := err.RetryAfterSeconds(); got != 2 {
+			t.Errorf("Expected 2 seconds, got %d", got)
+		}
+	})
+
+	t.Run("locks IP across usernames", func(t *testing.T) {
+		throttler := newLoginThrottler()
+
+		for i := 0; i < 10; i++ {
+			throttler.RegistrarFallo(string(rune('a'+i))+"-usuario", "10.0.0.9")
+		}
+
+		if espera := esperaBloqueo(t, throttler, "otro-usuario", "10.0.0.9"); espera == 0 {
+			t.Error("Expected the IP to be locked")
+		}
+		if espera := esperaBloqueo(t, throttler, "otro-usuario", "10.0.0.10"); espera != 0 {
+			t.Errorf("Expected other IPs to be allowed, got %v", espera)
+		}
+	})
+
+	t.Run("unknown usernames are throttled like existing ones", func(t *testing.T) {
+		throttler := newLoginThrottler()
+
+		for i := 0; i < 3; i++ {
+			throttler.RegistrarFallo("no-existe", "10.0.0.1")
+			throttler.RegistrarFallo("jugador1", "10.0.0.2")
+		}
+
+		inexistente := esperaBloqueo(t, throttler, "no-existe", "10.0.0.3")
+		existente := esperaBloqueo(t, throttler, "jugador1", "10.0.0.3")
+		if inexistente == 0 || existente == 0 || (inexistente-existente).Abs() > time.Second {
+			t.Errorf("Expected equal lockouts, got %v and %v", inexistente, existente)
+		}
+	})
+
+	t.Run("unlock and success clear the username", func(t *testing.T) {
+		throttler := newLoginThrottler()
+
+		for i := 0; i < 3; i++ {
+			throttler.RegistrarFallo("jugador1", "10.0.0.1")
+			throttler.RegistrarFallo("jugador2", "10.0.0.2")
+		}
+
+		throttler.Unlock("jugador1")
+		if espera := esperaBloqueo(t, throttler, "jugador1", "10.0.0.3"); espera != 0 {
+			t.Errorf("Expected unlocked username, got %v", espera)
+		}
+
+		throttler.RegistrarExito("jugador2")
+		if espera := esperaBloqueo(t, throttler, "jugador2", "10.0.0.3"); espera != 0 {
+			t.Errorf("Expected cleared username after success, got %v", espera)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"testing"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		desde, hacia models.EstadoPartido
+		permitida    bool
+	}{
+		{models.EstadoPorDefinir, models.EstadoPendiente, true},
+		{models.EstadoPendiente, models.EstadoAgendado, true},
+		{models.EstadoAgendado, models.EstadoEnJuego, true},
+		{models.EstadoEnJuego, models.EstadoReportado, true},
+		{models.EstadoReportado, models.EstadoFinalizado, true},
+		{models.EstadoReportado, models.EstadoReportado, true},
+		{models.EstadoReportado, models.EstadoEnDisputa, true},
+		{models.EstadoEnDisputa, models.EstadoFinalizado, true},
+		{models.EstadoPendiente, models.EstadoWalkover, true},
+		{models.EstadoAgendado, models.EstadoCancelado, true},
+		{models.EstadoPorDefinir, models.EstadoAgendado, false},
+		{models.EstadoPendiente, models.EstadoReportado, false},
+		{models.EstadoEnJuego, models.EstadoWalkover, false},
+		{models.EstadoReportado, models.EstadoCancelado, false},
+		{models.EstadoEnDisputa, models.EstadoReportado, false},
+		{models.EstadoPendiente, models.EstadoEnDisputa, false},
+		{models.EstadoFinalizado, models.EstadoReportado, false},
+		{models.EstadoWalkover, models.EstadoFinalizado, false},
+		{models.EstadoCancelado, models.EstadoPendiente, false},
+		{models.EstadoPendiente, models.EstadoPartido("Jugado"), false},
+	}
+
+	for _, tt := range tests {
+		if got := services.CanTransition(tt.desde, tt.hacia); got != tt.permitida {
+			t.Errorf("%s -> %s: expected %v, got %v", tt.desde, tt.hacia, tt.permitida, got)
+		}
+	}
+}
+
+func TestPartidoTieneJugador(t *testing.T) {
+	partido := &models.Partido{Jugador1ID: 3, Jugador2ID: 8}
+
+	for jugadorID, esperado := range map[int]bool{3: true, 8: true, 5: false, 0: false} {
+		if got := partido.TieneJugador(jugadorID); got != esperado {
+			t.Errorf("jugador %d: expected %v, got %v", jugadorID, esperado, got)
+		}
+	}
+
+	porDefinir := &models.Partido{Jugador1ID: 3}
+	if porDefinir.TieneJugador(0) {
+		t.Error("Expected an undefined rival slot not to match jugador 0")
+	}
+}
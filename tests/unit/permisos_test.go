@@ -0,0 +1,138 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"copa-litoral-backend/middlewares"
+	"copa-litoral-backend/models"
+)
+
+func TestConcesionesPermite(t *testing.T) {
+	concesiones := models.Concesiones{
+		{Permiso: models.PermisoNoticiasPublish},
+		{Permiso: models.PermisoPartidosApprove, CategoriaID: 3},
+		{Permiso: models.PermisoLlavesEdit, TorneoID: 1, CategoriaID: 5},
+		{Permiso: models.PermisoPartidosSchedule, TorneoID: 2},
+	}
+
+	tests := []struct {
+		nombre   string
+		permiso  string
+		alcance  models.Alcance
+		esperado bool
+	}{
+		{"global covers any scope", models.PermisoNoticiasPublish, models.Alcance{TorneoID: 9, CategoriaID: 9}, true},
+		{"global covers unscoped resources", models.PermisoNoticiasPublish, models.Alcance{}, true},
+		{"categoria in any torneo", models.PermisoPartidosApprove, models.Alcance{TorneoID: 7, CategoriaID: 3}, true},
+		{"other categoria", models.PermisoPartidosApprove, models.Alcance{TorneoID: 7, CategoriaID: 4}, false},
+		{"scoped grant does not cover unscoped resources", models.PermisoPartidosApprove, models.Alcance{}, false},
+		{"torneo and categoria must both match", models.PermisoLlavesEdit, models.Alcance{TorneoID: 1, CategoriaID: 5}, true},
+		{"categoria of another torneo", models.PermisoLlavesEdit, models.Alcance{TorneoID: 2, CategoriaID: 5}, false},
+		{"any categoria of the torneo", models.PermisoPartidosSchedule, models.Alcance{TorneoID: 2, CategoriaID: 8}, true},
+		{"missing permission", models.PermisoRolesManage, models.Alcance{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nombre, func(t *testing.T) {
+			if got := concesiones.Permite(tt.permiso, tt.alcance); got != tt.esperado {
+				t.Errorf("Expected %v, got %v", tt.esperado, got)
+			}
+		})
+	}
+
+	if !concesiones.TienePermiso(models.PermisoPartidosApprove) || concesiones.TienePermiso(models.PermisoRolesManage) {
+		t.Error("Unexpected TienePermiso result")
+	}
+}
+
+func TestConcesionesLimitar(t *testing.T) {
+	concesiones := models.Concesiones{
+		{Permiso: models.PermisoPartidosApprove, CategoriaID: 3},
+		{Permiso: models.PermisoPartidosApprove, TorneoID: 1},
+		{Permiso: models.PermisoRolesManage},
+	}
+
+	limitadas := concesiones.Limitar([]string{models.PermisoPartidosApprove, models.PermisoNoticiasPublish})
+
+	if len(limitadas) != 2 || limitadas.TienePermiso(models.PermisoRolesManage) {
+		t.Errorf("Expected only partidos:approve grants, got %v", limitadas)
+	}
+	if limitadas.TienePermiso(models.PermisoNoticiasPublish) {
+		t.Error("Limiting must not add permissions the user does not have")
+	}
+	if !limitadas.Permite(models.PermisoPartidosApprove, models.Alcance{CategoriaID: 3}) ||
+		limitadas.Permite(models.PermisoPartidosApprove, models.Alcance{}) {
+		t.Error("Expected grants to keep their scope")
+	}
+}
+
+// permisosDePrueba responde siempre las mismas concesiones
+type permisosDePrueba models.Concesiones
+
+func (p permisosDePrueba) GetConcesiones(usuarioID int) (models.Concesiones, error) {
+	return models.Concesiones(p), nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	middlewares.SetPermissionChecker(permisosDePrueba{
+		{Permiso: models.PermisoPartidosApprove, CategoriaID: 3},
+		{Permiso: models.PermisoNoticiasPublish},
+	})
+	t.Cleanup(func() { middlewares.SetPermissionChecker(nil) })
+
+	resoluciones := 0
+	alcanceFijo := func(alcance models.Alcance) middlewares.AlcanceResolver {
+		return func(r *http.Request) (models.Alcance, error) {
+			resoluciones++
+			return alcance, nil
+		}
+	}
+
+	tests := []struct {
+		nombre   string
+		permiso  string
+		resolver middlewares.AlcanceResolver
+		esperado int
+		resuelve bool
+	}{
+		{"resource in scope", models.PermisoPartidosApprove, alcanceFijo(models.Alcance{TorneoID: 1, CategoriaID: 3}), http.StatusOK, true},
+		{"resource out of scope", models.PermisoPartidosApprove, alcanceFijo(models.Alcance{TorneoID: 1, CategoriaID: 4}), http.StatusForbidden, true},
+		{"no resolver requires global grant", models.PermisoPartidosApprove, nil, http.StatusForbidden, false},
+		{"global grant skips the resolver", models.PermisoNoticiasPublish, alcanceFijo(models.Alcance{CategoriaID: 4}), http.StatusOK, false},
+		{"missing permission skips the resolver", models.PermisoRolesManage, alcanceFijo(models.Alcance{CategoriaID: 3}), http.StatusForbidden, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nombre, func(t *testing.T) {
+			resoluciones = 0
+			handler := middlewares.RequirePermission(tt.permiso, tt.resolver)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("POST", "/", nil)
+			req = req.WithContext(context.WithValue(req.Context(), middlewares.UserIDKey, 30))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.esperado {
+				t.Errorf("Expected %d, got %d", tt.esperado, w.Code)
+			}
+			if (resoluciones > 0) != tt.resuelve {
+				t.Errorf("Expected scope resolution %v, got %d calls", tt.resuelve, resoluciones)
+			}
+		})
+	}
+
+	t.Run("without user in context", func(t *testing.T) {
+		handler := middlewares.RequirePermission(models.PermisoNoticiasPublish, nil)(http.NotFoundHandler())
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", w.Code)
+		}
+	})
+}
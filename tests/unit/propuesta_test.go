@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"copa-litoral-backend/services"
+)
+
+func TestValidateHorarios(t *testing.T) {
+	manana := time.Now().Add(24 * time.Hour).Truncate(time.Minute)
+
+	tests := []struct {
+		name     string
+		horarios []time.Time
+		valid    bool
+	}{
+		{"single future slot", []time.Time{manana}, true},
+		{"several future slots", []time.Time{manana, manana.Add(2 * time.Hour), manana.Add(24 * time.Hour)}, true},
+		{"no slots", nil, false},
+		{"slot in the past", []time.Time{time.Now().Add(-time.Hour)}, false},
+		{"duplicated slot", []time.Time{manana, manana}, false},
+		{"too many slots", []time.Time{
+			manana, manana.Add(time.Hour), manana.Add(2 * time.Hour),
+			manana.Add(3 * time.Hour), manana.Add(4 * time.Hour), manana.Add(5 * time.Hour),
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateHorarios(tt.horarios)
+			if tt.valid && err != nil {
+				t.Errorf("expected valid slots, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, services.ErrInvalidPropuesta) {
+				t.Errorf("expected ErrInvalidPropuesta, got %v", err)
+			}
+		})
+	}
+}
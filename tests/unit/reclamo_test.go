@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"testing"
+
+	"copa-litoral-backend/services"
+	"copa-litoral-backend/utils"
+)
+
+func TestTelefonosCoinciden(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		esperado bool
+	}{
+		{"+54 9 341 555-1234", "3415551234", true},
+		{"341 5551234", "(0341) 15-555-1234", false},
+		{"3415551234", "3415551234", true},
+		{"3415551234", "3415551235", false},
+		{"5551234", "5551234", false}, // Muy pocos dígitos para identificar al jugador
+		{"", "3415551234", false},
+	}
+
+	for _, tt := range tests {
+		if got := services.TelefonosCoinciden(tt.a, tt.b); got != tt.esperado {
+			t.Errorf("%q vs %q: expected %v, got %v", tt.a, tt.b, tt.esperado, got)
+		}
+	}
+}
+
+func TestNombresCoinciden(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		esperado bool
+	}{
+		{"José María", "jose  maria", true},
+		{"PÉREZ", "perez", true},
+		{"Pérez", "Peres", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := services.NombresCoinciden(tt.a, tt.b); got != tt.esperado {
+			t.Errorf("%q vs %q: expected %v, got %v", tt.a, tt.b, tt.esperado, got)
+		}
+	}
+}
+
+func TestGenerateOneTimeCode(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		codigo, err := utils.GenerateOneTimeCode(6)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(codigo) != 6 {
+			t.Fatalf("Expected 6 digits, got %q", codigo)
+		}
+		for _, c := range codigo {
+			if c < '0' || c > '9' {
+				t.Fatalf("Expected only digits, got %q", codigo)
+			}
+		}
+	}
+}
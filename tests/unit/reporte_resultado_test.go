@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"testing"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+)
+
+func TestResultadosCoinciden(t *testing.T) {
+	base := func(sets ...models.SetPartido) services.MatchScore {
+		return services.MatchScore{
+			Jugador1ID: 1, Jugador2ID: 2,
+			SetsGanadosJ1: 2, SetsGanadosJ2: 1,
+			GanadorID: 1,
+			Sets:      sets,
+		}
+	}
+	reporte := base(scoreSet(6, 4), tieBreakSet(6, 7, 5, 7), scoreSet(6, 2))
+
+	tests := []struct {
+		name     string
+		otro     services.MatchScore
+		esperado bool
+	}{
+		{"mismo resultado", base(scoreSet(6, 4), tieBreakSet(6, 7, 5, 7), scoreSet(6, 2)), true},
+		{"distinto game", base(scoreSet(6, 3), tieBreakSet(6, 7, 5, 7), scoreSet(6, 2)), false},
+		{"distinto tie-break", base(scoreSet(6, 4), tieBreakSet(6, 7, 8, 10), scoreSet(6, 2)), false},
+		{"tie-break omitido", base(scoreSet(6, 4), scoreSet(6, 7), scoreSet(6, 2)), false},
+		{"falta un set", base(scoreSet(6, 4), tieBreakSet(6, 7, 5, 7)), false},
+		{"distinto ganador", func() services.MatchScore {
+			s := base(scoreSet(6, 4), tieBreakSet(6, 7, 5, 7), scoreSet(6, 2))
+			s.GanadorID = 2
+			return s
+		}(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := services.ResultadosCoinciden(reporte, tt.otro); got != tt.esperado {
+				t.Errorf("expected %v, got %v", tt.esperado, got)
+			}
+		})
+	}
+}
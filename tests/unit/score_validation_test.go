@@ -0,0 +1,232 @@
+package unit
+
+import (
+	"database/sql"
+	"testing"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+)
+
+func scoreSet(j1, j2 int) models.SetPartido {
+	return models.SetPartido{ScoreJugador1: j1, ScoreJugador2: j2}
+}
+
+func tieBreakSet(j1, j2, tb1, tb2 int) models.SetPartido {
+	return models.SetPartido{
+		ScoreJugador1: j1,
+		ScoreJugador2: j2,
+		TieBreakJ1:    sql.NullInt32{Int32: int32(tb1), Valid: true},
+		TieBreakJ2:    sql.NullInt32{Int32: int32(tb2), Valid: true},
+	}
+}
+
+func TestValidateMatchScore(t *testing.T) {
+	mejorDe3 := services.FormatosPartido[services.FormatoMejorDe3]
+	superTieBreak := services.FormatosPartido[services.FormatoMejorDe3SuperTieBreak]
+	proSet := services.FormatosPartido[services.FormatoProSet8]
+
+	tests := []struct {
+		name    string
+		formato services.FormatoPartido
+		score   services.MatchScore
+		valid   bool
+		field   string
+	}{
+		{
+			name:    "straight sets",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 1, Sets: []models.SetPartido{scoreSet(6, 3), scoreSet(7, 5)}},
+			valid:   true,
+		},
+		{
+			name:    "three sets with tie-break",
+			formato: mejorDe3,
+			score: services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 1, SetsGanadosJ2: 2, GanadorID: 2,
+				Sets: []models.SetPartido{scoreSet(6, 4), tieBreakSet(6, 7, 5, 7), scoreSet(2, 6)}},
+			valid: true,
+		},
+		{
+			name:    "set without two-game margin",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 1, Sets: []models.SetPartido{scoreSet(6, 5), scoreSet(6, 0)}},
+			field:   "sets[0]",
+		},
+		{
+			name:    "7-6 without tie-break points",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 1, Sets: []models.SetPartido{scoreSet(7, 6), scoreSet(6, 0)}},
+			field:   "sets[0].tie_break",
+		},
+		{
+			name:    "tie-break won by the set loser",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 1, Sets: []models.SetPartido{tieBreakSet(7, 6, 4, 7), scoreSet(6, 0)}},
+			field:   "sets[0].tie_break",
+		},
+		{
+			name:    "tie-break without two-point margin",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 1, Sets: []models.SetPartido{tieBreakSet(7, 6, 7, 6), scoreSet(6, 0)}},
+			field:   "sets[0].tie_break",
+		},
+		{
+			name:    "sets won do not match scores",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, SetsGanadosJ2: 1, GanadorID: 1, Sets: []models.SetPartido{scoreSet(6, 3), scoreSet(6, 3)}},
+			field:   "sets_ganados_j2",
+		},
+		{
+			name:    "winner does not match scores",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 2, Sets: []models.SetPartido{scoreSet(6, 3), scoreSet(6, 3)}},
+			field:   "ganador_id",
+		},
+		{
+			name:    "winner is not a player",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 9, Sets: []models.SetPartido{scoreSet(6, 3), scoreSet(6, 3)}},
+			field:   "ganador_id",
+		},
+		{
+			name:    "match not finished",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 1, SetsGanadosJ2: 1, GanadorID: 1, Sets: []models.SetPartido{scoreSet(6, 3), scoreSet(3, 6)}},
+			field:   "sets",
+		},
+		{
+			name:    "extra set after match decided",
+			formato: mejorDe3,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 1, Sets: []models.SetPartido{scoreSet(6, 3), scoreSet(6, 3), scoreSet(6, 1)}},
+			field:   "sets[2]",
+		},
+		{
+			name:    "super tie-break deciding set",
+			formato: superTieBreak,
+			score: services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, SetsGanadosJ2: 1, GanadorID: 1,
+				Sets: []models.SetPartido{scoreSet(4, 6), scoreSet(6, 2), scoreSet(12, 10)}},
+			valid: true,
+		},
+		{
+			name:    "super tie-break below ten points",
+			formato: superTieBreak,
+			score: services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, SetsGanadosJ2: 1, GanadorID: 1,
+				Sets: []models.SetPartido{scoreSet(4, 6), scoreSet(6, 2), scoreSet(7, 3)}},
+			field: "sets[2]",
+		},
+		{
+			name:    "pro-set to 8",
+			formato: proSet,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ2: 1, GanadorID: 2, Sets: []models.SetPartido{scoreSet(6, 8)}},
+			valid:   true,
+		},
+		{
+			name:    "pro-set scored as a regular set",
+			formato: proSet,
+			score:   services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 1, GanadorID: 1, Sets: []models.SetPartido{scoreSet(6, 2)}},
+			field:   "sets[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateMatchScore(tt.formato, tt.score)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("expected valid score, got %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected validation error on %s", tt.field)
+			}
+			found := false
+			for _, detail := range err.Errors {
+				if detail.Field == tt.field {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected error on field %s, got %v", tt.field, err.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateMatchScoreNumbersSets(t *testing.T) {
+	sets := []models.SetPartido{scoreSet(6, 1), scoreSet(6, 2)}
+	score := services.MatchScore{Jugador1ID: 1, Jugador2ID: 2, SetsGanadosJ1: 2, GanadorID: 1, Sets: sets}
+
+	if err := services.ValidateMatchScore(services.FormatosPartido[services.FormatoMejorDe3], score); err != nil {
+		t.Fatalf("expected valid score, got %v", err)
+	}
+	if sets[0].NumeroSet != 1 || sets[1].NumeroSet != 2 {
+		t.Errorf("expected sets numbered 1 and 2, got %d and %d", sets[0].NumeroSet, sets[1].NumeroSet)
+	}
+}
+
+func TestValidatePartialScore(t *testing.T) {
+	mejorDe3 := services.FormatosPartido[services.FormatoMejorDe3]
+	superTieBreak := services.FormatosPartido[services.FormatoMejorDe3SuperTieBreak]
+
+	t.Run("walkover credits the beneficiary", func(t *testing.T) {
+		setsJ1, setsJ2, err := services.ValidatePartialScore(mejorDe3, 1, 2, 2, nil)
+		if err != nil {
+			t.Fatalf("expected valid walkover, got %v", err)
+		}
+		if setsJ1 != 0 || setsJ2 != 2 {
+			t.Errorf("expected 0-2 sets, got %d-%d", setsJ1, setsJ2)
+		}
+	})
+
+	t.Run("retirement mid-set", func(t *testing.T) {
+		sets := []models.SetPartido{scoreSet(6, 3), scoreSet(2, 6), scoreSet(3, 1)}
+		setsJ1, setsJ2, err := services.ValidatePartialScore(mejorDe3, 1, 2, 1, sets)
+		if err != nil {
+			t.Fatalf("expected valid retirement, got %v", err)
+		}
+		if setsJ1 != 2 || setsJ2 != 1 {
+			t.Errorf("expected 2-1 sets, got %d-%d", setsJ1, setsJ2)
+		}
+	})
+
+	t.Run("retirement between sets", func(t *testing.T) {
+		sets := []models.SetPartido{scoreSet(6, 3), scoreSet(2, 6)}
+		setsJ1, setsJ2, err := services.ValidatePartialScore(mejorDe3, 1, 2, 2, sets)
+		if err != nil {
+			t.Fatalf("expected valid retirement, got %v", err)
+		}
+		if setsJ1 != 1 || setsJ2 != 2 {
+			t.Errorf("expected 1-2 sets, got %d-%d", setsJ1, setsJ2)
+		}
+	})
+
+	t.Run("retirement during super tie-break", func(t *testing.T) {
+		sets := []models.SetPartido{scoreSet(6, 3), scoreSet(2, 6), scoreSet(5, 7)}
+		if _, _, err := services.ValidatePartialScore(superTieBreak, 1, 2, 1, sets); err != nil {
+			t.Fatalf("expected valid retirement, got %v", err)
+		}
+	})
+
+	t.Run("sets already decide the match", func(t *testing.T) {
+		sets := []models.SetPartido{scoreSet(6, 3), scoreSet(6, 2)}
+		if _, _, err := services.ValidatePartialScore(mejorDe3, 1, 2, 1, sets); err == nil {
+			t.Error("expected error for a finished match")
+		}
+	})
+
+	t.Run("impossible in-progress set", func(t *testing.T) {
+		sets := []models.SetPartido{scoreSet(6, 3), scoreSet(7, 3)}
+		if _, _, err := services.ValidatePartialScore(mejorDe3, 1, 2, 1, sets); err == nil {
+			t.Error("expected error for an impossible set score")
+		}
+	})
+
+	t.Run("beneficiary is not a player", func(t *testing.T) {
+		_, _, err := services.ValidatePartialScore(mejorDe3, 1, 2, 5, nil)
+		if err == nil || err.Errors[0].Field != "beneficiario_id" {
+			t.Errorf("expected beneficiario_id error, got %v", err)
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"testing"
 
+	"copa-litoral-backend/config"
+	"copa-litoral-backend/services"
 	"copa-litoral-backend/tests/mocks"
 )
 
@@ -220,9 +222,14 @@ func TestEmailService(t *testing.T) {
 		if err == nil {
 			t.Error("Expected error from mock service")
 		}
+		if emails := mockService.GetSentEmails(); len(emails) != 1 {
+			t.Errorf("Expected failed email not to be retained, got %d emails", len(emails))
+		}
 	})
 
 	t.Run("clear sent emails", func(t *testing.T) {
+		// Mock propio: los subtests anteriores dejan un correo y un error configurado
+		mockService := mocks.NewMockEmailService()
 		mockService.SendEmail("test1@example.com", "Subject 1", "Body 1")
 		mockService.SendEmail("test2@example.com", "Subject 2", "Body 2")
 
@@ -239,6 +246,44 @@ func TestEmailService(t *testing.T) {
 	})
 }
 
+func TestMailer(t *testing.T) {
+	t.Run("memory mailer without SMTP host", func(t *testing.T) {
+		mailer := services.NewMailer(&config.Config{})
+
+		memoria, ok := mailer.(*services.MemoryMailer)
+		if !ok {
+			t.Fatalf("Expected *services.MemoryMailer, got %T", mailer)
+		}
+
+		memoria.SendEmail("jugador@example.com", "Verificá tu email", "enlace")
+		emails := memoria.GetSentEmails()
+		if len(emails) != 1 || emails[0].To != "jugador@example.com" {
+			t.Errorf("Expected the email to be retained, got %+v", emails)
+		}
+
+		memoria.ClearSentEmails()
+		if len(memoria.GetSentEmails()) != 0 {
+			t.Error("Expected no emails after clear")
+		}
+	})
+
+	t.Run("SMTP mailer with SMTP host", func(t *testing.T) {
+		mailer := services.NewMailer(&config.Config{EmailSMTPHost: "smtp.example.com", EmailSMTPPort: "587"})
+
+		if _, ok := mailer.(*services.SMTPMailer); !ok {
+			t.Errorf("Expected *services.SMTPMailer, got %T", mailer)
+		}
+	})
+
+	t.Run("SMTP mailer rejects header injection", func(t *testing.T) {
+		mailer := services.NewSMTPMailer(&config.Config{EmailSMTPHost: "127.0.0.1", EmailSMTPPort: "1"})
+
+		if err := mailer.SendEmail("a@example.com\r\nBcc: b@example.com", "Asunto", "Cuerpo"); err == nil {
+			t.Error("Expected error for a recipient with line breaks")
+		}
+	})
+}
+
 func TestHTTPClient(t *testing.T) {
 	mockClient := mocks.NewMockHTTPClient()
 
@@ -259,7 +304,7 @@ func TestHTTPClient(t *testing.T) {
 			t.Errorf("Expected status 200, got %d", response.StatusCode)
 		}
 		if response.Body != `{"success": true}` {
-			t.Errorf("Expected body '{}', got %s", `{"success": true}`, response.Body)
+			t.Errorf("Expected body '%s', got %s", `{"success": true}`, response.Body)
 		}
 	})
 
@@ -0,0 +1,34 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"copa-litoral-backend/utils"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		texto    string
+		esperado string
+	}{
+		{"Final de la Copa Litoral", "final-de-la-copa-litoral"},
+		{"Campeón 2024: ¡Gran final!", "campeon-2024-gran-final"},
+		{"Año nuevo, pingüino y ñandú", "ano-nuevo-pinguino-y-nandu"},
+		{"ÁRBITROS DE LA SEMANA", "arbitros-de-la-semana"},
+		{"  Doble   espacio -- y guiones  ", "doble-espacio-y-guiones"},
+		{"L'equipe &amp; el &quot;Abierto&quot;", "lequipe-el-abierto"},
+		{"¿?¡!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := utils.Slugify(tt.texto); got != tt.esperado {
+			t.Errorf("Slugify(%q): expected %q, got %q", tt.texto, tt.esperado, got)
+		}
+	}
+
+	largo := utils.Slugify(strings.Repeat("palabra ", 60))
+	if len(largo) > utils.LargoMaximoSlug || strings.HasSuffix(largo, "-") {
+		t.Errorf("Expected a trimmed slug of at most %d characters, got %d", utils.LargoMaximoSlug, len(largo))
+	}
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"copa-litoral-backend/services"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	storage := services.NewLocalStorage(dir, "/uploads/")
+	ctx := context.Background()
+
+	url, err := storage.Put(ctx, "fotos/2026/05/abc.jpg", []byte("contenido"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if url != "/uploads/fotos/2026/05/abc.jpg" {
+		t.Errorf("Unexpected URL %s", url)
+	}
+
+	t.Run("serves stored files but not directories", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		storage.Handler().ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "contenido" {
+			t.Errorf("Expected the file, got %d %q", rec.Code, rec.Body.String())
+		}
+
+		rec = httptest.NewRecorder()
+		storage.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/uploads/fotos/", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Expected directory listing to be hidden, got %d", rec.Code)
+		}
+	})
+
+	t.Run("rejects keys outside the directory", func(t *testing.T) {
+		for _, clave := range []string{"../fuera.jpg", "fotos/../../fuera.jpg", "/absoluta.jpg", ""} {
+			if _, err := storage.Put(ctx, clave, []byte("x"), "image/jpeg"); err == nil {
+				t.Errorf("Expected %q to be rejected", clave)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "fuera.jpg")); err == nil {
+			t.Error("A file was written outside the storage directory")
+		}
+	})
+
+	t.Run("deletes files and ignores missing ones", func(t *testing.T) {
+		if err := storage.Delete(ctx, "fotos/2026/05/abc.jpg"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "fotos", "2026", "05", "abc.jpg")); !os.IsNotExist(err) {
+			t.Error("Expected the file to be deleted")
+		}
+		if err := storage.Delete(ctx, "fotos/2026/05/abc.jpg"); err != nil {
+			t.Errorf("Expected deleting a missing file to succeed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"testing"
+
+	"copa-litoral-backend/models"
+	"copa-litoral-backend/services"
+)
+
+func TestCanTransitionTorneo(t *testing.T) {
+	tests := []struct {
+		desde, hacia models.EstadoTorneo
+		permitida    bool
+	}{
+		{models.TorneoPlanificado, models.TorneoInscripcionAbierta, true},
+		{models.TorneoInscripcionAbierta, models.TorneoInscripcionCerrada, true},
+		{models.TorneoInscripcionCerrada, models.TorneoInscripcionAbierta, true},
+		{models.TorneoInscripcionCerrada, models.TorneoEnCurso, true},
+		{models.TorneoEnCurso, models.TorneoFinalizado, true},
+		{models.TorneoFinalizado, models.TorneoArchivado, true},
+		{models.TorneoPlanificado, models.TorneoEnCurso, false},
+		{models.TorneoInscripcionAbierta, models.TorneoEnCurso, false},
+		{models.TorneoEnCurso, models.TorneoInscripcionAbierta, false},
+		{models.TorneoFinalizado, models.TorneoEnCurso, false},
+		{models.TorneoArchivado, models.TorneoFinalizado, false},
+		{models.TorneoEnCurso, models.TorneoEnCurso, false},
+		{models.TorneoPlanificado, models.EstadoTorneo("activo"), false},
+	}
+
+	for _, tt := range tests {
+		if got := services.CanTransitionTorneo(tt.desde, tt.hacia); got != tt.permitida {
+			t.Errorf("%s -> %s: expected %v, got %v", tt.desde, tt.hacia, tt.permitida, got)
+		}
+	}
+}
+
+func TestEstadoTorneoEnJuego(t *testing.T) {
+	for estado, esperado := range map[models.EstadoTorneo]bool{
+		models.TorneoPlanificado:        false,
+		models.TorneoInscripcionAbierta: true,
+		models.TorneoInscripcionCerrada: true,
+		models.TorneoEnCurso:            true,
+		models.TorneoFinalizado:         false,
+		models.TorneoArchivado:          false,
+	} {
+		if got := estado.EnJuego(); got != esperado {
+			t.Errorf("%s: expected %v, got %v", estado, esperado, got)
+		}
+	}
+}
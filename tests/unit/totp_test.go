@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"copa-litoral-backend/utils"
+)
+
+// secretoRFC es la clave de los vectores de prueba del RFC 6238 para SHA-1
+var secretoRFC = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// Últimos seis dígitos de los vectores del apéndice B del RFC 6238
+	tests := []struct {
+		unix     int64
+		esperado string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		codigo, err := utils.TOTPCode(secretoRFC, utils.TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if codigo != tt.esperado {
+			t.Errorf("At %d expected %s, got %s", tt.unix, tt.esperado, codigo)
+		}
+	}
+
+	if _, err := utils.TOTPCode("no es base32!", 1); err == nil {
+		t.Error("Expected error for invalid secret")
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secreto, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	ahora := time.Unix(1700000000, 0)
+	paso := utils.TOTPStep(ahora)
+
+	codigoDe := func(p int64) string {
+		codigo, err := utils.TOTPCode(secreto, p)
+		if err != nil {
+			t.Fatalf("Failed to compute code: %v", err)
+		}
+		return codigo
+	}
+
+	t.Run("accepts adjacent steps for clock drift", func(t *testing.T) {
+		for _, p := range []int64{paso - 1, paso, paso + 1} {
+			got, ok := utils.VerifyTOTP(secreto, codigoDe(p), ahora)
+			if !ok || got != p {
+				t.Errorf("Expected step %d to be accepted, got %d %v", p, got, ok)
+			}
+		}
+	})
+
+	t.Run("rejects distant steps", func(t *testing.T) {
+		for _, p := range []int64{paso - 3, paso + 3} {
+			if _, ok := utils.VerifyTOTP(secreto, codigoDe(p), ahora); ok {
+				t.Errorf("Expected step %d to be rejected", p)
+			}
+		}
+	})
+
+	t.Run("rejects malformed codes", func(t *testing.T) {
+		for _, codigo := range []string{"", "12345", "1234567", codigoDe(paso) + " "} {
+			if _, ok := utils.VerifyTOTP(secreto, codigo, ahora); ok {
+				t.Errorf("Expected %q to be rejected", codigo)
+			}
+		}
+	})
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := utils.TOTPProvisioningURI("Copa Litoral", "admin", "JBSWY3DPEHPK3PXP")
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("Invalid URI: %v", err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Errorf("Unexpected URI: %s", uri)
+	}
+	if parsed.Path != "/Copa Litoral:admin" {
+		t.Errorf("Unexpected label: %s", parsed.Path)
+	}
+	query := parsed.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Copa Litoral" || query.Get("digits") != "6" {
+		t.Errorf("Unexpected parameters: %s", parsed.RawQuery)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	vistos := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		codigo, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			t.Fatalf("Failed to generate code: %v", err)
+		}
+		if len(codigo) != 19 || strings.Count(codigo, "-") != 3 {
+			t.Errorf("Unexpected format: %s", codigo)
+		}
+		if vistos[codigo] {
+			t.Errorf("Duplicated code: %s", codigo)
+		}
+		vistos[codigo] = true
+
+		tipeado := strings.ToUpper(strings.ReplaceAll(codigo, "-", " "))
+		if utils.NormalizeRecoveryCode(tipeado) != utils.NormalizeRecoveryCode(codigo) {
+			t.Errorf("Expected %q to match %q", tipeado, codigo)
+		}
+	}
+}
+
+func TestMFAToken(t *testing.T) {
+	keys := utils.NewHMACKeyRing("test-secret-key")
+
+	token, err := utils.GenerateMFAToken(7, "verificacion", keys, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if id, err := utils.ParseMFAToken(token, "verificacion", keys); err != nil || id != 7 {
+		t.Errorf("Expected user 7, got %d %v", id, err)
+	}
+	if _, err := utils.ParseMFAToken(token, "enrolamiento", keys); err == nil {
+		t.Error("Expected error for a different purpose")
+	}
+	if _, err := utils.ParseJWT(token, keys); err == nil {
+		t.Error("Expected a pending MFA token to be rejected as an access token")
+	}
+
+	acceso, err := utils.GenerateJWT(7, 0, "administrador", "sesion", keys, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := utils.ParseMFAToken(acceso, "verificacion", keys); err == nil {
+		t.Error("Expected an access token to be rejected as an MFA token")
+	}
+}
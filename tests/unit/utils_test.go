@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"copa-litoral-backend/utils"
 )
@@ -303,6 +305,33 @@ func TestFilterManager(t *testing.T) {
 			t.Errorf("Expected 2 arguments, got %d", len(args))
 		}
 	})
+
+	t.Run("usuarios filters", func(t *testing.T) {
+		values := url.Values{}
+		values.Set("rol[eq]", "administrador")
+		values.Set("activo[eq]", "false")
+		values.Set("jugador_id[null]", "true")
+		values.Set("password_hash[eq]", "x")
+
+		filter, err := fm.ParseAdvancedFilters(values, "usuarios")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(filter.Groups) != 1 || len(filter.Groups[0].Conditions) != 3 {
+			t.Fatalf("Expected 3 conditions, got %+v", filter.Groups)
+		}
+
+		sql, args, err := fm.BuildSQLConditions(filter)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(sql, "jugador_id IS NULL") {
+			t.Errorf("Expected a null condition, got %s", sql)
+		}
+		if len(args) != 2 {
+			t.Errorf("Expected 2 arguments, got %d", len(args))
+		}
+	})
 }
 
 func TestResponseManager(t *testing.T) {
@@ -366,16 +395,17 @@ func TestResponseManager(t *testing.T) {
 }
 
 func TestJWTUtils(t *testing.T) {
-	secret := "test-secret-key"
+	secret := utils.NewHMACKeyRing("test-secret-key")
 
 	t.Run("generate and parse JWT", func(t *testing.T) {
 		// Test JWT generation with correct parameters
 		userID := 1
+		jugadorID := 7
 		rol := "jugador"
 
 		// Note: This test assumes JWT utility functions exist
 		// If they don't exist yet, this test will fail and we'll need to implement them
-		token, err := utils.GenerateJWT(userID, rol, secret)
+		token, err := utils.GenerateJWT(userID, jugadorID, rol, "sesion-1", secret, 15*time.Minute)
 		if err != nil {
 			t.Errorf("Expected no error generating JWT, got %v", err)
 		}
@@ -392,6 +422,41 @@ func TestJWTUtils(t *testing.T) {
 		if parsedClaims.UserID != 1 {
 			t.Errorf("Expected user_id 1, got %v", parsedClaims.UserID)
 		}
+		if parsedClaims.JugadorID != 7 {
+			t.Errorf("Expected jugador_id 7, got %v", parsedClaims.JugadorID)
+		}
+		if parsedClaims.SesionID != "sesion-1" {
+			t.Errorf("Expected sid sesion-1, got %v", parsedClaims.SesionID)
+		}
+	})
+
+	t.Run("expired JWT is rejected", func(t *testing.T) {
+		token, err := utils.GenerateJWT(1, 0, "jugador", "sesion-1", secret, -time.Minute)
+		if err != nil {
+			t.Fatalf("Expected no error generating JWT, got %v", err)
+		}
+
+		if _, err := utils.ParseJWT(token, secret); err == nil {
+			t.Error("Expected error for expired token")
+		}
+	})
+
+	t.Run("refresh tokens are random and hashed", func(t *testing.T) {
+		primero, err := utils.GenerateRefreshToken()
+		if err != nil {
+			t.Fatalf("Expected no error generating refresh token, got %v", err)
+		}
+		segundo, _ := utils.GenerateRefreshToken()
+
+		if primero == segundo {
+			t.Error("Expected distinct refresh tokens")
+		}
+		if utils.HashToken(primero) != utils.HashToken(primero) {
+			t.Error("Expected hashing to be deterministic")
+		}
+		if hash := utils.HashToken(primero); len(hash) != 64 || hash == primero {
+			t.Errorf("Expected a 64 character SHA-256 hash, got %q", hash)
+		}
 	})
 
 	t.Run("parse invalid JWT", func(t *testing.T) {
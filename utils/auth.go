@@ -1,7 +1,13 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"math/big"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -10,8 +16,11 @@ import (
 
 // Claims extiende jwt.RegisteredClaims con campos adicionales
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Rol    string `json:"rol"`
+	UserID    int    `json:"user_id"`
+	JugadorID int    `json:"jugador_id,omitempty"` // Jugador vinculado al usuario; 0 si no tiene
+	Rol       string `json:"rol"`
+	SesionID  string `json:"sid"` // Familia de refresh tokens que originó el token
+	MFA       string `json:"mfa,omitempty"` // Paso de MFA pendiente; vacío en los tokens de acceso
 	jwt.RegisteredClaims
 }
 
@@ -26,39 +35,116 @@ func CheckPasswordHash(password, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
-// GenerateJWT genera un nuevo token JWT
-func GenerateJWT(userID int, rol string, secretKey string) (string, error) {
+// GenerateJWT genera un token de acceso de corta duración ligado a una sesión.
+// jugadorID es 0 si el usuario no está vinculado a un jugador.
+func GenerateJWT(userID int, jugadorID int, rol string, sesionID string, keys *KeyRing, ttl time.Duration) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Rol:    rol,
+		UserID:    userID,
+		JugadorID: jugadorID,
+		Rol:       rol,
+		SesionID:  sesionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token válido por 24 horas
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secretKey))
+	return keys.Sign(claims)
 }
 
-// ParseJWT parsea y valida un token JWT
-func ParseJWT(tokenString, secretKey string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verificar el método de firma
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("método de firma inesperado")
-		}
-		return []byte(secretKey), nil
-	})
+// ParseJWT parsea y valida un token JWT con la clave del anillo que indica su kid
+func ParseJWT(tokenString string, keys *KeyRing) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys.keyfunc)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	// Un token de MFA pendiente no da acceso a la API
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.MFA == "" {
 		return claims, nil
 	}
 
 	return nil, errors.New("token inválido")
-} 
\ No newline at end of file
+}
+
+// GenerateMFAToken genera el token de corta duración que entrega el login cuando
+// falta el segundo factor; proposito indica qué paso de MFA habilita
+func GenerateMFAToken(userID int, proposito string, keys *KeyRing, ttl time.Duration) (string, error) {
+	claims := Claims{
+		UserID: userID,
+		MFA:    proposito,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return keys.Sign(claims)
+}
+
+// ParseMFAToken valida un token de MFA pendiente emitido para el propósito indicado
+// y devuelve el usuario
+func ParseMFAToken(tokenString, proposito string, keys *KeyRing) (int, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keys.keyfunc)
+	if err != nil {
+		return 0, err
+	}
+
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && proposito != "" && claims.MFA == proposito {
+		return claims.UserID, nil
+	}
+
+	return 0, errors.New("token inválido")
+}
+
+// GenerateRefreshToken genera un refresh token opaco y aleatorio
+func GenerateRefreshToken() (string, error) {
+	return randomToken(32, base64.RawURLEncoding.EncodeToString)
+}
+
+// GenerateSessionID genera el identificador de una nueva familia de refresh tokens
+func GenerateSessionID() (string, error) {
+	return randomToken(16, hex.EncodeToString)
+}
+
+// APIKeyPrefijo antecede a todas las API keys para reconocerlas en logs y repositorios
+const APIKeyPrefijo = "cl_"
+
+// GenerateAPIKey genera una API key de 256 bits
+func GenerateAPIKey() (string, error) {
+	clave, err := randomToken(32, hex.EncodeToString)
+	if err != nil {
+		return "", err
+	}
+	return APIKeyPrefijo + clave, nil
+}
+
+// HashToken devuelve el SHA-256 de un token; es lo único que se guarda en la base de datos
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateOneTimeCode genera un código numérico de un solo uso con la cantidad de dígitos indicada
+func GenerateOneTimeCode(digits int) (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < digits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+func randomToken(size int, encode func([]byte) string) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
@@ -125,7 +125,8 @@ func (fm *FilterManager) setupDefaultConfigs() {
 			"nombre_usuario": {Type: "string", AllowedOperators: []FilterOperator{OpEqual, OpLike, OpILike}},
 			"email":          {Type: "string", AllowedOperators: []FilterOperator{OpEqual, OpLike, OpILike}},
 			"rol":            {Type: "string", AllowedOperators: []FilterOperator{OpEqual, OpIn, OpNotIn}},
-			"jugador_id":     {Type: "int", AllowedOperators: []FilterOperator{OpEqual, OpIn, OpNotIn, OpIsNull}},
+			"jugador_id":     {Type: "int", AllowedOperators: []FilterOperator{OpEqual, OpIn, OpNotIn, OpIsNull, OpIsNotNull}},
+			"activo":         {Type: "bool", AllowedOperators: []FilterOperator{OpEqual}},
 			"created_at":     {Type: "datetime", AllowedOperators: []FilterOperator{OpEqual, OpGreaterThan, OpLessThan, OpBetween}},
 		},
 		MaxConditions: 8,
@@ -227,6 +228,11 @@ func (fm *FilterManager) createCondition(field string, operator FilterOperator,
 		Operator: operator,
 	}
 
+	// Los operadores de nulidad no usan el valor (ej: jugador_id[null]=true)
+	if operator == OpIsNull || operator == OpIsNotNull {
+		return condition, nil
+	}
+
 	// Validar y convertir valor según el tipo de campo
 	convertedValue, err := fm.convertValue(value, fieldConfig.Type)
 	if err != nil {
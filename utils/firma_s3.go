@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HashVacio es el SHA-256 de un cuerpo vacío, en hexadecimal
+const HashVacio = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// FirmarS3 firma una solicitud a un almacenamiento compatible con S3 con AWS
+// Signature Version 4. Firma todos los encabezados presentes más host,
+// x-amz-date y x-amz-content-sha256, que agrega; hashCuerpo es el SHA-256 del
+// cuerpo en hexadecimal.
+func FirmarS3(req *http.Request, accessKey, secretKey, region, hashCuerpo string, ahora time.Time) {
+	ahora = ahora.UTC()
+	fecha := ahora.Format("20060102")
+	marca := ahora.Format("20060102T150405Z")
+
+	req.Header.Set("X-Amz-Date", marca)
+	req.Header.Set("X-Amz-Content-Sha256", hashCuerpo)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	encabezados := map[string]string{"host": host}
+	for nombre, valores := range req.Header {
+		encabezados[strings.ToLower(nombre)] = strings.TrimSpace(strings.Join(valores, ","))
+	}
+	nombres := make([]string, 0, len(encabezados))
+	for nombre := range encabezados {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+
+	var canonicos strings.Builder
+	for _, nombre := range nombres {
+		canonicos.WriteString(nombre + ":" + encabezados[nombre] + "\n")
+	}
+	firmados := strings.Join(nombres, ";")
+
+	solicitud := strings.Join([]string{
+		req.Method,
+		codificarRutaS3(req.URL.Path),
+		consultaCanonicaS3(req),
+		canonicos.String(),
+		firmados,
+		hashCuerpo,
+	}, "\n")
+
+	alcance := fecha + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(solicitud))
+	aFirmar := "AWS4-HMAC-SHA256\n" + marca + "\n" + alcance + "\n" + hex.EncodeToString(hash[:])
+
+	clave := hmacSHA256([]byte("AWS4"+secretKey), fecha)
+	clave = hmacSHA256(clave, region)
+	clave = hmacSHA256(clave, "s3")
+	clave = hmacSHA256(clave, "aws4_request")
+	firma := hex.EncodeToString(hmacSHA256(clave, aFirmar))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+alcance+
+		", SignedHeaders="+firmados+", Signature="+firma)
+}
+
+func hmacSHA256(clave []byte, dato string) []byte {
+	mac := hmac.New(sha256.New, clave)
+	mac.Write([]byte(dato))
+	return mac.Sum(nil)
+}
+
+// codificarRutaS3 codifica la ruta como la espera S3: todo salvo los caracteres no
+// reservados y las barras
+func codificarRutaS3(ruta string) string {
+	if ruta == "" {
+		return "/"
+	}
+	const noReservados = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_.~/"
+	const hexa = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(ruta); i++ {
+		c := ruta[i]
+		if strings.IndexByte(noReservados, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexa[c>>4])
+		b.WriteByte(hexa[c&15])
+	}
+	return b.String()
+}
+
+// consultaCanonicaS3 ordena los parámetros de la consulta por nombre
+func consultaCanonicaS3(req *http.Request) string {
+	consulta := req.URL.Query()
+	if len(consulta) == 0 {
+		return ""
+	}
+	// Encode ordena por nombre; S3 espera %20 en lugar de +
+	return strings.ReplaceAll(consulta.Encode(), "+", "%20")
+}
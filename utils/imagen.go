@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	// ErrImagenFormato indica que el archivo no es una imagen JPEG o PNG válida
+	ErrImagenFormato = errors.New("la imagen debe ser JPEG o PNG")
+	// ErrImagenDimensiones indica que la imagen supera las dimensiones admitidas
+	ErrImagenDimensiones = errors.New("la imagen supera las dimensiones admitidas")
+)
+
+// Límites de una imagen subida; evitan que un archivo chico se descomprima en
+// gigabytes de memoria
+const (
+	LadoMaximoImagen    = 12000
+	PixelesMaximoImagen = 50_000_000
+)
+
+// Calidad JPEG del original y de las miniaturas
+const (
+	calidadOriginal  = 90
+	calidadMiniatura = 82
+)
+
+// Miniatura es un tamaño que se genera de cada foto: el lado mayor no supera Lado
+type Miniatura struct {
+	Nombre string
+	Lado   int
+}
+
+// Miniaturas son los tamaños que se generan, del más chico al más grande
+var Miniaturas = []Miniatura{
+	{Nombre: "chica", Lado: 320},
+	{Nombre: "mediana", Lado: 800},
+	{Nombre: "grande", Lado: 1600},
+}
+
+// VarianteImagen es una versión codificada de la imagen
+type VarianteImagen struct {
+	Nombre    string
+	Ancho     int
+	Alto      int
+	Contenido []byte
+}
+
+// ImagenProcesada es una imagen lista para guardar: el original vuelto a codificar,
+// sin metadatos, y las miniaturas más chicas que el original
+type ImagenProcesada struct {
+	TipoMIME  string
+	Extension string
+	Original  VarianteImagen
+	Variantes []VarianteImagen
+}
+
+// ProcesarImagen valida una imagen JPEG o PNG por su contenido, la endereza según
+// la orientación EXIF y la vuelve a codificar, lo que descarta los metadatos
+// (EXIF, GPS, comentarios). Genera las miniaturas cuyo tamaño es menor que el del
+// original; no se agranda la imagen.
+func ProcesarImagen(datos []byte) (*ImagenProcesada, error) {
+	procesada := &ImagenProcesada{TipoMIME: http.DetectContentType(datos)}
+	switch procesada.TipoMIME {
+	case "image/jpeg":
+		procesada.Extension = "jpg"
+	case "image/png":
+		procesada.Extension = "png"
+	default:
+		return nil, ErrImagenFormato
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(datos))
+	if err != nil {
+		return nil, ErrImagenFormato
+	}
+	if config.Width > LadoMaximoImagen || config.Height > LadoMaximoImagen ||
+		config.Width*config.Height > PixelesMaximoImagen {
+		return nil, ErrImagenDimensiones
+	}
+
+	var img image.Image
+	if procesada.TipoMIME == "image/jpeg" {
+		img, err = jpeg.Decode(bytes.NewReader(datos))
+	} else {
+		img, err = png.Decode(bytes.NewReader(datos))
+	}
+	if err != nil {
+		return nil, ErrImagenFormato
+	}
+
+	rgba := aRGBA(img)
+	if procesada.TipoMIME == "image/jpeg" {
+		rgba = orientar(rgba, orientacionEXIF(datos))
+	}
+
+	codificar := func(nombre string, img *image.RGBA, calidad int) (VarianteImagen, error) {
+		var buf bytes.Buffer
+		var err error
+		if procesada.TipoMIME == "image/jpeg" {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: calidad})
+		} else {
+			err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+		}
+		b := img.Bounds()
+		return VarianteImagen{Nombre: nombre, Ancho: b.Dx(), Alto: b.Dy(), Contenido: buf.Bytes()}, err
+	}
+
+	if procesada.Original, err = codificar("original", rgba, calidadOriginal); err != nil {
+		return nil, err
+	}
+	ancho, alto := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+	for _, m := range Miniaturas {
+		if ancho <= m.Lado && alto <= m.Lado {
+			continue
+		}
+		variante, err := codificar(m.Nombre, reducir(rgba, m.Lado), calidadMiniatura)
+		if err != nil {
+			return nil, err
+		}
+		procesada.Variantes = append(procesada.Variantes, variante)
+	}
+
+	return procesada, nil
+}
+
+// aRGBA copia la imagen a un *image.RGBA con origen en (0, 0)
+func aRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// reducir achica la imagen para que su lado mayor mida lado, promediando los
+// píxeles de origen que cubre cada píxel de destino
+func reducir(src *image.RGBA, lado int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := lado, lado
+	if sw >= sh {
+		dh = max(1, sh*lado/sw)
+	} else {
+		dw = max(1, sw*lado/sh)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				fila := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := fila[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// orientar aplica la orientación EXIF (1 a 8) para que la imagen se vea derecha
+// sin sus metadatos
+func orientar(src *image.RGBA, orientacion int) *image.RGBA {
+	if orientacion < 2 || orientacion > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientacion >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientacion {
+			case 2: // espejo horizontal
+				sx, sy = w-1-x, y
+			case 3: // 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // espejo vertical
+				sx, sy = x, h-1-y
+			case 5: // transpuesta
+				sx, sy = y, x
+			case 6: // 90° horario
+				sx, sy = y, h-1-x
+			case 7: // transversa
+				sx, sy = w-1-y, h-1-x
+			case 8: // 90° antihorario
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:])
+		}
+	}
+	return dst
+}
+
+// orientacionEXIF busca la etiqueta de orientación (0x0112) en el segmento EXIF de
+// un JPEG; devuelve 1 (normal) si no la encuentra
+func orientacionEXIF(datos []byte) int {
+	if len(datos) < 4 || datos[0] != 0xFF || datos[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(datos); {
+		if datos[i] != 0xFF {
+			return 1
+		}
+		marcador := datos[i+1]
+		largo := int(binary.BigEndian.Uint16(datos[i+2:]))
+		// Comienzo de los datos de la imagen: ya no hay metadatos
+		if marcador == 0xDA || largo < 2 || i+2+largo > len(datos) {
+			return 1
+		}
+		segmento := datos[i+4 : i+2+largo]
+		if marcador == 0xE1 && bytes.HasPrefix(segmento, []byte("Exif\x00\x00")) {
+			return orientacionTIFF(segmento[6:])
+		}
+		i += 2 + largo
+	}
+	return 1
+}
+
+func orientacionTIFF(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var orden binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		orden = binary.LittleEndian
+	case "MM":
+		orden = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(orden.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entradas := int(orden.Uint16(tiff[ifd:]))
+	for e := 0; e < entradas; e++ {
+		inicio := ifd + 2 + e*12
+		if inicio+12 > len(tiff) {
+			return 1
+		}
+		if orden.Uint16(tiff[inicio:]) == 0x0112 {
+			return int(orden.Uint16(tiff[inicio+8:]))
+		}
+	}
+	return 1
+}
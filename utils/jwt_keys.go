@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"copa-litoral-backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey es una clave del anillo. Las claves retiradas solo tienen la parte pública:
+// verifican los tokens que todavía no vencieron pero no firman.
+type JWTKey struct {
+	KID       string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeyRing agrupa las claves con las que se verifican los tokens y la clave activa
+// con la que se firman los nuevos
+type KeyRing struct {
+	keys   map[string]*JWTKey
+	activa *JWTKey
+}
+
+// JSONWebKey es la representación pública de una clave según RFC 7517
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`   // RSA: módulo
+	E   string `json:"e,omitempty"`   // RSA: exponente
+	Crv string `json:"crv,omitempty"` // OKP: curva
+	X   string `json:"x,omitempty"`   // OKP: clave pública
+}
+
+// JSONWebKeySet es el documento publicado en /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewKeyRing arma el anillo según la configuración: con JWT_KEYS_DIR carga las
+// claves asimétricas del directorio; sin él firma con HS256 y JWT_SECRET
+func NewKeyRing(cfg *config.Config) (*KeyRing, error) {
+	if cfg.JWTKeysDir == "" {
+		return NewHMACKeyRing(cfg.JWTSecret), nil
+	}
+	return LoadKeyRing(cfg.JWTKeysDir, cfg.JWTActiveKID)
+}
+
+// NewHMACKeyRing crea un anillo con una única clave simétrica. No publica nada
+// en el JWKS: quien verifica necesita el secreto.
+func NewHMACKeyRing(secret string) *KeyRing {
+	key := &JWTKey{
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+	return &KeyRing{
+		keys:   map[string]*JWTKey{"": key},
+		activa: key,
+	}
+}
+
+// LoadKeyRing carga los archivos .pem del directorio; el nombre del archivo sin
+// extensión es el kid. Se aceptan claves privadas RSA (RS256) y Ed25519 (EdDSA)
+// en PKCS#8 o PKCS#1, y claves públicas PKIX para las claves retiradas. Firma la
+// clave activeKID o, si está vacío, la última clave privada en orden alfabético.
+func LoadKeyRing(dir, activeKID string) (*KeyRing, error) {
+	archivos, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(archivos)
+
+	ring := &KeyRing{keys: make(map[string]*JWTKey)}
+	for _, archivo := range archivos {
+		kid := strings.TrimSuffix(filepath.Base(archivo), ".pem")
+		key, err := loadJWTKey(archivo, kid)
+		if err != nil {
+			return nil, fmt.Errorf("clave %s: %w", kid, err)
+		}
+		ring.keys[kid] = key
+		if activeKID == "" && key.signKey != nil {
+			ring.activa = key
+		}
+	}
+
+	if activeKID != "" {
+		ring.activa = ring.keys[activeKID]
+		if ring.activa == nil {
+			return nil, fmt.Errorf("la clave activa %s no está en %s", activeKID, dir)
+		}
+	}
+	if ring.activa == nil || ring.activa.signKey == nil {
+		return nil, fmt.Errorf("no hay una clave privada para firmar en %s", dir)
+	}
+
+	return ring, nil
+}
+
+func loadJWTKey(archivo, kid string) (*JWTKey, error) {
+	data, err := os.ReadFile(archivo)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no contiene un bloque PEM")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("tipo de bloque PEM no soportado: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &JWTKey{KID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("tipo de clave no soportado: %T", parsed)
+	}
+
+	return key, nil
+}
+
+// ActiveKID devuelve el kid de la clave con la que se firman los tokens nuevos
+func (k *KeyRing) ActiveKID() string {
+	return k.activa.KID
+}
+
+// Sign firma los claims con la clave activa e informa su kid en el header
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.activa.Method, claims)
+	if k.activa.KID != "" {
+		token.Header["kid"] = k.activa.KID
+	}
+	return token.SignedString(k.activa.signKey)
+}
+
+// keyfunc elige la clave por el kid del token y exige el algoritmo de esa clave,
+// para que un token no pueda declarar otro método de firma
+func (k *KeyRing) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, errors.New("clave de firma desconocida")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("método de firma inesperado")
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS devuelve las claves públicas del anillo, ordenadas por kid
+func (k *KeyRing) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range k.keys {
+		jwk := JSONWebKey{Use: "sig", Alg: key.Method.Alg(), Kid: key.KID}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			// Las claves simétricas nunca se publican
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// JWKSHandler publica las claves públicas para que otros servicios verifiquen los tokens
+func JWKSHandler(keys *KeyRing) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Los clientes pueden cachear el documento; una clave nueva se publica antes de activarla
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(keys.JWKS())
+	}
+}
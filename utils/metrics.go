@@ -77,6 +77,14 @@ var (
 		[]string{"result"}, // success, failure
 	)
 
+	authLockoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_lockouts_total",
+			Help: "Total number of temporary login lockouts",
+		},
+		[]string{"scope"}, // usuario, ip
+	)
+
 	// Rate limiting metrics
 	rateLimitHitsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -108,6 +116,7 @@ func InitMetrics() {
 		dbQueriesTotal,
 		dbQueryDuration,
 		authAttemptsTotal,
+		authLockoutsTotal,
 		rateLimitHitsTotal,
 		appInfo,
 	)
@@ -173,6 +182,11 @@ func RecordAuthAttempt(success bool) {
 	authAttemptsTotal.WithLabelValues(result).Inc()
 }
 
+// RecordAuthLockout registra un bloqueo temporal de login por usuario o por IP
+func RecordAuthLockout(scope string) {
+	authLockoutsTotal.WithLabelValues(scope).Inc()
+}
+
 // RecordRateLimitHit registra un hit de rate limiting
 func RecordRateLimitHit(endpoint, ip string) {
 	rateLimitHitsTotal.WithLabelValues(endpoint, ip).Inc()
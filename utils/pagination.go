@@ -162,6 +162,8 @@ func sanitizeSortField(sort string) string {
 		"anio":                    true,
 		"fecha_inicio":            true,
 		"fecha_fin":               true,
+		"titulo":                  true,
+		"fecha_publicacion":       true,
 	}
 
 	// Limpiar el campo
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// LargoMaximoSlug es el largo máximo de un slug generado, sin contar el sufijo
+// que lo hace único
+const LargoMaximoSlug = 200
+
+// sinAcentos reemplaza las letras acentuadas del castellano (y las más comunes de
+// otros idiomas) por su versión sin acento
+var sinAcentos = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+	"â", "a", "ê", "e", "î", "i", "ô", "o", "û", "u",
+	"ä", "a", "ë", "e", "ï", "i", "ö", "o", "ã", "a", "õ", "o", "ç", "c",
+)
+
+// Slugify convierte un texto en un slug para URLs: minúsculas, sin acentos y con
+// guiones entre palabras ("Campeón 2024: ¡Final!" → "campeon-2024-final"). Acepta
+// texto con entidades HTML, como el que deja SanitizeString.
+func Slugify(texto string) string {
+	texto = sinAcentos.Replace(strings.ToLower(html.UnescapeString(texto)))
+
+	var b strings.Builder
+	guion := false
+	for _, r := range texto {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if guion && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			guion = false
+			continue
+		}
+		// El apóstrofo no separa palabras: "l'equipe" → "lequipe"
+		if r != '\'' && r != '’' {
+			guion = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > LargoMaximoSlug {
+		slug = strings.TrimRight(slug[:LargoMaximoSlug], "-")
+	}
+	return slug
+}
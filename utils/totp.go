@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parámetros de TOTP (RFC 6238) compatibles con las aplicaciones autenticadoras
+const (
+	TOTPDigitos = 6
+	TOTPPeriodo = 30 // seconds
+	// totpTolerancia es la cantidad de pasos aceptados antes y después del actual
+	// para absorber la diferencia de reloj con el teléfono
+	totpTolerancia = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret genera un secreto de 160 bits codificado en base32
+func GenerateTOTPSecret() (string, error) {
+	return randomToken(20, totpEncoding.EncodeToString)
+}
+
+// TOTPProvisioningURI arma la URI otpauth:// que las aplicaciones leen desde un QR
+func TOTPProvisioningURI(emisor, cuenta, secreto string) string {
+	params := url.Values{}
+	params.Set("secret", secreto)
+	params.Set("issuer", emisor)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(TOTPDigitos))
+	params.Set("period", fmt.Sprint(TOTPPeriodo))
+
+	label := url.PathEscape(emisor) + ":" + url.PathEscape(cuenta)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPStep devuelve el paso de tiempo al que pertenece t
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / TOTPPeriodo
+}
+
+// TOTPCode calcula el código del secreto para un paso de tiempo
+func TOTPCode(secreto string, paso int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secreto, "=")))
+	if err != nil {
+		return "", fmt.Errorf("secreto TOTP inválido: %w", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(paso))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	valor := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < TOTPDigitos; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigitos, valor%modulo), nil
+}
+
+// VerifyTOTP busca el código entre los pasos cercanos a t y devuelve el paso que
+// coincidió, para que quien llama rechace reutilizarlo
+func VerifyTOTP(secreto, codigo string, t time.Time) (int64, bool) {
+	if len(codigo) != TOTPDigitos {
+		return 0, false
+	}
+
+	actual := TOTPStep(t)
+	for paso := actual - totpTolerancia; paso <= actual+totpTolerancia; paso++ {
+		esperado, err := TOTPCode(secreto, paso)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(esperado), []byte(codigo)) == 1 {
+			return paso, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCode genera un código de recuperación de 80 bits con el formato
+// xxxx-xxxx-xxxx-xxxx
+func GenerateRecoveryCode() (string, error) {
+	codigo, err := randomToken(10, totpEncoding.EncodeToString)
+	if err != nil {
+		return "", err
+	}
+	codigo = strings.ToLower(codigo)
+	return codigo[0:4] + "-" + codigo[4:8] + "-" + codigo[8:12] + "-" + codigo[12:16], nil
+}
+
+// NormalizeRecoveryCode quita guiones y espacios para comparar lo que tipea el usuario
+func NormalizeRecoveryCode(codigo string) string {
+	codigo = strings.ToLower(codigo)
+	return strings.NewReplacer("-", "", " ", "").Replace(codigo)
+}